@@ -13,8 +13,12 @@ parser.add_argument('-m', '--model-path', type=str, default='sam_checkpoint.pt')
 args = parser.parse_args()
 print(args)
 
-# simulate running a model benchmark
-time.sleep(20)
+# simulate running a model benchmark, reporting progress to mlsolid
+# with "MLSOLID_PROGRESS <done>/<total>" lines on stdout
+steps = 20
+for step in range(1, steps + 1):
+    time.sleep(1)
+    print(f'MLSOLID_PROGRESS {step}/{steps}', flush=True)
 
 print('Dataset path:', os.listdir(args.dataset_path))
 if os.path.exists(args.model_path):
@@ -0,0 +1,115 @@
+// Package main collects the artifact garbage of an mlsolid deployment: the
+// object store objects nothing references, and references to missing
+// objects.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/urfave/cli/v3"
+	"github.com/zeddo123/mlsolid/solid"
+	"github.com/zeddo123/mlsolid/solid/controllers"
+	"github.com/zeddo123/mlsolid/solid/logger"
+	"github.com/zeddo123/mlsolid/solid/s3"
+	"github.com/zeddo123/mlsolid/solid/store"
+)
+
+func main() {
+	var configPath string
+
+	var grace time.Duration
+
+	var dryRun bool
+
+	cmd := cli.Command{ //nolint: exhaustruct
+		Name:  "gc",
+		Usage: "reports orphaned artifact objects and dangling references, and deletes orphans older than the grace period",
+		Flags: []cli.Flag{
+			&cli.StringFlag{ //nolint: exhaustruct
+				Name:        "config",
+				Usage:       "directory holding mlsolid.yaml",
+				Value:       ".",
+				Destination: &configPath,
+			},
+			&cli.DurationFlag{ //nolint: exhaustruct
+				Name:        "grace",
+				Usage:       "how old orphans must be to be deleted, artifact_gc_grace when unset",
+				Destination: &grace,
+			},
+			&cli.BoolFlag{ //nolint: exhaustruct
+				Name:        "dry-run",
+				Usage:       "report orphans without deleting them",
+				Destination: &dryRun,
+			},
+		},
+		Action: func(ctx context.Context, _ *cli.Command) error {
+			config, err := solid.LoadConfig(configPath)
+			if err != nil {
+				return err //nolint: wrapcheck
+			}
+
+			if grace == 0 {
+				grace = config.ArtifactGCGrace
+			}
+
+			controller, err := newController(ctx, config)
+			if err != nil {
+				return err
+			}
+
+			report, err := controller.CollectArtifactGarbage(ctx, grace, dryRun)
+			if err != nil {
+				return err //nolint: wrapcheck
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+
+			return enc.Encode(report) //nolint: wrapcheck
+		},
+	}
+
+	if err := cmd.Run(context.Background(), os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newController(ctx context.Context, config solid.Config) (*controllers.Controller, error) {
+	redisClient := redis.NewClient(&redis.Options{ //nolint: exhaustruct
+		Addr:     config.RedisAddr,
+		Password: config.RedisPassword,
+		DB:       config.RedisDB,
+	})
+
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	objectStore, err := s3.NewStore(s3.StoreOps{ //nolint: exhaustruct
+		Bucket:          config.S3Bucket,
+		Endpoint:        config.S3Endpoint,
+		AccessKey:       config.S3Key,
+		SecretAccessKey: config.S3Secret,
+		Region:          config.S3Region,
+		Prefix:          config.S3Prefix,
+	})
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	base := logger.New(!config.Prod)
+
+	return &controllers.Controller{ //nolint: exhaustruct
+		Redis: store.RedisStore{
+			Client: *redisClient,
+			Logger: logger.NewSub(base, "store"),
+		},
+		S3:     objectStore,
+		Logger: logger.NewSub(base, "gc"),
+	}, nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/zeddo123/mlsolid/solid/oauth"
 	"github.com/zeddo123/mlsolid/solid/s3"
 	"github.com/zeddo123/mlsolid/solid/store"
+	"github.com/zeddo123/mlsolid/solid/types"
 	"github.com/zeddo123/pubgo"
 )
 
@@ -76,16 +77,59 @@ func main() {
 		Logger: logger.NewSub(log, "store"),
 	}
 
+	compression, err := types.ParseCompression(config.ArtifactCompression)
+	if err != nil {
+		panic(err)
+	}
+
 	controller := controllers.Controller{
 		Redis:              store,
 		S3:                 objectStore,
 		Bus:                bus,
 		Logger:             logger.NewSub(log, "controller"),
 		PublishBenchEvents: config.EnableBEngine,
+		ArtifactUploadTTL:  config.ArtifactUploadTTL,
+		PresignedURLs:      config.ArtifactPresignedURLs,
+		PresignTTL:         config.ArtifactPresignTTL,
+		Compression:        compression,
+		StorageQuota: types.StorageQuota{
+			MaxArtifactSize: config.ArtifactMaxSize,
+			Run:             config.RunStorageQuota,
+			Experiment:      config.ExpStorageQuota,
+		},
+	}
+
+	// index the runs created before run search existed, so that they can
+	// be searched too; done before serving so that no write races it
+	if err := store.BackfillRunsIndex(context.Background()); err != nil {
+		log.Error().Err(err).Msg("could not backfill runs index")
+	}
+
+	// summarize the metrics of runs logged before metric summaries were
+	// maintained; values logged during the backfill would be counted twice
+	if err := store.BackfillMetricSummaries(context.Background()); err != nil {
+		log.Error().Err(err).Msg("could not backfill metric summaries")
+	}
+
+	// record the artifacts saved before content was deduplicated as the
+	// blobs of their content, so that later uploads of it share them
+	if err := store.BackfillBlobs(context.Background()); err != nil {
+		log.Error().Err(err).Msg("could not backfill blobs")
 	}
 
 	log.Info().Msg("starting servers")
 
+	if config.RunHeartbeatTimeout > 0 {
+		go controller.StartRunReaper(context.Background(), config.RunHeartbeatTimeout)
+	}
+
+	go controller.StartArtifactDeleter(context.Background())
+	go controller.StartUploadReaper(context.Background())
+
+	if config.ArtifactGCInterval > 0 {
+		go controller.StartArtifactGC(context.Background(), config.ArtifactGCInterval, config.ArtifactGCGrace)
+	}
+
 	if config.EnableBEngine {
 		sub := bus.Subscribe("bengine", pubgo.WithBufferSize(BengineBufferSize))
 
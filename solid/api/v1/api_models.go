@@ -13,9 +13,71 @@ type ErrorResponse struct {
 
 // ExperimentResponse struct returned by Experiment endpoint.
 type ExperimentResponse struct {
-	Details string    `json:"details"`
-	Runs    []runInfo `json:"runs"`
-	Metrics []string  `json:"metrics"`
+	Details string         `json:"details"`
+	Info    experimentInfo `json:"info"`
+	Runs    []runInfo      `json:"runs"`
+	Metrics []string       `json:"metrics"`
+}
+
+type experimentInfo struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Owner       string            `json:"owner"`
+	Tags        map[string]string `json:"tags"`
+	CreatedAt   *time.Time        `json:"createdAt,omitempty,format:datetime"`
+}
+
+func newExperimentInfo(i types.ExperimentInfo) experimentInfo {
+	info := experimentInfo{
+		Name:        i.Name,
+		Description: i.Description,
+		Owner:       i.Owner,
+		Tags:        i.Tags,
+		CreatedAt:   nil,
+	}
+
+	if info.Tags == nil {
+		info.Tags = map[string]string{}
+	}
+
+	if !i.CreatedAt.IsZero() {
+		info.CreatedAt = &i.CreatedAt
+	}
+
+	return info
+}
+
+// CreateExperimentRequest represents a request to create an experiment
+// ahead of its first run.
+type CreateExperimentRequest struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Owner       string            `json:"owner"`
+	Tags        map[string]string `json:"tags"`
+}
+
+// CreateExperimentResponse response to experiment creation request.
+type CreateExperimentResponse struct {
+	Details string `json:"details"`
+	ID      string `json:"id"`
+}
+
+// UpdateExperimentRequest represents a request to update an experiment's
+// info. Absent fields are left unchanged, Tags are set (or overwritten) and
+// RemoveTags deleted.
+type UpdateExperimentRequest struct {
+	Name        *string           `json:"name"`
+	Description *string           `json:"description"`
+	Owner       *string           `json:"owner"`
+	Tags        map[string]string `json:"tags"`
+	RemoveTags  []string          `json:"removeTags"`
+}
+
+// UpdateExperimentResponse response to experiment update request.
+type UpdateExperimentResponse struct {
+	Details string         `json:"details"`
+	Info    experimentInfo `json:"info"`
 }
 
 // ExperimentsResponse struct returned by Experiments endpoint.
@@ -26,9 +88,170 @@ type ExperimentsResponse struct {
 }
 
 type runInfo struct {
-	RunID     string    `json:"runId"`
-	CreatedAt time.Time `json:"createdAt,format:datetime"`
-	Color     string    `json:"color"`
+	RunID     string                   `json:"runId"`
+	CreatedAt time.Time                `json:"createdAt,format:datetime"`
+	Color     string                   `json:"color"`
+	Status    types.RunStatus          `json:"status"`
+	EndedAt   *time.Time               `json:"endedAt,omitempty,format:datetime"`
+	Params    map[string]types.Param   `json:"params"`
+	Tags      map[string]string        `json:"tags"`
+	Note      string                   `json:"note"`
+	ParentID  string                   `json:"parentId,omitempty"`
+	Children  []string                 `json:"children"`
+	Archived  bool                     `json:"archived"`
+	Summaries map[string]metricSummary `json:"summaries"`
+}
+
+// metricSummary summary statistics of a numeric metric of a run.
+type metricSummary struct {
+	Count    int64   `json:"count"`
+	Min      float64 `json:"min"`
+	MinStep  *int64  `json:"minStep,omitempty"`
+	Max      float64 `json:"max"`
+	MaxStep  *int64  `json:"maxStep,omitempty"`
+	Last     float64 `json:"last"`
+	LastStep *int64  `json:"lastStep,omitempty"`
+	Mean     float64 `json:"mean"`
+}
+
+func newMetricSummaries(summaries map[string]types.MetricSummary) map[string]metricSummary {
+	res := make(map[string]metricSummary, len(summaries))
+
+	for name, s := range summaries {
+		res[name] = metricSummary{
+			Count:    s.Count,
+			Min:      s.Min,
+			MinStep:  s.MinStep,
+			Max:      s.Max,
+			MaxStep:  s.MaxStep,
+			Last:     s.Last,
+			LastStep: s.LastStep,
+			Mean:     s.Mean(),
+		}
+	}
+
+	return res
+}
+
+func newRunInfo(r *types.Run) runInfo {
+	info := runInfo{
+		RunID:     r.Name,
+		CreatedAt: r.Timestamp,
+		Color:     r.Color,
+		Status:    r.Status,
+		EndedAt:   nil,
+		Params:    r.Params,
+		Tags:      r.Tags,
+		Note:      r.Note,
+		ParentID:  r.ParentID,
+		Children:  r.Children,
+		Archived:  r.Archived,
+		Summaries: newMetricSummaries(r.Summaries),
+	}
+
+	if !r.EndTime.IsZero() {
+		info.EndedAt = &r.EndTime
+	}
+
+	return info
+}
+
+// SearchRunsResponse struct returned by the run search endpoint.
+type SearchRunsResponse struct {
+	Details string    `json:"details"`
+	Runs    []runInfo `json:"runs"`
+	Cursor  string    `json:"cursor"`
+}
+
+// ChildRunsResponse struct returned by the child runs endpoint.
+type ChildRunsResponse struct {
+	Details    string                           `json:"details"`
+	Runs       []runInfo                        `json:"runs"`
+	Aggregates map[string]types.MetricAggregate `json:"aggregates"`
+}
+
+// CompareRunsRequest represents a request to compare runs side by side.
+// Metrics are the metrics to compare the curves of, all numeric metrics when
+// empty; the step window and downsampling apply to each run's curves.
+type CompareRunsRequest struct {
+	RunIDs     []string `json:"runIds"`
+	Metrics    []string `json:"metrics"`
+	StartStep  *int64   `json:"startStep"`
+	EndStep    *int64   `json:"endStep"`
+	MaxPoints  int      `json:"maxPoints"`
+	Downsample string   `json:"downsample"`
+}
+
+// CompareRunsResponse struct returned by the run comparison endpoint.
+type CompareRunsResponse struct {
+	Details     string                              `json:"details"`
+	RunIDs      []string                            `json:"runIds"`
+	Experiments map[string]string                   `json:"experiments"`
+	Params      map[string]paramComparison          `json:"params"`
+	Summaries   map[string]map[string]metricSummary `json:"summaries"`
+	Curves      map[string]alignedCurve             `json:"curves"`
+}
+
+type paramComparison struct {
+	Values  map[string]types.Param `json:"values"`
+	Differs bool                   `json:"differs"`
+}
+
+type alignedCurve struct {
+	Steps  []int64               `json:"steps"`
+	Values map[string][]*float64 `json:"values"`
+}
+
+func newCompareRunsResponse(c *types.RunComparison) CompareRunsResponse {
+	res := CompareRunsResponse{
+		Details:     "successfully compared runs",
+		RunIDs:      c.RunIDs,
+		Experiments: c.Experiments,
+		Params:      make(map[string]paramComparison, len(c.Params)),
+		Summaries:   make(map[string]map[string]metricSummary, len(c.Summaries)),
+		Curves:      make(map[string]alignedCurve, len(c.Curves)),
+	}
+
+	for name, p := range c.Params {
+		res.Params[name] = paramComparison(p)
+	}
+
+	for id, s := range c.Summaries {
+		res.Summaries[id] = newMetricSummaries(s)
+	}
+
+	for name, curve := range c.Curves {
+		res.Curves[name] = alignedCurve(curve)
+	}
+
+	return res
+}
+
+// UpdateRunRequest represents a request to update a run's tags and note.
+// Tags are set (or overwritten), RemoveTags deleted, and Note replaces the
+// run's note when present.
+type UpdateRunRequest struct {
+	Tags       map[string]string `json:"tags"`
+	RemoveTags []string          `json:"removeTags"`
+	Note       *string           `json:"note"`
+}
+
+// UpdateRunResponse response to run update request.
+type UpdateRunResponse struct {
+	Details string            `json:"details"`
+	Tags    map[string]string `json:"tags"`
+	Note    string            `json:"note"`
+}
+
+// DeleteRunsResponse response to run and experiment deletion requests.
+type DeleteRunsResponse struct {
+	Details     string   `json:"details"`
+	DeletedRuns []string `json:"deletedRuns"`
+}
+
+// ArchiveResponse response to run and experiment archive/restore requests.
+type ArchiveResponse struct {
+	Details string `json:"details"`
 }
 
 // RegistryResponse struct returned by registry endpoint.
@@ -44,10 +267,11 @@ type RegistryResponse struct {
 }
 
 type entryInfo struct {
-	CreatedAt time.Time `json:"createdAt,format:datetime"`
-	Tags      []string  `json:"tags"`
-	Name      string    `json:"name"`
-	Run       string    `json:"run"`
+	CreatedAt time.Time            `json:"createdAt,format:datetime"`
+	Tags      []string             `json:"tags"`
+	Name      string               `json:"name"`
+	Run       string               `json:"run"`
+	Files     []types.ArtifactFile `json:"files,omitempty"`
 }
 
 // RegistriesResponse response to registries request.
@@ -131,10 +355,112 @@ type BenchmarkBestResponse struct {
 	Runs    map[string]*types.BenchRun `json:"runs"`
 }
 
-// ArtifactsResponse response to artifacts request.
+// ArtifactsResponse response to artifacts request, the artifacts of each
+// run of an experiment.
 type ArtifactsResponse struct {
-	Details   string              `json:"details"`
-	Artifacts map[string][]string `json:"artifacts"`
+	Details   string                    `json:"details"`
+	Artifacts map[string][]artifactInfo `json:"artifacts"`
+}
+
+// RunArtifactsResponse response listing the artifacts of a run.
+type RunArtifactsResponse struct {
+	Details   string         `json:"details"`
+	Artifacts []artifactInfo `json:"artifacts"`
+}
+
+// StorageUsageResponse response reporting the artifact storage used by a
+// run or an experiment. Quota and MaxArtifactSize are zero when unlimited.
+type StorageUsageResponse struct {
+	Details         string             `json:"details"`
+	Usage           types.StorageUsage `json:"usage"`
+	MaxArtifactSize int64              `json:"maxArtifactSize"`
+}
+
+// artifactInfo metadata of a saved artifact.
+type artifactInfo struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// MIMEType the MIME type the content is served with.
+	MIMEType string `json:"mimeType"`
+	// CreatedAt unset for artifacts saved before upload times were
+	// recorded.
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	// Encoding the compression the content is stored with, empty when it
+	// is stored as is.
+	Encoding string `json:"encoding,omitempty"`
+}
+
+func newArtifactInfo(a *types.SavedArtifact) artifactInfo {
+	return artifactInfo{
+		Name:      a.Name,
+		Type:      string(a.ContentType),
+		Size:      a.Size,
+		SHA256:    a.SHA256,
+		MIMEType:  a.ServedMIMEType(),
+		CreatedAt: a.CreatedAt,
+		Encoding:  string(a.Encoding),
+	}
+}
+
+func newArtifactInfos(as []types.SavedArtifact) []artifactInfo {
+	infos := make([]artifactInfo, len(as))
+
+	for i := range as {
+		infos[i] = newArtifactInfo(&as[i])
+	}
+
+	return infos
+}
+
+// ArtifactFilesResponse response listing the files of a directory
+// artifact.
+type ArtifactFilesResponse struct {
+	Details string               `json:"details"`
+	Files   []types.ArtifactFile `json:"files"`
+}
+
+// ArtifactURLResponse response to a pre-signed artifact download request.
+type ArtifactURLResponse struct {
+	Details  string                 `json:"details"`
+	Artifact artifactInfo           `json:"artifact"`
+	Request  types.PresignedRequest `json:"request"`
+}
+
+// ArtifactUploadURLRequest request to upload an artifact with a pre-signed
+// url.
+type ArtifactUploadURLRequest struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ArtifactUploadURLResponse response to ArtifactUploadURLRequest.
+type ArtifactUploadURLResponse struct {
+	Details   string    `json:"details"`
+	UploadID  string    `json:"uploadId"`
+	ExpiresAt time.Time `json:"expiresAt,format:datetime"`
+	// Deduplicated the content is already stored as an artifact of the
+	// run, there is nothing to upload and Request is unset.
+	Deduplicated bool                    `json:"deduplicated"`
+	Request      *types.PresignedRequest `json:"request,omitempty"`
+}
+
+// CompleteUploadResponse response to completing an artifact upload.
+type CompleteUploadResponse struct {
+	Details  string       `json:"details"`
+	Artifact artifactInfo `json:"artifact"`
+}
+
+// ModelURLResponse response to a pre-signed model download request.
+type ModelURLResponse struct {
+	Details string                 `json:"details"`
+	Version int                    `json:"version"`
+	Run     string                 `json:"run"`
+	Name    string                 `json:"name"`
+	Request types.PresignedRequest `json:"request"`
 }
 
 // MetricsResponse response to metrics request.
@@ -145,9 +471,10 @@ type MetricsResponse struct {
 
 // MetricResponse response to metric request.
 type MetricResponse struct {
-	Details string         `json:"details"`
-	Metric  map[string]any `json:"metric"`
-	Kind    string         `json:"kind"`
+	Details string                         `json:"details"`
+	Metric  map[string]any                 `json:"metric"`
+	Points  map[string][]types.MetricPoint `json:"points"`
+	Kind    string                         `json:"kind"`
 }
 
 // KeyLabelsResponse response to keys request.
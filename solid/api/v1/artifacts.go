@@ -1,12 +1,19 @@
 package v1
 
 import (
+	"bufio"
+	"context"
 	"errors"
+	"fmt"
+	"path"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/zeddo123/mlsolid/solid/types"
 )
 
+// headerArtifactSHA256 carries the hex encoded SHA-256 of a downloaded artifact.
+const headerArtifactSHA256 = "X-Artifact-Sha256"
+
 func artifacts(ctx *fiber.Ctx) error {
 	ctrl := ctxController(ctx)
 	expID := ctx.Params("id")
@@ -31,22 +38,83 @@ func artifacts(ctx *fiber.Ctx) error {
 		})
 	}
 
+	infos := make(map[string][]artifactInfo, len(artifacts))
+
+	for id, as := range artifacts {
+		infos[id] = newArtifactInfos(as)
+	}
+
 	return ctx.Status(fiber.StatusOK).JSON(ArtifactsResponse{ //nolint: wrapcheck
-		Artifacts: artifacts,
+		Artifacts: infos,
 		Details:   "successfully retrieved experiment artifacts!",
 	})
 }
 
+func runArtifacts(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	artifacts, err := ctrl.RunArtifacts(c.Context(), c.Params("id"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(RunArtifactsResponse{ //nolint: wrapcheck
+		Details:   "successfully retrieved run artifacts",
+		Artifacts: newArtifactInfos(artifacts),
+	})
+}
+
+func runStorageUsage(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	usage, err := ctrl.RunStorageUsage(c.Context(), c.Params("id"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(StorageUsageResponse{ //nolint: wrapcheck
+		Details:         "successfully retrieved run storage usage",
+		Usage:           *usage,
+		MaxArtifactSize: ctrl.StorageQuota.MaxArtifactSize,
+	})
+}
+
+func expStorageUsage(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	usage, err := ctrl.ExperimentStorageUsage(c.Context(), c.Params("id"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(StorageUsageResponse{ //nolint: wrapcheck
+		Details:         "successfully retrieved experiment storage usage",
+		Usage:           *usage,
+		MaxArtifactSize: ctrl.StorageQuota.MaxArtifactSize,
+	})
+}
+
 func artifact(ctx *fiber.Ctx) error {
 	ctrl := ctxController(ctx)
 	runID := ctx.Params("rid")
 	artifactID := ctx.Params("aid")
 
-	artifact, body, err := ctrl.Artifact(ctx.Context(), runID, artifactID)
-	if errors.Is(err, types.ErrNotFound) {
+	artifact, rng, ranged, body, err := ctrl.ArtifactRange(ctx.Context(), runID, artifactID, ctx.Get(fiber.HeaderRange),
+		ctx.Get(fiber.HeaderAcceptEncoding))
+	if errors.Is(err, types.ErrRangeNotSatisfiable) {
+		ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", artifact.Size))
+
+		return ctx.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	} else if errors.Is(err, types.ErrNotFound) {
 		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
 			Error: "artifact not found",
 		})
+	} else if errors.Is(err, types.ErrBadRequest) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
 	} else if errors.Is(err, types.ErrInternal) || err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
 			Error: "could not retrieve artifact",
@@ -56,6 +124,187 @@ func artifact(ctx *fiber.Ctx) error {
 	defer body.Close() //nolint: errcheck
 
 	ctx.Attachment(artifact.Name)
+	ctx.Set(fiber.HeaderContentType, artifact.ServedMIMEType())
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	if artifact.Encoding != types.IdentityEncoding {
+		// compressed content is sent as is to clients accepting its
+		// encoding, its compressed size being unknown
+		ctx.Set(fiber.HeaderContentEncoding, string(artifact.Encoding))
+		ctx.Set(fiber.HeaderVary, fiber.HeaderAcceptEncoding)
+
+		if artifact.SHA256 != "" {
+			ctx.Set(fiber.HeaderETag, `W/"`+artifact.SHA256+`"`)
+			ctx.Set(headerArtifactSHA256, artifact.SHA256)
+		}
+
+		return ctx.SendStream(body) //nolint: wrapcheck
+	}
+
+	if artifact.SHA256 == "" {
+		return ctx.SendStream(body) //nolint: wrapcheck
+	}
+
+	// content decompressed on the fly has no ranges
+	if ranged {
+		ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+	}
+
+	ctx.Set(fiber.HeaderETag, `"`+artifact.SHA256+`"`)
+	ctx.Set(headerArtifactSHA256, artifact.SHA256)
+
+	if rng != nil {
+		ctx.Set(fiber.HeaderContentRange, rng.ContentRange(artifact.Size))
+
+		return ctx.Status(fiber.StatusPartialContent).SendStream(body, int(rng.Length())) //nolint: wrapcheck
+	}
+
+	return ctx.SendStream(body, int(artifact.Size)) //nolint: wrapcheck
+}
+
+func artifactFiles(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	files, err := ctrl.ArtifactFiles(c.Context(), c.Params("rid"), c.Params("aid"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ArtifactFilesResponse{ //nolint: wrapcheck
+		Details: "successfully retrieved artifact files",
+		Files:   files,
+	})
+}
+
+func artifactFile(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	file, body, err := ctrl.ArtifactFile(c.Context(), c.Params("rid"), c.Params("aid"), c.Params("*"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	defer body.Close() //nolint: errcheck
+
+	c.Attachment(path.Base(file.Path))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	if file.MIMEType != "" {
+		c.Set(fiber.HeaderContentType, file.MIMEType)
+	}
+
+	c.Set(fiber.HeaderETag, `"`+file.SHA256+`"`)
+	c.Set(headerArtifactSHA256, file.SHA256)
+
+	return c.SendStream(body, int(file.Size)) //nolint: wrapcheck
+}
+
+func artifactArchive(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	format, err := types.ParseArchiveFormat(c.Query("format"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	artifact, files, err := ctrl.ArtifactArchive(c.Context(), c.Params("rid"), c.Params("aid"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	c.Attachment(artifact.Name + "." + string(format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		err := ctrl.WriteArchive(context.Background(), files, format, w)
+		if err == nil {
+			err = w.Flush()
+		}
+
+		if err != nil {
+			ctrl.Logger.Error().Err(err).Str("artifact", artifact.Name).Msg("could not stream artifact archive")
+		}
+	})
+
+	return nil
+}
+
+// artifactError responds with the status matching an artifact transfer
+// error.
+func artifactError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, types.ErrBadRequest):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, types.ErrNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, types.ErrAlreadyInUse), errors.Is(err, types.ErrInvalidInput):
+		status = fiber.StatusConflict
+	case errors.Is(err, types.ErrResourceExhausted):
+		status = fiber.StatusRequestEntityTooLarge
+	}
+
+	return c.Status(status).JSON(ErrorResponse{ //nolint: wrapcheck
+		Error: err.Error(),
+	})
+}
+
+func artifactDownloadURL(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	artifact, req, err := ctrl.ArtifactDownloadURL(c.Context(), c.Params("rid"), c.Params("aid"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ArtifactURLResponse{ //nolint: wrapcheck
+		Details:  "pre-signed artifact download",
+		Artifact: newArtifactInfo(artifact),
+		Request:  *req,
+	})
+}
+
+func artifactUploadURL(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	var request ArtifactUploadURLRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
 
-	return ctx.SendStream(body) //nolint: wrapcheck
+	session, req, err := ctrl.ArtifactUploadURL(c.Context(), c.Params("id"), request.Name, request.Type,
+		request.Size, request.SHA256)
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	details := "pre-signed artifact upload, complete it once the content is uploaded"
+	if session.Deduplicated {
+		details = "content already stored, complete the upload"
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(ArtifactUploadURLResponse{ //nolint: wrapcheck
+		Details:      details,
+		UploadID:     session.ID,
+		ExpiresAt:    session.ExpiresAt,
+		Deduplicated: session.Deduplicated,
+		Request:      req,
+	})
+}
+
+func completeArtifactUpload(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	artifact, err := ctrl.CompleteArtifactUpload(c.Context(), c.Params("id"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(CompleteUploadResponse{ //nolint: wrapcheck
+		Details:  "artifact uploaded successfully",
+		Artifact: newArtifactInfo(artifact),
+	})
 }
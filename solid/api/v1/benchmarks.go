@@ -1,9 +1,14 @@
 package v1
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/zeddo123/mlsolid/solid/types"
@@ -202,3 +207,103 @@ func benchmarkBest(c *fiber.Ctx) error {
 		Details: "best models retrieved successfully",
 	})
 }
+
+// watchBenchmarkRunBlock bounds how long the watch stream blocks on new log
+// entries before sending a keep-alive comment to the client.
+const watchBenchmarkRunBlock = 15 * time.Second
+
+// watchBenchmarkRun streams the logs and progress of a benchmark run as
+// server-sent events. Clients resume with the Last-Event-ID header (or the
+// "after" query param); the stream ends with an "end" event.
+func watchBenchmarkRun(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	id := c.Params("id")
+	registry := c.Params("registry")
+
+	version, err := strconv.ParseInt(c.Params("version"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: "version must be an integer",
+		})
+	}
+
+	after := c.Get("Last-Event-ID", c.Query("after"))
+
+	// Pull what is already there without blocking, which also surfaces
+	// unknown benchmarks before the stream is opened.
+	logs, err := ctrl.BenchRunLogs(c.Context(), id, registry, version, after, -1)
+	if errors.Is(err, types.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx := context.Background()
+
+		for {
+			for _, l := range logs {
+				if err := writeBenchRunLogEvent(w, l); err != nil {
+					return
+				}
+
+				if l.End {
+					return
+				}
+
+				after = l.ID
+			}
+
+			if len(logs) == 0 {
+				// keep-alive, also detects disconnected clients
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+
+			logs, err = ctrl.BenchRunLogs(ctx, id, registry, version, after, watchBenchmarkRunBlock)
+			if err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeBenchRunLogEvent(w *bufio.Writer, l types.BenchRunLog) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("could not marshal benchmark run log: %w", err)
+	}
+
+	event := "log"
+	if l.End {
+		event = "end"
+	} else if l.Progress != nil {
+		event = "progress"
+	}
+
+	if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", l.ID, event, data); err != nil {
+		return fmt.Errorf("could not write event: %w", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("could not flush event: %w", err)
+	}
+
+	return nil
+}
@@ -1,7 +1,10 @@
 package v1
 
 import (
+	"errors"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/zeddo123/mlsolid/solid/types"
@@ -47,16 +50,31 @@ func experiment(ctx *fiber.Ctx) error {
 
 	expID := ctx.Params("id")
 
-	runs, err := ctrl.ExpRuns(ctx.Context(), expID)
-	if err != nil {
+	filter := types.RunFilter{
+		Params: queryFilters(ctx, paramFiltersPrefix),
+		Tags:   queryFilters(ctx, tagFiltersPrefix),
+	}
+
+	info, err := ctrl.ExpInfo(ctx.Context(), expID)
+	if errors.Is(err, types.ErrNotFound) {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: err.Error(),
+		})
+	} else if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
 		})
 	}
 
-	if len(runs) == 0 {
-		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-			Error: "not runs found for this experiment",
+	// experiments created ahead of their first run have no runs
+	runs, err := ctrl.ExpRunsMatching(ctx.Context(), expID, filter)
+	if errors.Is(err, types.ErrBadRequest) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: err.Error(),
+		})
+	} else if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: err.Error(),
 		})
 	}
 
@@ -71,19 +89,181 @@ func experiment(ctx *fiber.Ctx) error {
 
 	for i, r := range rs {
 		if r != nil {
-			runsInfo[i] = runInfo{
-				RunID:     r.Name,
-				CreatedAt: r.Timestamp,
-				Color:     r.Color,
-			}
+			runsInfo[i] = newRunInfo(r)
 		}
 	}
 
 	out := ExperimentResponse{
 		Details: "successfully retrieved experiment",
+		Info:    newExperimentInfo(info),
 		Runs:    runsInfo,
 		Metrics: types.UniqueMetrics(rs),
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(out)
 }
+
+// Prefixes of the query params filtering runs by param or tag,
+// e.g. ?param.lr=0.01&param.optimizer=adam&tag.baseline=true.
+const (
+	paramFiltersPrefix = "param."
+	tagFiltersPrefix   = "tag."
+)
+
+func queryFilters(ctx *fiber.Ctx, prefix string) map[string]string {
+	filters := make(map[string]string)
+
+	for k, v := range ctx.Queries() {
+		if name, ok := strings.CutPrefix(k, prefix); ok && name != "" {
+			filters[name] = v
+		}
+	}
+
+	return filters
+}
+
+// deleteExperiment deletes an experiment and its runs. It fails with 409
+// when one of their artifacts is registered in a model registry.
+func deleteExperiment(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	ids, err := ctrl.DeleteExperiment(c.Context(), c.Params("id"))
+
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case errors.Is(err, types.ErrAlreadyInUse):
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(DeleteRunsResponse{ //nolint: wrapcheck
+		Details:     "experiment deleted successfully",
+		DeletedRuns: ids,
+	})
+}
+
+func archiveExperiment(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	err := ctrl.ArchiveExperiment(c.Context(), c.Params("id"))
+	if errors.Is(err, types.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ArchiveResponse{ //nolint: wrapcheck
+		Details: "experiment archived successfully",
+	})
+}
+
+func restoreExperiment(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	err := ctrl.RestoreExperiment(c.Context(), c.Params("id"))
+	if errors.Is(err, types.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ArchiveResponse{ //nolint: wrapcheck
+		Details: "experiment restored successfully",
+	})
+}
+
+func createExperiment(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	var request CreateExperimentRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	err := ctrl.CreateExperiment(c.Context(), request.ID, types.ExperimentInfo{
+		Name:        request.Name,
+		Description: request.Description,
+		Owner:       request.Owner,
+		Tags:        request.Tags,
+		CreatedAt:   time.Time{},
+	})
+
+	switch {
+	case errors.Is(err, types.ErrBadRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case errors.Is(err, types.ErrAlreadyInUse):
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(CreateExperimentResponse{ //nolint: wrapcheck
+		Details: "experiment created successfully",
+		ID:      types.NormalizeID(request.ID),
+	})
+}
+
+func updateExperiment(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	var request UpdateExperimentRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	info, err := ctrl.UpdateExperiment(c.Context(), c.Params("id"), types.UpdateExperiment{
+		Name:        request.Name,
+		Description: request.Description,
+		Owner:       request.Owner,
+		Tags:        request.Tags,
+		RemoveTags:  request.RemoveTags,
+	})
+
+	switch {
+	case errors.Is(err, types.ErrBadRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case errors.Is(err, types.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(UpdateExperimentResponse{ //nolint: wrapcheck
+		Details: "experiment updated successfully",
+		Info:    newExperimentInfo(info),
+	})
+}
@@ -1,6 +1,15 @@
 package v1
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/zeddo123/mlsolid/solid/types"
 )
@@ -24,29 +33,216 @@ func metrics(ctx *fiber.Ctx) error {
 	})
 }
 
+// metric returns the values of a metric for every run of an experiment.
+// Values can be windowed by step (startStep, endStep) or server time
+// (since, until, RFC 3339), downsampled to maxPoints with the downsample
+// method (lttb or minmax), or reduced to the last value (last=true).
 func metric(ctx *fiber.Ctx) error {
 	ctrl := ctxController(ctx)
 	expID := ctx.Params("id")
 	metricID := ctx.Params("mid")
 
-	rs, err := ctrl.RunsFromExp(ctx.Context(), expID)
+	q, err := parseMetricQuery(ctx)
 	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+
+	ms, err := ctrl.ExpMetric(ctx.Context(), expID, metricID, q)
+	if errors.Is(err, types.ErrBadRequest) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: err.Error(),
+		})
+	} else if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
 		})
 	}
 
-	if len(rs) == 0 {
+	if len(ms) == 0 {
 		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{
 			Error: "no metrics were found",
 		})
 	}
 
-	metric, kind := types.CollectMetric(rs, metricID)
+	metric, kind := types.MetricVals(ms)
 
 	return ctx.Status(fiber.StatusOK).JSON(MetricResponse{
 		Details: "metric retrieved successfully",
 		Metric:  metric,
+		Points:  types.MetricPoints(ms),
 		Kind:    string(kind),
 	})
 }
+
+// watchRunMetricsBlock bounds how long the watch stream blocks on new metric
+// values before sending a keep-alive comment to the client.
+const watchRunMetricsBlock = 15 * time.Second
+
+// watchRunMetrics streams the metric values logged by a run as server-sent
+// events, restricted to the comma-separated "metrics" query param when set.
+// Clients resume with the Last-Event-ID header (or the "after" query param);
+// the stream ends with an "end" event once the run has ended.
+func watchRunMetrics(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	id := c.Params("id")
+
+	var names []string
+
+	for _, m := range strings.Split(c.Query("metrics"), ",") {
+		if trimmed := strings.TrimSpace(m); trimmed != "" {
+			names = append(names, trimmed)
+		}
+	}
+
+	cursor := types.NewMetricCursor(c.Get("Last-Event-ID", c.Query("after")))
+
+	// Pull what is already there without blocking, which also surfaces
+	// unknown runs before the stream is opened.
+	events, done, err := ctrl.RunMetricEvents(c.Context(), id, names, cursor, -1)
+	if errors.Is(err, types.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx := context.Background()
+
+		for {
+			for _, e := range events {
+				if err := writeMetricEvent(w, e); err != nil {
+					return
+				}
+			}
+
+			if done {
+				_ = writeMetricsEndEvent(w)
+
+				return
+			}
+
+			if len(events) == 0 {
+				// keep-alive, also detects disconnected clients
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+
+			events, done, err = ctrl.RunMetricEvents(ctx, id, names, cursor, watchRunMetricsBlock)
+			if err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeMetricEvent(w *bufio.Writer, e types.MetricEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("could not marshal metric event: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(w, "id: %s\nevent: metric\ndata: %s\n\n", e.ID, data); err != nil {
+		return fmt.Errorf("could not write event: %w", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("could not flush event: %w", err)
+	}
+
+	return nil
+}
+
+func writeMetricsEndEvent(w *bufio.Writer) error {
+	if _, err := w.WriteString("event: end\ndata: {}\n\n"); err != nil {
+		return fmt.Errorf("could not write event: %w", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("could not flush event: %w", err)
+	}
+
+	return nil
+}
+
+func parseMetricQuery(ctx *fiber.Ctx) (types.MetricQuery, error) {
+	var (
+		q   types.MetricQuery
+		err error
+	)
+
+	if q.StartStep, err = queryStep(ctx, "startStep"); err != nil {
+		return q, err
+	}
+
+	if q.EndStep, err = queryStep(ctx, "endStep"); err != nil {
+		return q, err
+	}
+
+	if q.Since, err = queryTime(ctx, "since"); err != nil {
+		return q, err
+	}
+
+	if q.Until, err = queryTime(ctx, "until"); err != nil {
+		return q, err
+	}
+
+	q.MaxPoints, err = strconv.Atoi(ctx.Query("maxPoints", "0"))
+	if err != nil {
+		return q, errors.New("maxPoints query param is malformed")
+	}
+
+	q.Downsample, err = types.ParseDownsampleMethod(ctx.Query("downsample"))
+	if err != nil {
+		return q, err //nolint: wrapcheck
+	}
+
+	q.LastOnly = ctx.QueryBool("last", false)
+
+	return q, nil
+}
+
+func queryStep(ctx *fiber.Ctx, param string) (*int64, error) {
+	raw := ctx.Query(param)
+	if raw == "" {
+		return nil, nil //nolint: nilnil
+	}
+
+	step, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s query param is malformed", param) //nolint: err113
+	}
+
+	return &step, nil
+}
+
+func queryTime(ctx *fiber.Ctx, param string) (*time.Time, error) {
+	raw := ctx.Query(param)
+	if raw == "" {
+		return nil, nil //nolint: nilnil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s query param must be an RFC 3339 time", param) //nolint: err113
+	}
+
+	return &t, nil
+}
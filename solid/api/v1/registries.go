@@ -55,6 +55,7 @@ func registry(ctx *fiber.Ctx) error {
 			Tags:      entry.Tags,
 			Name:      entry.Name,
 			Run:       entry.Run,
+			Files:     entry.Files,
 		}
 	}
 
@@ -102,3 +103,20 @@ func createRegistry(ctx *fiber.Ctx) error {
 		ID:      payload.Name,
 	})
 }
+
+func taggedModelDownloadURL(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	entry, req, err := ctrl.TaggedModelDownloadURL(c.Context(), c.Params("id"), c.Params("tag"))
+	if err != nil {
+		return artifactError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ModelURLResponse{ //nolint: wrapcheck
+		Details: "pre-signed model download",
+		Version: entry.Version,
+		Run:     entry.Run,
+		Name:    entry.Name,
+		Request: *req,
+	})
+}
@@ -0,0 +1,237 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func updateRun(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	id := c.Params("id")
+
+	var request UpdateRunRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	tags, err := ctrl.SetRunTags(c.Context(), id, request.Tags, request.RemoveTags)
+	if err == nil && request.Note != nil {
+		err = ctrl.SetRunNote(c.Context(), id, *request.Note)
+	}
+
+	switch {
+	case errors.Is(err, types.ErrBadRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case errors.Is(err, types.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	run, err := ctrl.Run(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(UpdateRunResponse{ //nolint: wrapcheck
+		Details: "run updated successfully",
+		Tags:    tags,
+		Note:    run.Note,
+	})
+}
+
+// searchRuns searches runs across experiments, see types.ParseRunFilter for
+// the filter language. exps is a comma-separated list of experiment ids.
+func searchRuns(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	cursor, limit, err := parsePagination(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	var exps []string
+	if raw := c.Query("exps"); raw != "" {
+		exps = strings.Split(raw, ",")
+	}
+
+	q, err := types.NewRunQuery(exps, c.Query("filter"), c.Query("order"), cursor, limit)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	runs, next, err := ctrl.SearchRuns(c.Context(), q)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	infos := make([]runInfo, len(runs))
+
+	for i, r := range runs {
+		infos[i] = newRunInfo(r)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SearchRunsResponse{ //nolint: wrapcheck
+		Details: "successfully searched runs",
+		Runs:    infos,
+		Cursor:  strconv.FormatUint(next, 10),
+	})
+}
+
+func childRuns(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	runs, err := ctrl.ChildRuns(c.Context(), c.Params("id"))
+	if errors.Is(err, types.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	infos := make([]runInfo, len(runs))
+
+	for i, r := range runs {
+		infos[i] = newRunInfo(r)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ChildRunsResponse{ //nolint: wrapcheck
+		Details:    "successfully retrieved child runs",
+		Runs:       infos,
+		Aggregates: types.AggregateFinalMetrics(runs),
+	})
+}
+
+// deleteRun deletes a run and the runs nested under it. It fails with 409
+// when one of their artifacts is registered in a model registry.
+func deleteRun(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	ids, err := ctrl.DeleteRun(c.Context(), c.Params("id"))
+
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case errors.Is(err, types.ErrAlreadyInUse):
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(DeleteRunsResponse{ //nolint: wrapcheck
+		Details:     "run deleted successfully",
+		DeletedRuns: ids,
+	})
+}
+
+func archiveRun(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	err := ctrl.ArchiveRun(c.Context(), c.Params("id"))
+	if errors.Is(err, types.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ArchiveResponse{ //nolint: wrapcheck
+		Details: "run archived successfully",
+	})
+}
+
+func restoreRun(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	err := ctrl.RestoreRun(c.Context(), c.Params("id"))
+	if errors.Is(err, types.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ArchiveResponse{ //nolint: wrapcheck
+		Details: "run restored successfully",
+	})
+}
+
+func compareRuns(c *fiber.Ctx) error {
+	ctrl := ctxController(c)
+
+	var request CompareRunsRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	method, err := types.ParseDownsampleMethod(request.Downsample)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	comparison, err := ctrl.CompareRuns(c.Context(), request.RunIDs, request.Metrics, types.MetricQuery{ //nolint: exhaustruct
+		StartStep:  request.StartStep,
+		EndStep:    request.EndStep,
+		MaxPoints:  request.MaxPoints,
+		Downsample: method,
+	})
+
+	switch {
+	case errors.Is(err, types.ErrBadRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case errors.Is(err, types.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{ //nolint: wrapcheck
+			Error: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(newCompareRunsResponse(comparison)) //nolint: wrapcheck
+}
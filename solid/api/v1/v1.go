@@ -49,16 +49,40 @@ func BuildRoutes(f *fiber.App, middlewares ...fiber.Handler) error {
 
 	v1.Get("/exps", experiments)
 	v1.Get("/exp/:id", experiment)
+	v1.Post("/exp", createExperiment)
+	v1.Patch("/exp/:id", updateExperiment)
+	v1.Delete("/exp/:id", deleteExperiment)
+	v1.Put("/exp/:id/archive", archiveExperiment)
+	v1.Put("/exp/:id/restore", restoreExperiment)
+
+	v1.Get("/runs", searchRuns)
+	v1.Post("/runs/compare", compareRuns)
+	v1.Patch("/run/:id", updateRun)
+	v1.Get("/run/:id/children", childRuns)
+	v1.Delete("/run/:id", deleteRun)
+	v1.Put("/run/:id/archive", archiveRun)
+	v1.Put("/run/:id/restore", restoreRun)
 
 	v1.Get("/exp/:id/metrics", metrics)
 	v1.Get("/exp/:id/metric/:mid", metric)
+	v1.Get("/run/:id/metrics/watch", watchRunMetrics)
 
 	v1.Get("/exp/:id/artifacts", artifacts)
+	v1.Get("/run/:id/artifacts", runArtifacts)
+	v1.Get("/run/:id/usage", runStorageUsage)
+	v1.Get("/exp/:id/usage", expStorageUsage)
 	v1.Get("/artifact/:rid/:aid", artifact)
+	v1.Get("/artifact/:rid/:aid/url", artifactDownloadURL)
+	v1.Get("/artifact/:rid/:aid/files", artifactFiles)
+	v1.Get("/artifact/:rid/:aid/files/*", artifactFile)
+	v1.Get("/artifact/:rid/:aid/archive", artifactArchive)
+	v1.Post("/run/:id/artifact/url", artifactUploadURL)
+	v1.Post("/upload/:id/complete", completeArtifactUpload)
 
 	v1.Get("/registries", registries)
 	v1.Get("/registry/:id", registry)
 	v1.Post("/registry", createRegistry)
+	v1.Get("/registry/:id/tag/:tag/url", taggedModelDownloadURL)
 
 	v1.Get("/benchmarks", benchmarks)
 	v1.Get("/benchmark/:id", benchmark)
@@ -68,6 +92,7 @@ func BuildRoutes(f *fiber.App, middlewares ...fiber.Handler) error {
 	v1.Delete("/benchmark/:id", deleteBenchmark)
 	v1.Get("/benchmark/:id/runs", benchmarkRuns)
 	v1.Get("/benchmark/:id/best", benchmarkBest)
+	v1.Get("/benchmark/:id/run/:registry/:version/watch", watchBenchmarkRun)
 
 	v1.Get("/keys", keys)
 	v1.Post("/key", key)
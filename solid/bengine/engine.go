@@ -16,6 +16,7 @@ import (
 	"github.com/docker/cli/opts"
 	ctr "github.com/docker/go-sdk/container"
 	"github.com/moby/moby/api/pkg/authconfig"
+	"github.com/moby/moby/api/pkg/stdcopy"
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/api/types/mount"
 	"github.com/moby/moby/api/types/registry"
@@ -35,7 +36,9 @@ type Opts func(cfg *Config)
 type RunRecorder interface {
 	RecordRuns(ctx context.Context, benchID string, runs []types.BenchRun) error
 	SetActiveBenchRun(ctx context.Context, benchID string, run types.BenchRun) error
-	RemActiveBenchRun(ctx context.Context, benchID string) error
+	HeartbeatActiveBenchRun(ctx context.Context, benchID, registry string, version int64) error
+	RemActiveBenchRun(ctx context.Context, benchID, registry string, version int64) error
+	AppendBenchRunLogs(ctx context.Context, benchID, registry string, version int64, logs ...types.BenchRunLog) error
 }
 
 // Engine is a benchmark runner with docker containers.
@@ -207,9 +210,23 @@ func (e *Engine) Start(ctx context.Context) {
 
 // ConsumeEvent handles a benchmarking event.
 func (e *Engine) ConsumeEvent(ctx context.Context, event *types.BenchEvent) error {
+	// Stream the container's output to the recorder so the run can be
+	// followed live; the writer is closed even on failure, failures to
+	// prepare the run being written to it, so that watchers see the end of
+	// the run.
+	var logs io.Writer
+
+	if e.recorder != nil {
+		w := newBenchLogWriter(ctx, e.recorder, event, e.l)
+		defer w.Close() //nolint: errcheck
+
+		logs = w
+	}
+
 	err := e.pullImage(ctx, event.DockerImage)
 	if err != nil {
 		e.l.Error().Err(err).Msg("could not pull docker image")
+		writeBenchLogErr(logs, "could not pull docker image", err)
 
 		return err
 	}
@@ -224,6 +241,8 @@ func (e *Engine) ConsumeEvent(ctx context.Context, event *types.BenchEvent) erro
 		// Downloading dataset
 		err := e.PullDataset(ctx, event.DatasetURL, datasetPath, event.FromS3)
 		if err != nil {
+			writeBenchLogErr(logs, "could not pull dataset", err)
+
 			return err
 		}
 	}
@@ -239,7 +258,9 @@ func (e *Engine) ConsumeEvent(ctx context.Context, event *types.BenchEvent) erro
 			Msg("checking if model checkpoint is already present")
 
 		if _, err := os.Stat(checkpointPath); errors.Is(err, os.ErrNotExist) {
-			if err := e.PullModel(ctx, event.ModelURL, checkpointPath); err != nil {
+			if err := e.PullModel(ctx, event.ModelURL, event.ModelEncoding, checkpointPath); err != nil {
+				writeBenchLogErr(logs, "could not pull model checkpoint", err)
+
 				return err
 			}
 		}
@@ -250,7 +271,7 @@ func (e *Engine) ConsumeEvent(ctx context.Context, event *types.BenchEvent) erro
 	start := time.Now()
 
 	result, err := e.RunContainer(ctx, event.DockerImage,
-		event.DatasetName, datasetPath, checkpointName, checkpointPath)
+		event.DatasetName, datasetPath, checkpointName, checkpointPath, logs)
 	if err != nil {
 		return err
 	}
@@ -316,19 +337,27 @@ func (e *Engine) PullDataset(ctx context.Context, url string, outputPath string,
 	return nil
 }
 
-// PullModel pulls a model checkpoint from the configured object store to outputPath.
-func (e *Engine) PullModel(ctx context.Context, key string, outputPath string) error {
+// PullModel pulls a model checkpoint from the configured object store to outputPath,
+// decompressing it from the encoding it is stored with.
+func (e *Engine) PullModel(ctx context.Context, key string, encoding types.ContentEncoding, outputPath string) error {
 	if e.s3 == nil {
 		return errors.New("could not pull model checkpoint: s3 store not configured")
 	}
 
 	e.l.Info().Str("key", key).Msg("Downloading model checkpoint")
 
-	content, err := e.s3.DownloadFile(ctx, key)
+	body, err := e.s3.DownloadFile(ctx, key)
 	if err != nil {
 		return fmt.Errorf("could not download model checkpoint: %w", err)
 	}
 
+	content, err := encoding.NewDecoder(body)
+	if err != nil {
+		body.Close() //nolint: errcheck
+
+		return fmt.Errorf("could not decompress model checkpoint: %w", err)
+	}
+
 	defer content.Close() //nolint: errcheck
 
 	mod := 0o755
@@ -353,8 +382,10 @@ func (e *Engine) PullModel(ctx context.Context, key string, outputPath string) e
 }
 
 // RunContainer runs a benchmark on a container with a specified image, dataset, and checkpoint.
+// When logs is not nil, the container's stdout and stderr are copied to it while it runs.
 func (e *Engine) RunContainer(
 	ctx context.Context, image, datasetName, datasetPath, checkpointName, checkpointPath string,
+	logs io.Writer,
 ) (string, error) {
 	outputPath := "/run/output.json"
 
@@ -412,6 +443,14 @@ func (e *Engine) RunContainer(
 		return "", fmt.Errorf("could not exec container %q: %w", image, err)
 	}
 
+	followed := make(chan struct{})
+
+	if logs != nil {
+		go e.followLogs(ctx, c, logs, followed)
+	} else {
+		close(followed)
+	}
+
 	e.l.Debug().
 		Str("container", c.ShortID()).
 		Msg("waiting for container to exit")
@@ -425,15 +464,23 @@ func (e *Engine) RunContainer(
 	case <-wait.Result:
 	}
 
-	logs, err := c.Logs(ctx)
+	// The follow stream ends once the container stops; wait for the last
+	// lines to be written before moving on.
+	select {
+	case <-followed:
+	case <-time.After(followLogsDrainTimeout):
+		e.l.Warn().Str("container", c.ShortID()).Msg("timed out draining container logs")
+	}
+
+	containerLogs, err := c.Logs(ctx)
 	if err != nil {
 		e.l.Error().Err(err).
 			Str("container", c.ShortID()).
 			Msg("could not pull logs from container")
 	}
-	defer logs.Close() //nolint: errcheck
+	defer containerLogs.Close() //nolint: errcheck
 
-	logsContent, err := io.ReadAll(logs)
+	logsContent, err := io.ReadAll(containerLogs)
 	if err != nil {
 		e.l.Error().Err(err).
 			Str("container", c.ShortID()).
@@ -476,6 +523,36 @@ func (e *Engine) RunContainer(
 	return string(results), nil
 }
 
+// followLogsDrainTimeout bounds how long RunContainer waits for the log
+// follower to catch up once the container has exited.
+const followLogsDrainTimeout = 10 * time.Second
+
+// followLogs copies the demultiplexed output of c to w until the container
+// stops or ctx is done, then closes done.
+func (e *Engine) followLogs(ctx context.Context, c *ctr.Container, w io.Writer, done chan<- struct{}) {
+	defer close(done)
+
+	rc, err := c.Client().ContainerLogs(ctx, c.ID(), client.ContainerLogsOptions{ //nolint: exhaustruct
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		e.l.Error().Err(err).
+			Str("container", c.ShortID()).
+			Msg("could not follow container logs")
+
+		return
+	}
+	defer rc.Close() //nolint: errcheck
+
+	if _, err := stdcopy.StdCopy(w, w, rc); err != nil && !errors.Is(err, context.Canceled) {
+		e.l.Error().Err(err).
+			Str("container", c.ShortID()).
+			Msg("could not stream container logs")
+	}
+}
+
 // RecordRun records a run into the store.
 func (e *Engine) RecordRun(ctx context.Context, event *types.BenchEvent, start, end time.Time, result string) error {
 	metrics := make(map[string]float32)
@@ -515,9 +592,13 @@ func (e *Engine) RecordRun(ctx context.Context, event *types.BenchEvent, start,
 // giving up and just logging. They guard against short-lived store hiccups
 // (e.g. a transient Redis connection blip); a sustained outage still leaves
 // the marker stuck, which activeBenchRunTTL (see store.RedisStore) bounds.
+//
+// activeBenchRunHeartbeat is how often the marker's expiry is pushed back
+// while the run executes; it must stay well below activeBenchRunTTL.
 const (
 	activeBenchRunRetries      = 3
 	activeBenchRunRetryBackoff = 2 * time.Second
+	activeBenchRunHeartbeat    = time.Minute
 )
 
 // handleEvent runs a single benchmark event, keeping the recorder's active
@@ -537,9 +618,14 @@ func (e *Engine) handleEvent(ctx context.Context, event *types.BenchEvent) {
 		if err != nil {
 			e.l.Error().Err(err).Msg("could not set active benchmark run")
 		} else {
+			stop := make(chan struct{})
+			go e.heartbeatActiveRun(ctx, event, stop)
+
 			defer func() {
+				close(stop)
+
 				err := retryWithBackoff(ctx, activeBenchRunRetries, activeBenchRunRetryBackoff, func() error {
-					return e.recorder.RemActiveBenchRun(ctx, event.BenchID)
+					return e.recorder.RemActiveBenchRun(ctx, event.BenchID, event.Registry, event.Version)
 				})
 				if err != nil {
 					e.l.Error().Err(err).Msg("could not remove active benchmark run")
@@ -553,6 +639,31 @@ func (e *Engine) handleEvent(ctx context.Context, event *types.BenchEvent) {
 	}
 }
 
+// heartbeatActiveRun keeps the recorder's active run marker of event alive
+// until stop is closed or ctx is done.
+func (e *Engine) heartbeatActiveRun(ctx context.Context, event *types.BenchEvent, stop <-chan struct{}) {
+	ticker := time.NewTicker(activeBenchRunHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := e.recorder.HeartbeatActiveBenchRun(ctx, event.BenchID, event.Registry, event.Version)
+			if err != nil {
+				e.l.Error().Err(err).
+					Str("benchID", event.BenchID).
+					Str("registry", event.Registry).
+					Int64("version", event.Version).
+					Msg("could not heartbeat active benchmark run")
+			}
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // retryWithBackoff calls fn until it succeeds or attempts are exhausted,
 // waiting backoff between tries. It returns early if ctx is done.
 func retryWithBackoff(ctx context.Context, attempts int, backoff time.Duration, fn func() error) error {
@@ -0,0 +1,158 @@
+package bengine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// benchLogFlushInterval how often a benchLogWriter pushes the complete lines
+// it buffered to the recorder, and benchLogBatchSize how many lines are
+// buffered before an early flush.
+const (
+	benchLogFlushInterval = time.Second
+	benchLogBatchSize     = 64
+)
+
+// benchLogWriter splits container output into lines and forwards them, in
+// batches, to the recorder's log stream of a single benchmark run. Lines
+// carrying a types.BenchProgressPrefix marker are recorded with their parsed
+// progress. Buffered lines are flushed every benchLogFlushInterval until the
+// writer is closed, so that output reaches watchers even when the container
+// goes quiet. It is safe for concurrent use (stdout and stderr share it).
+type benchLogWriter struct {
+	ctx      context.Context //nolint: containedctx
+	recorder RunRecorder
+	benchID  string
+	registry string
+	version  int64
+	l        zerolog.Logger
+
+	mu      sync.Mutex
+	partial []byte
+	pending []types.BenchRunLog
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newBenchLogWriter(ctx context.Context, recorder RunRecorder, event *types.BenchEvent,
+	l zerolog.Logger,
+) *benchLogWriter {
+	w := &benchLogWriter{ //nolint: exhaustruct
+		ctx:      ctx,
+		recorder: recorder,
+		benchID:  event.BenchID,
+		registry: event.Registry,
+		version:  event.Version,
+		l:        l,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go w.flushLoop()
+
+	return w
+}
+
+// flushLoop flushes the buffered lines every benchLogFlushInterval until
+// the writer is closed.
+func (w *benchLogWriter) flushLoop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(benchLogFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			w.flush()
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Write implements io.Writer. It never fails: recorder errors are logged so
+// that a store hiccup doesn't interrupt the container's log stream.
+func (w *benchLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := string(bytes.TrimRight(w.partial[:i], "\r"))
+		w.partial = w.partial[i+1:]
+
+		w.pending = append(w.pending, types.NewBenchRunLog(line))
+	}
+
+	if len(w.pending) >= benchLogBatchSize {
+		w.flush()
+	}
+
+	return len(p), nil
+}
+
+// Close flushes any buffered output and appends the end-of-run marker so
+// that watchers know no more lines will follow. It must be called once.
+func (w *benchLogWriter) Close() error {
+	close(w.stop)
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		w.pending = append(w.pending, types.NewBenchRunLog(string(w.partial)))
+		w.partial = nil
+	}
+
+	end := types.NewBenchRunLog("")
+	end.End = true
+
+	w.pending = append(w.pending, end)
+	w.flush()
+
+	return nil
+}
+
+// writeBenchLogErr writes a failure to prepare a benchmark run to its log
+// stream, if any, so that watchers see why the run ended.
+func writeBenchLogErr(logs io.Writer, msg string, err error) {
+	if logs != nil {
+		_, _ = fmt.Fprintf(logs, "%s: %v\n", msg, err)
+	}
+}
+
+// flush must be called with w.mu held.
+func (w *benchLogWriter) flush() {
+	if len(w.pending) == 0 {
+		return
+	}
+
+	err := w.recorder.AppendBenchRunLogs(w.ctx, w.benchID, w.registry, w.version, w.pending...)
+	if err != nil {
+		w.l.Error().Err(err).
+			Str("benchID", w.benchID).
+			Str("registry", w.registry).
+			Int64("version", w.version).
+			Msg("could not append benchmark run logs")
+	}
+
+	w.pending = w.pending[:0]
+}
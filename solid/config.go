@@ -3,6 +3,7 @@ package solid
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -35,6 +36,22 @@ type Config struct {
 	S3Region   string `mapstructure:"s3_region"`
 	S3Prefix   string `mapstructure:"s3_prefix"`
 
+	ArtifactUploadTTL     time.Duration `mapstructure:"artifact_upload_ttl"`
+	ArtifactPresignedURLs bool          `mapstructure:"artifact_presigned_urls"`
+	ArtifactPresignTTL    time.Duration `mapstructure:"artifact_presign_ttl"`
+	ArtifactGCInterval    time.Duration `mapstructure:"artifact_gc_interval"`
+	ArtifactGCGrace       time.Duration `mapstructure:"artifact_gc_grace"`
+	// ArtifactCompression the encoding artifacts of each content type are
+	// compressed with, e.g. {"content-type/text": "zstd"}.
+	ArtifactCompression map[string]string `mapstructure:"artifact_compression"`
+	// ArtifactMaxSize, RunStorageQuota and ExpStorageQuota are in bytes,
+	// zero being unlimited.
+	ArtifactMaxSize int64 `mapstructure:"artifact_max_size"`
+	RunStorageQuota int64 `mapstructure:"run_storage_quota"`
+	ExpStorageQuota int64 `mapstructure:"exp_storage_quota"`
+
+	RunHeartbeatTimeout time.Duration `mapstructure:"run_heartbeat_timeout"`
+
 	EnableBEngine          bool   `mapstructure:"enable_bengine"`
 	BEngineRootDest        string `mapstructure:"bengine_root_dest"`
 	DockerRegistryUsername string `mapstructure:"docker_registry_username"`
@@ -76,6 +93,18 @@ func LoadConfig(path string) (Config, error) {
 	viper.SetDefault("s3_region", "")
 	viper.SetDefault("s3_prefix", "artifacts")
 
+	viper.SetDefault("artifact_upload_ttl", "24h")
+	viper.SetDefault("artifact_presigned_urls", false)
+	viper.SetDefault("artifact_presign_ttl", "15m")
+	viper.SetDefault("artifact_gc_interval", "0s")
+	viper.SetDefault("artifact_gc_grace", "24h")
+	viper.SetDefault("artifact_compression", map[string]string{})
+	viper.SetDefault("artifact_max_size", 0)
+	viper.SetDefault("run_storage_quota", 0)
+	viper.SetDefault("exp_storage_quota", 0)
+
+	viper.SetDefault("run_heartbeat_timeout", "0s")
+
 	viper.SetDefault("enable_bengine", false)
 	viper.SetDefault("bengine_root_dest", "/mlsolid/")
 	viper.SetDefault("docker_registry_username", "")
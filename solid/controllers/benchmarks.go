@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/zeddo123/mlsolid/solid/types"
 )
@@ -121,11 +122,12 @@ func (c *Controller) RecordRuns(ctx context.Context, benchID string, runs []type
 	return nil
 }
 
-// SetActiveBenchRun marks run as the one currently executing for benchID, so
-// that Benchmark's ActiveBenchRun field reflects it before the run finishes
-// and is persisted via RecordRuns. Setting a new active run replaces any
-// previous one. Callers should follow up with RemActiveBenchRun once the
-// run completes, whether it succeeded or failed.
+// SetActiveBenchRun marks run as executing for benchID, so that Benchmark's
+// ActiveBenchRuns field reflects it before the run finishes and is persisted
+// via RecordRuns. Runs of different registries/versions are tracked side by
+// side; setting a run that is already active replaces it. Callers should
+// keep it alive with HeartbeatActiveBenchRun while it executes and follow up
+// with RemActiveBenchRun once it completes, whether it succeeded or failed.
 func (c *Controller) SetActiveBenchRun(ctx context.Context, benchID string, run types.BenchRun) error {
 	exists, err := c.Redis.BenchmarkExists(ctx, benchID)
 	if err != nil {
@@ -143,10 +145,26 @@ func (c *Controller) SetActiveBenchRun(ctx context.Context, benchID string, run
 	return nil
 }
 
-// RemActiveBenchRun clears the run marked as currently executing for
-// benchID by SetActiveBenchRun. It succeeds without error when no run is
+// HeartbeatActiveBenchRun keeps the active run of registry/version on
+// benchID from expiring. It returns a not found error when the run is no
+// longer active, e.g. because it expired or was cleared.
+func (c *Controller) HeartbeatActiveBenchRun(ctx context.Context, benchID, registry string, version int64) error {
+	exists, err := c.Redis.BenchmarkExists(ctx, benchID)
+	if err != nil {
+		return fmt.Errorf("%w: checking if benchmark is present failed: %w", types.ErrInternal, err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: benchmark does not exist", types.ErrNotFound)
+	}
+
+	return c.Redis.HeartbeatActiveBenchRun(ctx, benchID, registry, version) //nolint: wrapcheck
+}
+
+// RemActiveBenchRun clears the active run of registry/version on benchID set
+// by SetActiveBenchRun. It succeeds without error when that run is not
 // active, so it is safe to call unconditionally once a run finishes.
-func (c *Controller) RemActiveBenchRun(ctx context.Context, benchID string) error {
+func (c *Controller) RemActiveBenchRun(ctx context.Context, benchID, registry string, version int64) error {
 	exists, err := c.Redis.BenchmarkExists(ctx, benchID)
 	if err != nil {
 		return fmt.Errorf("%w: checking if benchmark is present failed: %w", types.ErrInternal, err)
@@ -156,13 +174,57 @@ func (c *Controller) RemActiveBenchRun(ctx context.Context, benchID string) erro
 		return fmt.Errorf("%w: benchmark does not exist", types.ErrNotFound)
 	}
 
-	if err := c.Redis.RemActiveBenchRun(ctx, benchID); err != nil {
+	if err := c.Redis.RemActiveBenchRun(ctx, benchID, registry, version); err != nil {
 		return fmt.Errorf("%w: could not remove active benchmark run: %w", types.ErrInternal, err)
 	}
 
 	return nil
 }
 
+// AppendBenchRunLogs appends container output lines to the log stream of
+// the benchmark run of registry/version on benchID.
+func (c *Controller) AppendBenchRunLogs(ctx context.Context, benchID, registry string, version int64,
+	logs ...types.BenchRunLog,
+) error {
+	exists, err := c.Redis.BenchmarkExists(ctx, benchID)
+	if err != nil {
+		return fmt.Errorf("%w: checking if benchmark is present failed: %w", types.ErrInternal, err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: benchmark does not exist", types.ErrNotFound)
+	}
+
+	if err := c.Redis.AppendBenchRunLogs(ctx, benchID, registry, version, logs...); err != nil {
+		return fmt.Errorf("%w: could not append benchmark run logs: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// BenchRunLogs pulls the log entries of the benchmark run of registry/version
+// on benchID written after the entry with id after, waiting up to block for
+// new entries when none are available yet.
+func (c *Controller) BenchRunLogs(ctx context.Context, benchID, registry string, version int64,
+	after string, block time.Duration,
+) ([]types.BenchRunLog, error) {
+	exists, err := c.Redis.BenchmarkExists(ctx, benchID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: checking if benchmark is present failed: %w", types.ErrInternal, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w: benchmark does not exist", types.ErrNotFound)
+	}
+
+	logs, err := c.Redis.BenchRunLogs(ctx, benchID, registry, version, after, block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not pull benchmark run logs: %w", types.ErrInternal, err)
+	}
+
+	return logs, nil
+}
+
 // Benchmarks pulls all known benchmark ids.
 func (c *Controller) Benchmarks(ctx context.Context) ([]string, error) {
 	benchs, err := c.Redis.Benchmarks(ctx)
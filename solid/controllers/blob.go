@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// storeArtifacts stores the content of the artifacts of a run in the object
+// store. Content already stored as a blob is shared instead of being
+// uploaded again, and uploaded content becomes the blob of its SHA-256.
+// Content of the content types of Compression is compressed on upload.
+func (c *Controller) storeArtifacts(ctx context.Context, runID string,
+	as []types.Artifact,
+) ([]types.SavedArtifact, error) {
+	saved := make([]types.SavedArtifact, 0, len(as))
+	toUpload := make([]types.Artifact, 0, len(as))
+
+	for _, a := range as {
+		digest := a.Digest()
+		if digest.SHA256 == "" {
+			toUpload = append(toUpload, a)
+
+			continue
+		}
+
+		b, err := c.Redis.AcquireBlob(ctx, c.Redis.ArtifactRef(runID, a.Name()),
+			types.Blob{SHA256: digest.SHA256, Key: "", Size: digest.Size})
+		if errors.Is(err, types.ErrNotFound) {
+			toUpload = append(toUpload, a)
+
+			continue
+		} else if err != nil {
+			return saved, err
+		}
+
+		saved = append(saved, types.SavedArtifact{
+			Name:        a.Name(),
+			ContentType: a.ContentType(),
+			S3Key:       b.Key,
+			Size:        digest.Size,
+			SHA256:      digest.SHA256,
+			MIMEType:    a.MIMEType(),
+			Encoding:    b.Encoding,
+		})
+	}
+
+	if len(toUpload) == 0 {
+		return saved, nil
+	}
+
+	for i, a := range toUpload {
+		compressed, cleanup, err := c.compressArtifact(a)
+		if err != nil {
+			return saved, err
+		}
+
+		defer cleanup()
+
+		toUpload[i] = compressed
+	}
+
+	uploaded, uploadErr := c.S3.UploadArtifacts(ctx, toUpload)
+
+	for _, a := range uploaded {
+		b, err := c.acquireArtifactBlob(ctx, runID, a)
+		if err != nil {
+			// the artifact keeps its own object, deleted with its run
+			uploadErr = errors.Join(uploadErr, err)
+		}
+
+		a.S3Key, a.Encoding = b.Key, b.Encoding
+		saved = append(saved, a)
+	}
+
+	return saved, uploadErr
+}
+
+// acquireArtifactBlob references the blob of the content of an artifact of
+// a run, just uploaded to a.S3Key, and returns the blob the content of the
+// artifact is stored as, see acquireBlob.
+func (c *Controller) acquireArtifactBlob(ctx context.Context, runID string, a types.SavedArtifact,
+) (types.Blob, error) {
+	b := types.Blob{SHA256: a.SHA256, Key: a.S3Key, Size: a.Size, Encoding: a.Encoding}
+
+	if a.SHA256 == "" {
+		return b, nil
+	}
+
+	return c.acquireBlob(ctx, c.Redis.ArtifactRef(runID, a.Name), b)
+}
+
+// acquireBlob references b, content just uploaded to b.Key, from ref and
+// returns the blob the content is stored as. When the content was stored as
+// a blob by another upload in the meantime, b.Key is queued for deletion and
+// the existing blob, possibly of another encoding, is returned.
+func (c *Controller) acquireBlob(ctx context.Context, ref string, b types.Blob) (types.Blob, error) {
+	acquired, err := c.Redis.AcquireBlob(ctx, ref, b)
+	if err != nil {
+		return b, err
+	}
+
+	if acquired.Key != b.Key {
+		if err := c.Redis.QueueArtifactDeletion(ctx, b.Key); err != nil {
+			c.Logger.Error().Err(err).Str("key", b.Key).Msg("could not queue duplicate artifact deletion")
+		}
+	}
+
+	return acquired, nil
+}
+
+// deduplicateUpload makes s a deduplicated upload session when the content
+// it declares is already stored as an artifact of its run, and reports
+// whether it did. Content stored by other runs only is uploaded, and shared
+// once its digest is verified: a client that merely knows the SHA-256 of
+// some content must not get a reference to it.
+func (c *Controller) deduplicateUpload(ctx context.Context, s *types.UploadSession) (bool, error) {
+	if s.SHA256 == "" {
+		return false, nil
+	}
+
+	ok, err := c.runHoldsContent(ctx, s.RunID, s.SHA256, s.Size)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	s.Deduplicated, s.Offset = true, s.Size
+
+	return true, c.Redis.CreateUploadSession(ctx, *s)
+}
+
+// runHoldsContent reports whether an artifact of a run holds the content of
+// size bytes with the SHA-256 sha256.
+func (c *Controller) runHoldsContent(ctx context.Context, runID, sha256 string, size int64) (bool, error) {
+	artifacts, err := c.Redis.Artifacts(ctx, runID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, a := range artifacts {
+		if a.SHA256 == sha256 && a.Size == size {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// completeDeduplicatedUpload saves the artifact of a deduplicated upload,
+// referencing the blob of its content.
+func (c *Controller) completeDeduplicatedUpload(ctx context.Context, s *types.UploadSession,
+) (*types.SavedArtifact, error) {
+	if err := c.uploadedArtifactIsNew(ctx, s); err != nil {
+		return nil, err
+	}
+
+	// the artifacts of the run holding the content may have been deleted
+	// since the upload started
+	ok, err := c.runHoldsContent(ctx, s.RunID, s.SHA256, s.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		err = types.NewInvalidInputErr(fmt.Sprintf("content of upload <%s> is no longer stored, upload it again", s.ID))
+
+		return nil, errors.Join(err, c.abortUpload(ctx, s))
+	}
+
+	b, err := c.Redis.AcquireBlob(ctx, c.Redis.ArtifactRef(s.RunID, s.Name),
+		types.Blob{SHA256: s.SHA256, Key: "", Size: s.Size})
+	if errors.Is(err, types.ErrNotFound) {
+		err = types.NewInvalidInputErr(fmt.Sprintf("content of upload <%s> is no longer stored, upload it again", s.ID))
+
+		return nil, errors.Join(err, c.abortUpload(ctx, s))
+	} else if err != nil {
+		return nil, err
+	}
+
+	a := types.SavedArtifact{
+		Name:        s.Name,
+		ContentType: s.ContentType,
+		S3Key:       b.Key,
+		Size:        s.Size,
+		SHA256:      s.SHA256,
+		MIMEType:    c.sniffObject(ctx, b, s.ContentType, s.Name),
+		Encoding:    b.Encoding,
+	}
+
+	err = c.Redis.SetArtifact(ctx, s.RunID, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, c.Redis.DeleteUploadSession(ctx, s.ID)
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/zeddo123/mlsolid/solid/s3"
@@ -17,6 +18,22 @@ type Controller struct {
 	Bus                *pubgo.Bus
 	Logger             zerolog.Logger
 	PublishBenchEvents bool
+	// ArtifactUploadTTL how long an artifact upload session lives without
+	// receiving content, DefaultArtifactUploadTTL when zero.
+	ArtifactUploadTTL time.Duration
+	// PresignedURLs enables artifact transfers through pre-signed object
+	// store urls, see ArtifactDownloadURL and ArtifactUploadURL.
+	PresignedURLs bool
+	// PresignTTL how long pre-signed urls are valid for, DefaultPresignTTL
+	// when zero.
+	PresignTTL time.Duration
+	// Compression the encoding artifacts of each content type streamed
+	// through the server are compressed with, content types missing being
+	// stored as is.
+	Compression map[types.ContentType]types.ContentEncoding
+	// StorageQuota the limits on the artifact storage of runs and
+	// experiments, unlimited when zero.
+	StorageQuota types.StorageQuota
 }
 
 func (c *Controller) pushBengineEvent(ctx context.Context, registryName string, version int) {
@@ -59,17 +76,18 @@ func (c *Controller) pushBengineEvent(ctx context.Context, registryName string,
 			Msg("publishing benchmark event")
 
 		err = c.Bus.Publish("bengine", types.BenchEvent{
-			BenchID:     bench.ID,
-			BenchName:   bench.Name,
-			Registry:    registryName,
-			Version:     int64(version),
-			DockerImage: registry.BenchmarkImage,
-			ModelURL:    modelEntry.URL,
-			DatasetName: bench.DatasetName,
-			DatasetURL:  bench.DatasetURL,
-			FromS3:      bench.FromS3,
-			AutoTag:     bench.AutoTag,
-			Tag:         bench.Tag,
+			BenchID:       bench.ID,
+			BenchName:     bench.Name,
+			Registry:      registryName,
+			Version:       int64(version),
+			DockerImage:   registry.BenchmarkImage,
+			ModelURL:      modelEntry.URL,
+			ModelEncoding: modelEntry.Encoding,
+			DatasetName:   bench.DatasetName,
+			DatasetURL:    bench.DatasetURL,
+			FromS3:        bench.FromS3,
+			AutoTag:       bench.AutoTag,
+			Tag:           bench.Tag,
 		})
 		if err != nil {
 			c.Logger.Error().
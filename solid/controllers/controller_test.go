@@ -3,14 +3,20 @@
 package controllers_test
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	redisv9 "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/zeddo123/mlsolid/solid/controllers"
@@ -69,6 +75,530 @@ func TestRunFlow(t *testing.T) {
 	assert.Equal(t, "huge", savedRun.Metrics["model_size"].LastVal())
 }
 
+func TestRunLifecycle(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	t.Run("new_runs_are_running_until_finished", func(t *testing.T) {
+		run := types.NewRun("lifecycle-finished", "lifecycle-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+
+		saved, err := controller.Run(t.Context(), run.Name)
+		require.NoError(t, err)
+		assert.Equal(t, types.RunRunning, saved.Status)
+		assert.Zero(t, saved.EndTime)
+
+		finished, err := controller.FinishRun(t.Context(), run.Name, "")
+		require.NoError(t, err)
+		assert.Equal(t, types.RunFinished, finished.Status)
+		assert.False(t, finished.EndTime.IsZero())
+	})
+
+	t.Run("terminal_statuses_are_final", func(t *testing.T) {
+		run := types.NewRun("lifecycle-killed", "lifecycle-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+
+		_, err := controller.UpdateRunStatus(t.Context(), run.Name, types.RunKilled)
+		require.NoError(t, err)
+
+		_, err = controller.UpdateRunStatus(t.Context(), run.Name, types.RunRunning)
+		require.ErrorIs(t, err, types.ErrInvalidInput)
+	})
+
+	t.Run("finishing_with_a_non_terminal_status_is_rejected", func(t *testing.T) {
+		run := types.NewRun("lifecycle-bad-finish", "lifecycle-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+
+		_, err := controller.FinishRun(t.Context(), run.Name, types.RunRunning)
+		require.ErrorIs(t, err, types.ErrBadRequest)
+	})
+
+	t.Run("unknown_runs_return_not_found", func(t *testing.T) {
+		_, err := controller.FinishRun(t.Context(), "lifecycle-unknown", types.RunFailed)
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+
+	t.Run("stale_runs_are_reaped_as_failed", func(t *testing.T) {
+		run := types.NewRun("lifecycle-stale", "lifecycle-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+
+		// backdate the heartbeat instead of waiting for the timeout
+		lastSeen := time.Now().Add(-time.Hour)
+		err := client.ZAdd(t.Context(), store.RunningRunsKey,
+			redisv9.Z{Score: float64(lastSeen.Unix()), Member: run.Name}).Err()
+		require.NoError(t, err)
+
+		reaped, err := controller.ReapStaleRuns(t.Context(), 30*time.Minute)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, reaped, 1)
+
+		saved, err := controller.Run(t.Context(), run.Name)
+		require.NoError(t, err)
+		assert.Equal(t, types.RunFailed, saved.Status)
+		assert.WithinDuration(t, lastSeen, saved.EndTime, time.Second)
+	})
+
+	t.Run("logging_metrics_keeps_a_run_alive", func(t *testing.T) {
+		run := types.NewRun("lifecycle-alive", "lifecycle-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+
+		err := client.ZAdd(t.Context(), store.RunningRunsKey,
+			redisv9.Z{Score: float64(time.Now().Add(-time.Hour).Unix()), Member: run.Name}).Err()
+		require.NoError(t, err)
+
+		acc := types.NewGenericMetric[float64]("acc", 1)
+		acc.Add(0.5)
+		require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{acc}))
+
+		_, err = controller.ReapStaleRuns(t.Context(), 30*time.Minute)
+		require.NoError(t, err)
+
+		saved, err := controller.Run(t.Context(), run.Name)
+		require.NoError(t, err)
+		assert.Equal(t, types.RunRunning, saved.Status)
+	})
+}
+
+func TestMetricValMeta(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	run := types.NewRun("val-meta-run", "val-meta-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	step := int64(7)
+	at := time.Date(2025, 5, 8, 14, 30, 0, 0, time.UTC)
+
+	loss := types.NewGenericMetric[float64]("loss", 2)
+	loss.Add(0.9)
+	loss.AddWithMeta(0.4, types.ValMeta{Step: &step, Timestamp: &at})
+
+	require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{loss}))
+
+	saved, err := controller.Run(t.Context(), run.Name)
+	require.NoError(t, err)
+
+	metas := saved.Metrics["loss"].Metas()
+	require.Len(t, metas, 2)
+	assert.Nil(t, metas[0].Step)
+	assert.Nil(t, metas[0].Timestamp)
+	require.NotNil(t, metas[1].Step)
+	assert.Equal(t, step, *metas[1].Step)
+	require.NotNil(t, metas[1].Timestamp)
+	assert.True(t, at.Equal(*metas[1].Timestamp))
+}
+
+func TestRunParams(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	newParam := func(v any) types.Param {
+		p, err := types.NewParam(v)
+		require.NoError(t, err)
+
+		return p
+	}
+
+	adam := types.NewRun("params-adam", "params-exp")
+	sgd := types.NewRun("params-sgd", "params-exp")
+
+	require.NoError(t, controller.CreateRun(t.Context(), adam))
+	require.NoError(t, controller.CreateRun(t.Context(), sgd))
+
+	err := controller.LogParams(t.Context(), adam.Name, map[string]types.Param{
+		"optimizer": newParam("adam"), "lr": newParam(0.01), "batch size": newParam(32),
+	})
+	require.NoError(t, err)
+
+	err = controller.LogParams(t.Context(), sgd.Name, map[string]types.Param{
+		"optimizer": newParam("sgd"), "lr": newParam(0.01),
+	})
+	require.NoError(t, err)
+
+	t.Run("params_are_returned_with_the_run", func(t *testing.T) {
+		saved, err := controller.Run(t.Context(), adam.Name)
+		require.NoError(t, err)
+
+		assert.Equal(t, newParam("adam"), saved.Params["optimizer"])
+		assert.Equal(t, newParam(0.01), saved.Params["lr"])
+		assert.Equal(t, newParam(int64(32)), saved.Params["batch-size"])
+		assert.Empty(t, saved.Metrics)
+	})
+
+	t.Run("params_are_immutable", func(t *testing.T) {
+		err := controller.LogParams(t.Context(), adam.Name, map[string]types.Param{"lr": newParam(0.01)})
+		require.NoError(t, err)
+
+		err = controller.LogParams(t.Context(), adam.Name, map[string]types.Param{"lr": newParam(0.1)})
+		require.ErrorIs(t, err, types.ErrAlreadyInUse)
+		require.NotErrorIs(t, err, types.ErrInvalidInput)
+	})
+
+	t.Run("runs_are_filtered_by_params", func(t *testing.T) {
+		ids, err := controller.ExpRunsMatching(t.Context(), "params-exp",
+			types.RunFilter{Params: map[string]string{"lr": "0.01"}}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{adam.Name, sgd.Name}, ids)
+
+		ids, err = controller.ExpRunsMatching(t.Context(), "params-exp",
+			types.RunFilter{Params: map[string]string{"optimizer": "sgd"}}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.Equal(t, []string{sgd.Name}, ids)
+	})
+
+	t.Run("params_of_unknown_runs_return_not_found", func(t *testing.T) {
+		err := controller.LogParams(t.Context(), "params-unknown", map[string]types.Param{"lr": newParam(0.1)})
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestRunTagsAndNote(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	baseline := types.NewRun("tags-baseline", "tags-exp")
+	candidate := types.NewRun("tags-candidate", "tags-exp")
+
+	require.NoError(t, controller.CreateRun(t.Context(), baseline))
+	require.NoError(t, controller.CreateRun(t.Context(), candidate))
+
+	tags, err := controller.SetRunTags(t.Context(), baseline.Name,
+		map[string]string{"Baseline": "true", "dataset": "v1"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"baseline": "true", "dataset": "v1"}, tags)
+
+	_, err = controller.SetRunTags(t.Context(), candidate.Name,
+		map[string]string{"baseline": "false", "dataset": "v1"}, nil)
+	require.NoError(t, err)
+
+	t.Run("runs_are_filtered_by_tags", func(t *testing.T) {
+		ids, err := controller.ExpRunsMatching(t.Context(), "tags-exp",
+			types.RunFilter{Tags: map[string]string{"baseline": "true"}}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.Equal(t, []string{baseline.Name}, ids)
+
+		ids, err = controller.ExpRunsMatching(t.Context(), "tags-exp",
+			types.RunFilter{Tags: map[string]string{"dataset": "v1"}}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{baseline.Name, candidate.Name}, ids)
+	})
+
+	t.Run("tags_can_be_overwritten_and_removed", func(t *testing.T) {
+		tags, err := controller.SetRunTags(t.Context(), candidate.Name,
+			map[string]string{"baseline": "true"}, []string{"dataset"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"baseline": "true"}, tags)
+
+		ids, err := controller.ExpRunsMatching(t.Context(), "tags-exp",
+			types.RunFilter{Tags: map[string]string{"dataset": "v1"}}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.Equal(t, []string{baseline.Name}, ids)
+
+		ids, err = controller.ExpRunsMatching(t.Context(), "tags-exp",
+			types.RunFilter{Tags: map[string]string{"baseline": "false"}}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("note_is_returned_with_the_run", func(t *testing.T) {
+		require.NoError(t, controller.SetRunNote(t.Context(), baseline.Name, "# Baseline\nreference run"))
+
+		saved, err := controller.Run(t.Context(), baseline.Name)
+		require.NoError(t, err)
+		assert.Equal(t, "# Baseline\nreference run", saved.Note)
+		assert.Equal(t, "true", saved.Tags["baseline"])
+	})
+
+	t.Run("invalid_tags_are_rejected", func(t *testing.T) {
+		_, err := controller.SetRunTags(t.Context(), baseline.Name, map[string]string{"a:b": "c"}, nil)
+		require.ErrorIs(t, err, types.ErrBadRequest)
+	})
+
+	t.Run("tags_of_unknown_runs_return_not_found", func(t *testing.T) {
+		_, err := controller.SetRunTags(t.Context(), "tags-unknown", map[string]string{"a": "b"}, nil)
+		require.ErrorIs(t, err, types.ErrNotFound)
+
+		err = controller.SetRunNote(t.Context(), "tags-unknown", "note")
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestSearchRuns(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	lossOf := func(vals ...float64) types.Metric {
+		m := types.NewGenericMetric[float64]("val_loss", len(vals))
+		for _, v := range vals {
+			m.Add(v)
+		}
+
+		return m
+	}
+
+	type seed struct {
+		run  string
+		exp  string
+		lr   float64
+		tags map[string]string
+		loss []float64
+	}
+
+	seeds := []seed{
+		{"search-a", "search-exp-1", 0.001, map[string]string{"baseline": "true"}, []float64{0.9, 0.1, 0.15}},
+		{"search-b", "search-exp-1", 0.01, nil, []float64{0.8, 0.3}},
+		{"search-c", "search-exp-2", 0.001, nil, []float64{0.5, 0.05}},
+		{"search-d", "search-exp-2", 0.001, nil, nil},
+	}
+
+	for _, s := range seeds {
+		require.NoError(t, controller.CreateRun(t.Context(), types.NewRun(s.run, s.exp)))
+
+		lr, err := types.NewParam(s.lr)
+		require.NoError(t, err)
+		require.NoError(t, controller.LogParams(t.Context(), s.run, map[string]types.Param{"lr": lr}))
+
+		if s.tags != nil {
+			_, err = controller.SetRunTags(t.Context(), s.run, s.tags, nil)
+			require.NoError(t, err)
+		}
+
+		if s.loss != nil {
+			require.NoError(t, controller.AddMetrics(t.Context(), s.run, []types.Metric{lossOf(s.loss...)}))
+		}
+	}
+
+	exps := []string{"search-exp-1", "search-exp-2"}
+
+	search := func(t *testing.T, filter, order string, cursor uint64, limit int64) ([]string, uint64) {
+		t.Helper()
+
+		q, err := types.NewRunQuery(exps, filter, order, cursor, limit)
+		require.NoError(t, err)
+
+		runs, next, err := controller.SearchRuns(t.Context(), q)
+		require.NoError(t, err)
+
+		ids := make([]string, len(runs))
+		for i, r := range runs {
+			ids[i] = r.Name
+		}
+
+		return ids, next
+	}
+
+	t.Run("filters_on_last_metric_value_and_params", func(t *testing.T) {
+		ids, _ := search(t, "metrics.val_loss < 0.2 AND params.lr = 0.001", "metrics.val_loss ASC", 0, 10)
+		assert.Equal(t, []string{"search-c", "search-a"}, ids)
+	})
+
+	t.Run("filters_on_metric_extremes", func(t *testing.T) {
+		ids, _ := search(t, "min(metrics.val_loss) <= 0.1", "", 0, 10)
+		assert.ElementsMatch(t, []string{"search-a", "search-c"}, ids)
+
+		ids, _ = search(t, "max(metrics.val_loss) >= 0.85", "", 0, 10)
+		assert.Equal(t, []string{"search-a"}, ids)
+	})
+
+	t.Run("filters_on_tags_and_status", func(t *testing.T) {
+		ids, _ := search(t, "tags.baseline = true AND status = running", "", 0, 10)
+		assert.Equal(t, []string{"search-a"}, ids)
+
+		ids, _ = search(t, "tags.baseline != true", "", 0, 10)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("runs_without_the_order_metric_come_last", func(t *testing.T) {
+		ids, _ := search(t, "", "metrics.val_loss DESC", 0, 10)
+		assert.Equal(t, []string{"search-b", "search-a", "search-c", "search-d"}, ids)
+	})
+
+	t.Run("paginates_with_a_cursor", func(t *testing.T) {
+		first, next := search(t, "", "max(metrics.val_loss) ASC", 0, 3)
+		assert.Equal(t, []string{"search-c", "search-b", "search-a"}, first)
+		assert.Equal(t, uint64(3), next)
+
+		second, next := search(t, "", "max(metrics.val_loss) ASC", next, 3)
+		assert.Equal(t, []string{"search-d"}, second)
+		assert.Zero(t, next)
+	})
+}
+
+func TestNestedRuns(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	sweep := types.NewRun("nested-sweep", "nested-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), sweep))
+
+	for i, loss := range []float64{0.5, 0.2, 0.3} {
+		trial := types.NewRun(fmt.Sprintf("nested-trial-%d", i), "")
+		trial.ParentID = sweep.Name
+
+		require.NoError(t, controller.CreateRun(t.Context(), trial))
+
+		m := types.NewGenericMetric[float64]("loss", 1)
+		m.Add(loss)
+		require.NoError(t, controller.AddMetrics(t.Context(), trial.Name, []types.Metric{m}))
+	}
+
+	t.Run("children_inherit_the_parent_experiment", func(t *testing.T) {
+		ids, err := controller.ExpRuns(t.Context(), "nested-exp")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"nested-sweep", "nested-trial-0", "nested-trial-1", "nested-trial-2"}, ids)
+	})
+
+	t.Run("parent_and_children_are_linked", func(t *testing.T) {
+		parent, err := controller.Run(t.Context(), sweep.Name)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"nested-trial-0", "nested-trial-1", "nested-trial-2"}, parent.Children)
+		assert.Empty(t, parent.ParentID)
+
+		child, err := controller.Run(t.Context(), "nested-trial-1")
+		require.NoError(t, err)
+		assert.Equal(t, sweep.Name, child.ParentID)
+	})
+
+	t.Run("child_metrics_are_aggregated", func(t *testing.T) {
+		children, err := controller.ChildRuns(t.Context(), sweep.Name)
+		require.NoError(t, err)
+		require.Len(t, children, 3)
+
+		loss := types.AggregateFinalMetrics(children)["loss"]
+		assert.Equal(t, 3, loss.Count)
+		assert.Equal(t, "nested-trial-1", loss.MinRun)
+		assert.Equal(t, "nested-trial-0", loss.MaxRun)
+	})
+
+	t.Run("parent_must_exist_and_share_the_experiment", func(t *testing.T) {
+		orphan := types.NewRun("nested-orphan", "nested-exp")
+		orphan.ParentID = "nested-unknown"
+		require.ErrorIs(t, controller.CreateRun(t.Context(), orphan), types.ErrNotFound)
+
+		stray := types.NewRun("nested-stray", "another-exp")
+		stray.ParentID = sweep.Name
+		require.ErrorIs(t, controller.CreateRun(t.Context(), stray), types.ErrBadRequest)
+	})
+
+	t.Run("children_of_unknown_runs_return_not_found", func(t *testing.T) {
+		_, err := controller.ChildRuns(t.Context(), "nested-unknown")
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestDeleteAndArchiveRuns(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: objectStore}
+
+	parent := types.NewRun("lifecycle-parent", "lifecycle-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), parent))
+
+	child := types.NewRun("lifecycle-child", "")
+	child.ParentID = parent.Name
+	require.NoError(t, controller.CreateRun(t.Context(), child))
+
+	m := types.NewGenericMetric[float64]("loss", 1)
+	m.Add(0.4)
+	require.NoError(t, controller.AddMetrics(t.Context(), child.Name, []types.Metric{m}))
+
+	registered := types.NewRun("lifecycle-registered", "lifecycle-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), registered))
+
+	artifact := types.CheckpointArtifact{Model: "model.pt", Checkpoint: bytes.NewReader([]byte{1, 2, 3})}
+	require.NoError(t, controller.AddArtifacts(t.Context(), registered.Name, []types.Artifact{artifact}))
+
+	ops := types.RegistryBenchmarkOps{BenchmarkImage: "", BenchmarkGpuPassthrough: false}
+	require.NoError(t, controller.CreateModelRegistry(t.Context(), "lifecycle-registry", ops))
+	require.NoError(t, controller.AddArtifactToRegistry(t.Context(), "lifecycle-registry", registered.Name, "model.pt"))
+
+	t.Run("archived_runs_are_hidden_from_listings", func(t *testing.T) {
+		require.NoError(t, controller.ArchiveRun(t.Context(), registered.Name))
+
+		ids, err := controller.ExpRuns(t.Context(), "lifecycle-exp")
+		require.NoError(t, err)
+		assert.NotContains(t, ids, registered.Name)
+
+		run, err := controller.Run(t.Context(), registered.Name)
+		require.NoError(t, err)
+		assert.True(t, run.Archived)
+
+		require.NoError(t, controller.RestoreRun(t.Context(), registered.Name))
+
+		ids, err = controller.ExpRuns(t.Context(), "lifecycle-exp")
+		require.NoError(t, err)
+		assert.Contains(t, ids, registered.Name)
+	})
+
+	t.Run("registered_artifacts_block_deletion", func(t *testing.T) {
+		_, err := controller.DeleteRun(t.Context(), registered.Name)
+		require.ErrorIs(t, err, types.ErrAlreadyInUse)
+
+		ok, err := controller.Redis.RunExists(t.Context(), registered.Name)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("deleting_a_run_deletes_its_children", func(t *testing.T) {
+		ids, err := controller.DeleteRun(t.Context(), parent.Name)
+		require.NoError(t, err)
+		assert.Equal(t, []string{parent.Name, child.Name}, ids)
+
+		_, err = controller.Run(t.Context(), child.Name)
+		require.ErrorIs(t, err, types.ErrNotFound)
+
+		metrics, err := controller.Redis.RunMetrics(t.Context(), child.Name)
+		require.NoError(t, err)
+		assert.Empty(t, metrics)
+
+		ids, err = controller.ExpRuns(t.Context(), "lifecycle-exp")
+		require.NoError(t, err)
+		assert.Equal(t, []string{registered.Name}, ids)
+	})
+
+	t.Run("unknown_runs_return_not_found", func(t *testing.T) {
+		_, err := controller.DeleteRun(t.Context(), "lifecycle-unknown")
+		require.ErrorIs(t, err, types.ErrNotFound)
+		require.ErrorIs(t, controller.ArchiveRun(t.Context(), "lifecycle-unknown"), types.ErrNotFound)
+	})
+
+	t.Run("archived_experiments_are_hidden", func(t *testing.T) {
+		require.NoError(t, controller.ArchiveExperiment(t.Context(), "lifecycle-exp"))
+
+		exps, err := controller.Exps(t.Context())
+		require.NoError(t, err)
+		assert.NotContains(t, exps, "lifecycle-exp")
+
+		require.NoError(t, controller.RestoreExperiment(t.Context(), "lifecycle-exp"))
+
+		exps, err = controller.Exps(t.Context())
+		require.NoError(t, err)
+		assert.Contains(t, exps, "lifecycle-exp")
+	})
+
+	t.Run("runs_created_in_archived_experiments_keep_them_archived", func(t *testing.T) {
+		require.NoError(t, controller.ArchiveExperiment(t.Context(), "lifecycle-exp"))
+
+		require.NoError(t, controller.CreateRun(t.Context(), types.NewRun("lifecycle-late", "lifecycle-exp")))
+
+		exps, err := controller.Exps(t.Context())
+		require.NoError(t, err)
+		assert.NotContains(t, exps, "lifecycle-exp")
+
+		archived, err := controller.Redis.ArchivedExps(t.Context())
+		require.NoError(t, err)
+		assert.Contains(t, archived, "lifecycle-exp")
+
+		require.NoError(t, controller.RestoreExperiment(t.Context(), "lifecycle-exp"))
+	})
+}
+
 func TestArtifact(t *testing.T) {
 	t.Run("content_of_an_artifact_is_saved_correctly", func(t *testing.T) {
 		t.Parallel()
@@ -181,6 +711,16 @@ func findRun(runs []*types.BenchRun, registry string, version int64) *types.Benc
 	return nil
 }
 
+// toPtrs adapts a slice of runs for findRun.
+func toPtrs(runs []types.BenchRun) []*types.BenchRun {
+	ptrs := make([]*types.BenchRun, len(runs))
+	for i := range runs {
+		ptrs[i] = &runs[i]
+	}
+
+	return ptrs
+}
+
 func TestRecordRuns(t *testing.T) {
 	t.Parallel()
 
@@ -373,46 +913,92 @@ func TestActiveBenchRun(t *testing.T) {
 
 		got, err := controller.Benchmark(t.Context(), benchID)
 		require.NoError(t, err)
-		assert.Equal(t, run.Registry, got.ActiveBenchRun.Registry)
-		assert.Equal(t, run.Version, got.ActiveBenchRun.Version)
+
+		active := findRun(toPtrs(got.ActiveBenchRuns), run.Registry, run.Version)
+		require.NotNil(t, active)
 
 		// A self-expiring TTL bounds how long a stuck active run can survive
 		// a RemActiveBenchRun that never succeeds (crash, sustained outage).
 		ttl, err := client.HExpireTime(t.Context(),
-			fmt.Sprintf(store.BenchmarkKeyPattern, benchID), "ActiveBenchRun").Result()
+			fmt.Sprintf(store.BenchmarkActiveRunsKeyPattern, benchID), "dummy-registry:1").Result()
 		require.NoError(t, err)
 		require.Len(t, ttl, 1)
-		assert.Greater(t, ttl[0], time.Now().Add(4*24*time.Hour).Unix())
+		assert.Greater(t, ttl[0], time.Now().Unix())
 	})
 
-	t.Run("setting_a_new_active_run_replaces_the_previous_one", func(t *testing.T) {
-		err := controller.SetActiveBenchRun(t.Context(), benchID, types.BenchRun{ //nolint: exhaustruct
-			Registry: "dummy-registry", Version: 1,
-		})
-		require.NoError(t, err)
-
-		err = controller.SetActiveBenchRun(t.Context(), benchID, types.BenchRun{ //nolint: exhaustruct
-			Registry: "dummy-registry", Version: 2,
-		})
-		require.NoError(t, err)
+	t.Run("concurrent_runs_are_all_active", func(t *testing.T) {
+		for _, version := range []int64{10, 11} {
+			err := controller.SetActiveBenchRun(t.Context(), benchID, types.BenchRun{ //nolint: exhaustruct
+				Registry: "dummy-registry", Version: version, Start: time.Now(),
+			})
+			require.NoError(t, err)
+		}
 
 		got, err := controller.Benchmark(t.Context(), benchID)
 		require.NoError(t, err)
-		assert.Equal(t, int64(2), got.ActiveBenchRun.Version)
-	})
 
-	t.Run("removing_the_active_run_clears_it_from_the_benchmark", func(t *testing.T) {
-		err := controller.SetActiveBenchRun(t.Context(), benchID, types.BenchRun{ //nolint: exhaustruct
-			Registry: "dummy-registry", Version: 3,
-		})
-		require.NoError(t, err)
+		runs := toPtrs(got.ActiveBenchRuns)
+		assert.NotNil(t, findRun(runs, "dummy-registry", 10))
+		assert.NotNil(t, findRun(runs, "dummy-registry", 11))
+	})
 
-		err = controller.RemActiveBenchRun(t.Context(), benchID)
-		require.NoError(t, err)
+	t.Run("setting_the_same_run_again_replaces_it", func(t *testing.T) {
+		for _, score := range []float32{1, 2} {
+			err := controller.SetActiveBenchRun(t.Context(), benchID, types.BenchRun{ //nolint: exhaustruct
+				Registry: "dummy-registry", Version: 20, Metrics: map[string]float32{"mae": score},
+			})
+			require.NoError(t, err)
+		}
 
 		got, err := controller.Benchmark(t.Context(), benchID)
 		require.NoError(t, err)
-		assert.Zero(t, got.ActiveBenchRun)
+
+		count := 0
+
+		for _, run := range got.ActiveBenchRuns {
+			if run.Registry == "dummy-registry" && run.Version == 20 {
+				count++
+
+				assert.InDelta(t, 2.0, run.Metrics["mae"], 1e-6)
+			}
+		}
+
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("heartbeating_an_active_run_succeeds", func(t *testing.T) {
+		err := controller.SetActiveBenchRun(t.Context(), benchID, types.BenchRun{ //nolint: exhaustruct
+			Registry: "dummy-registry", Version: 30,
+		})
+		require.NoError(t, err)
+
+		err = controller.HeartbeatActiveBenchRun(t.Context(), benchID, "dummy-registry", 30)
+		require.NoError(t, err)
+	})
+
+	t.Run("heartbeating_an_inactive_run_returns_not_found", func(t *testing.T) {
+		err := controller.HeartbeatActiveBenchRun(t.Context(), benchID, "dummy-registry", 404)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, types.ErrNotFound))
+	})
+
+	t.Run("removing_an_active_run_only_clears_that_run", func(t *testing.T) {
+		for _, version := range []int64{40, 41} {
+			err := controller.SetActiveBenchRun(t.Context(), benchID, types.BenchRun{ //nolint: exhaustruct
+				Registry: "dummy-registry", Version: version,
+			})
+			require.NoError(t, err)
+		}
+
+		err := controller.RemActiveBenchRun(t.Context(), benchID, "dummy-registry", 40)
+		require.NoError(t, err)
+
+		got, err := controller.Benchmark(t.Context(), benchID)
+		require.NoError(t, err)
+
+		runs := toPtrs(got.ActiveBenchRuns)
+		assert.Nil(t, findRun(runs, "dummy-registry", 40))
+		assert.NotNil(t, findRun(runs, "dummy-registry", 41))
 	})
 
 	t.Run("removing_an_active_run_that_was_never_set_is_a_no-op", func(t *testing.T) {
@@ -427,8 +1013,12 @@ func TestActiveBenchRun(t *testing.T) {
 		require.NoError(t, err)
 		require.True(t, created)
 
-		err = controller.RemActiveBenchRun(t.Context(), noRunBenchID)
+		err = controller.RemActiveBenchRun(t.Context(), noRunBenchID, "dummy-registry", 1)
+		require.NoError(t, err)
+
+		got, err := controller.Benchmark(t.Context(), noRunBenchID)
 		require.NoError(t, err)
+		assert.Empty(t, got.ActiveBenchRuns)
 	})
 
 	t.Run("setting_an_active_run_for_an_unknown_benchmark_returns_not_found", func(t *testing.T) {
@@ -440,7 +1030,71 @@ func TestActiveBenchRun(t *testing.T) {
 	})
 
 	t.Run("removing_an_active_run_for_an_unknown_benchmark_returns_not_found", func(t *testing.T) {
-		err := controller.RemActiveBenchRun(t.Context(), "unknown-benchmark-id")
+		err := controller.RemActiveBenchRun(t.Context(), "unknown-benchmark-id", "dummy-registry", 1)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, types.ErrNotFound))
+	})
+}
+
+func TestBenchRunLogs(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: objectStore}
+
+	benchID, created, err := controller.CreateBenchmark(t.Context(), types.Bench{ //nolint: exhaustruct
+		Name:        "bench-run-logs-bench",
+		Registries:  []string{"dummy-registry"},
+		Metrics:     []types.BenchMetric{{Name: "mae"}},
+		DatasetName: "dummy-dataset",
+		DatasetURL:  "https://example.com/dataset.zip",
+		Timestamp:   time.Now(),
+	})
+	require.NoError(t, err)
+	require.True(t, created)
+
+	t.Run("appended_logs_are_read_back_in_order_with_progress", func(t *testing.T) {
+		end := types.NewBenchRunLog("")
+		end.End = true
+
+		err := controller.AppendBenchRunLogs(t.Context(), benchID, "dummy-registry", 1,
+			types.NewBenchRunLog("loading dataset"),
+			types.NewBenchRunLog("MLSOLID_PROGRESS 42/100"),
+			end,
+		)
+		require.NoError(t, err)
+
+		logs, err := controller.BenchRunLogs(t.Context(), benchID, "dummy-registry", 1, "", -1)
+		require.NoError(t, err)
+		require.Len(t, logs, 3)
+
+		assert.Equal(t, "loading dataset", logs[0].Line)
+		assert.Nil(t, logs[0].Progress)
+		require.NotNil(t, logs[1].Progress)
+		assert.Equal(t, types.BenchProgress{Done: 42, Total: 100}, *logs[1].Progress)
+		assert.True(t, logs[2].End)
+
+		resumed, err := controller.BenchRunLogs(t.Context(), benchID, "dummy-registry", 1, logs[0].ID, -1)
+		require.NoError(t, err)
+		assert.Len(t, resumed, 2)
+	})
+
+	t.Run("setting_the_active_run_resets_its_logs", func(t *testing.T) {
+		err := controller.AppendBenchRunLogs(t.Context(), benchID, "dummy-registry", 2,
+			types.NewBenchRunLog("stale line"))
+		require.NoError(t, err)
+
+		err = controller.SetActiveBenchRun(t.Context(), benchID, types.BenchRun{ //nolint: exhaustruct
+			Registry: "dummy-registry", Version: 2,
+		})
+		require.NoError(t, err)
+
+		logs, err := controller.BenchRunLogs(t.Context(), benchID, "dummy-registry", 2, "", -1)
+		require.NoError(t, err)
+		assert.Empty(t, logs)
+	})
+
+	t.Run("logs_of_an_unknown_benchmark_return_not_found", func(t *testing.T) {
+		_, err := controller.BenchRunLogs(t.Context(), "unknown-benchmark-id", "dummy-registry", 1, "", -1)
 		require.Error(t, err)
 		assert.True(t, errors.Is(err, types.ErrNotFound))
 	})
@@ -475,3 +1129,1228 @@ func TestExpInfo(t *testing.T) {
 		assert.Equal(t, desc, info.Description)
 	})
 }
+
+func TestCreateExperiment(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	err := controller.CreateExperiment(t.Context(), "Created Exp", types.ExperimentInfo{ //nolint: exhaustruct
+		Description: "created before its first run",
+		Owner:       "alice",
+		Tags:        map[string]string{"Team": "vision"},
+	})
+	require.NoError(t, err)
+
+	t.Run("created_experiments_are_listed_without_runs", func(t *testing.T) {
+		exps, err := controller.Exps(t.Context())
+		require.NoError(t, err)
+		assert.Contains(t, exps, "created-exp")
+
+		info, err := controller.ExpInfo(t.Context(), "created-exp")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", info.Owner)
+		assert.Equal(t, map[string]string{"team": "vision"}, info.Tags)
+		assert.False(t, info.CreatedAt.IsZero())
+
+		ids, err := controller.ExpRuns(t.Context(), "created-exp")
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("experiments_cannot_be_created_twice", func(t *testing.T) {
+		err := controller.CreateExperiment(t.Context(), "created-exp", types.ExperimentInfo{}) //nolint: exhaustruct
+		require.ErrorIs(t, err, types.ErrAlreadyInUse)
+		require.NotErrorIs(t, err, types.ErrInvalidInput)
+	})
+
+	t.Run("runs_can_be_added_to_created_experiments", func(t *testing.T) {
+		require.NoError(t, controller.CreateRun(t.Context(), types.NewRun("created-exp-run", "created-exp")))
+
+		ids, err := controller.ExpRuns(t.Context(), "created-exp")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"created-exp-run"}, ids)
+	})
+
+	t.Run("update_only_changes_set_fields", func(t *testing.T) {
+		name := "Created experiment"
+
+		info, err := controller.UpdateExperiment(t.Context(), "created-exp", types.UpdateExperiment{ //nolint: exhaustruct
+			Name:       &name,
+			Tags:       map[string]string{"stage": "dev"},
+			RemoveTags: []string{"team"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, name, info.Name)
+		assert.Equal(t, "alice", info.Owner)
+		assert.Equal(t, "created before its first run", info.Description)
+		assert.Equal(t, map[string]string{"stage": "dev"}, info.Tags)
+	})
+
+	t.Run("unknown_experiments_return_not_found", func(t *testing.T) {
+		_, err := controller.UpdateExperiment(t.Context(), "created-unknown", types.UpdateExperiment{}) //nolint: exhaustruct
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestMetricRangeQueries(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	run := types.NewRun("range-run", "range-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	m := types.NewGenericMetric[float64]("loss", 100)
+	for i := range 100 {
+		step := int64(i)
+		m.AddWithMeta(1/float64(i+2), types.ValMeta{Step: &step}) //nolint: exhaustruct
+	}
+
+	require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{m}))
+
+	t.Run("values_are_downsampled", func(t *testing.T) {
+		ms, err := controller.RunsMetric(t.Context(), []string{run.Name}, "loss",
+			types.MetricQuery{MaxPoints: 10, Downsample: types.DownsampleLTTB}) //nolint: exhaustruct
+		require.NoError(t, err)
+		require.Contains(t, ms, run.Name)
+
+		vals := ms[run.Name].Vals()
+		assert.Len(t, vals, 10)
+		assert.InDelta(t, 0.5, vals[0], 1e-9)
+		assert.InDelta(t, 1/101.0, vals[9], 1e-9)
+	})
+
+	t.Run("values_are_windowed_by_step", func(t *testing.T) {
+		start, end := int64(10), int64(19)
+
+		ms, err := controller.ExpMetric(t.Context(), "range-exp", "loss",
+			types.MetricQuery{StartStep: &start, EndStep: &end}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.Len(t, ms[run.Name].Vals(), 10)
+	})
+
+	t.Run("last_value_only", func(t *testing.T) {
+		ms, err := controller.RunsMetric(t.Context(), []string{run.Name}, "loss",
+			types.MetricQuery{LastOnly: true}) //nolint: exhaustruct
+		require.NoError(t, err)
+		require.Len(t, ms[run.Name].Vals(), 1)
+		assert.InDelta(t, 1/101.0, ms[run.Name].Vals()[0], 1e-9)
+		assert.Equal(t, int64(99), *ms[run.Name].Metas()[0].Step)
+	})
+
+	t.Run("future_time_window_is_empty", func(t *testing.T) {
+		since := time.Now().Add(time.Hour)
+
+		ms, err := controller.RunsMetric(t.Context(), []string{run.Name}, "loss",
+			types.MetricQuery{Since: &since}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.Empty(t, ms)
+	})
+
+	t.Run("malformed_queries_are_rejected", func(t *testing.T) {
+		_, err := controller.RunsMetric(t.Context(), []string{run.Name}, "loss",
+			types.MetricQuery{MaxPoints: -1}) //nolint: exhaustruct
+		require.ErrorIs(t, err, types.ErrBadRequest)
+	})
+}
+
+func TestRunMetricEvents(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	run := types.NewRun("watch-run", "watch-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	loss := types.NewGenericMetric[float64]("loss", 1)
+	loss.Add(0.5)
+
+	require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{loss}))
+
+	cursor := types.NewMetricCursor("")
+
+	events, done, err := controller.RunMetricEvents(t.Context(), run.Name, nil, cursor, -1)
+	require.NoError(t, err)
+	assert.False(t, done)
+	require.Len(t, events, 1)
+	assert.Equal(t, "loss", events[0].Metric)
+	assert.InDelta(t, 0.5, events[0].Value, 1e-9)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+
+		loss := types.NewGenericMetric[float64]("loss", 1)
+		loss.Add(0.25)
+
+		_ = controller.AddMetrics(context.Background(), run.Name, []types.Metric{loss})
+	}()
+
+	events, done, err = controller.RunMetricEvents(t.Context(), run.Name, []string{"loss"}, cursor, 5*time.Second)
+	require.NoError(t, err)
+	assert.False(t, done)
+	require.Len(t, events, 1)
+	assert.InDelta(t, 0.25, events[0].Value, 1e-9)
+
+	_, err = controller.FinishRun(t.Context(), run.Name, types.RunFinished)
+	require.NoError(t, err)
+
+	events, done, err = controller.RunMetricEvents(t.Context(), run.Name, nil, cursor, 5*time.Second)
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Empty(t, events)
+
+	_, _, err = controller.RunMetricEvents(t.Context(), "watch-missing-run", nil, cursor, -1)
+	require.ErrorIs(t, err, types.ErrNotFound)
+}
+
+func TestMetricSummaries(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	run := types.NewRun("summary-run", "summary-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	step := int64(0)
+
+	for _, batch := range [][]float64{{0.9, 0.4}, {0.2, 0.5}} {
+		m := types.NewGenericMetric[float64]("val_loss", len(batch))
+
+		for _, v := range batch {
+			at := step
+			m.AddWithMeta(v, types.ValMeta{Step: &at}) //nolint: exhaustruct
+			step++
+		}
+
+		require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{m}))
+	}
+
+	notes := types.NewGenericMetric[string]("notes", 1)
+	notes.Add("converging")
+	require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{notes}))
+
+	r, err := controller.Run(t.Context(), run.Name)
+	require.NoError(t, err)
+
+	require.Contains(t, r.Summaries, "val_loss")
+	assert.NotContains(t, r.Summaries, "notes")
+
+	s := r.Summaries["val_loss"]
+	assert.Equal(t, int64(4), s.Count)
+	assert.InDelta(t, 0.2, s.Min, 1e-9)
+	assert.Equal(t, int64(2), *s.MinStep)
+	assert.InDelta(t, 0.9, s.Max, 1e-9)
+	assert.Equal(t, int64(0), *s.MaxStep)
+	assert.InDelta(t, 0.5, s.Last, 1e-9)
+	assert.InDelta(t, 0.5, s.Mean(), 1e-9)
+}
+
+func TestBackfillMetricSummaries(t *testing.T) {
+	// not parallel: the backfill summarizes every run without summaries,
+	// which would count the values other tests log meanwhile twice
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	run := types.NewRun("summary-backfill-run", "summary-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	acc := types.NewGenericMetric[float64]("acc", 3)
+	acc.Add(0.5)
+	acc.Add(0.75)
+	acc.Add(0.7)
+	require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{acc}))
+
+	// runs logged before summaries were maintained have none
+	require.NoError(t, client.Del(t.Context(), fmt.Sprintf(store.RunMetricSummaryKeyPattern, run.Name)).Err())
+
+	for range 2 {
+		require.NoError(t, controller.Redis.BackfillMetricSummaries(t.Context()))
+
+		r, err := controller.Run(t.Context(), run.Name)
+		require.NoError(t, err)
+
+		require.Contains(t, r.Summaries, "acc")
+		assert.Equal(t, int64(3), r.Summaries["acc"].Count)
+		assert.InDelta(t, 0.75, r.Summaries["acc"].Max, 1e-9)
+		assert.InDelta(t, 0.7, r.Summaries["acc"].Last, 1e-9)
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	for i, exp := range []string{"compare-exp-a", "compare-exp-b"} {
+		run := types.NewRun(fmt.Sprintf("compare-run-%d", i), exp)
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+
+		lr, err := types.NewParam(0.01 * float64(i+1))
+		require.NoError(t, err)
+
+		optim, err := types.NewParam("adam")
+		require.NoError(t, err)
+
+		require.NoError(t, controller.LogParams(t.Context(), run.Name, map[string]types.Param{"lr": lr, "optimizer": optim}))
+
+		m := types.NewGenericMetric[float64]("val_acc", 3)
+		for j := range 3 {
+			step := int64((i + 1) * j)
+			m.AddWithMeta(1/float64(j+i+2), types.ValMeta{Step: &step}) //nolint: exhaustruct
+		}
+
+		require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{m}))
+	}
+
+	c, err := controller.CompareRuns(t.Context(), []string{"compare-run-0", "compare-run-1"}, nil,
+		types.MetricQuery{}) //nolint: exhaustruct
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"compare-run-0", "compare-run-1"}, c.RunIDs)
+	assert.Equal(t, "compare-exp-b", c.Experiments["compare-run-1"])
+	assert.True(t, c.Params["lr"].Differs)
+	assert.False(t, c.Params["optimizer"].Differs)
+	assert.Equal(t, int64(3), c.Summaries["compare-run-0"]["val_acc"].Count)
+
+	require.Contains(t, c.Curves, "val_acc")
+	assert.Equal(t, []int64{0, 1, 2, 4}, c.Curves["val_acc"].Steps)
+	assert.Nil(t, c.Curves["val_acc"].Values["compare-run-0"][3])
+	assert.Nil(t, c.Curves["val_acc"].Values["compare-run-1"][1])
+
+	_, err = controller.CompareRuns(t.Context(), []string{"compare-run-0"}, nil, types.MetricQuery{}) //nolint: exhaustruct
+	require.ErrorIs(t, err, types.ErrBadRequest)
+
+	_, err = controller.CompareRuns(t.Context(), []string{"compare-run-0", "compare-missing"}, nil,
+		types.MetricQuery{}) //nolint: exhaustruct
+	require.ErrorIs(t, err, types.ErrNotFound)
+}
+
+func TestTypedMetricValues(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	run := types.NewRun("typed-run", "typed-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	loss := types.NewGenericMetric[float64]("loss", 2)
+	loss.Add(1.0)
+	loss.Add(0.5)
+
+	done := types.NewGenericMetric[bool]("converged", 1)
+	done.Add(true)
+
+	weights := types.NewGenericMetric[types.Histogram]("weights", 1)
+	weights.Add(types.Histogram{Edges: []float64{-1, 0, 1}, Counts: []float64{4, 6}})
+
+	perClass := types.NewGenericMetric[types.Vector]("per-class-acc", 1)
+	perClass.Add(types.Vector{0.9, 0.8})
+
+	require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{loss, done, weights, perClass}))
+
+	// int values are logged to float metrics as floats
+	more := types.NewGenericMetric[int64]("loss", 1)
+	more.Add(2)
+	require.NoError(t, controller.AddMetrics(t.Context(), run.Name, []types.Metric{more}))
+
+	r, err := controller.Run(t.Context(), run.Name)
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{1.0, 0.5, 2.0}, r.Metrics["loss"].Vals())
+	assert.Equal(t, []any{true}, r.Metrics["converged"].Vals())
+	assert.Equal(t, []any{types.Histogram{Edges: []float64{-1, 0, 1}, Counts: []float64{4, 6}}},
+		r.Metrics["weights"].Vals())
+	assert.Equal(t, []any{types.Vector{0.9, 0.8}}, r.Metrics["per-class-acc"].Vals())
+
+	t.Run("kind_cannot_change", func(t *testing.T) {
+		flag := types.NewGenericMetric[string]("converged", 1)
+		flag.Add("yes")
+
+		err := controller.AddMetrics(t.Context(), run.Name, []types.Metric{flag})
+		require.ErrorIs(t, err, types.ErrBadRequest)
+	})
+
+	t.Run("concurrent_first_writes_keep_a_single_kind", func(t *testing.T) {
+		errs := make(chan error, 2)
+
+		go func() {
+			m := types.NewGenericMetric[string]("status", 1)
+			m.Add("warming-up")
+			errs <- controller.AddMetrics(t.Context(), run.Name, []types.Metric{m})
+		}()
+
+		go func() {
+			m := types.NewGenericMetric[bool]("status", 1)
+			m.Add(true)
+			errs <- controller.AddMetrics(t.Context(), run.Name, []types.Metric{m})
+		}()
+
+		failed := 0
+
+		for range 2 {
+			if err := <-errs; err != nil {
+				require.ErrorIs(t, err, types.ErrBadRequest)
+
+				failed++
+			}
+		}
+
+		assert.Equal(t, 1, failed)
+
+		r, err := controller.Run(t.Context(), run.Name)
+		require.NoError(t, err)
+		assert.Len(t, r.Metrics["status"].Vals(), 1)
+	})
+
+	t.Run("malformed_histograms_are_rejected", func(t *testing.T) {
+		h := types.NewGenericMetric[types.Histogram]("grads", 1)
+		h.Add(types.Histogram{Edges: []float64{0}, Counts: []float64{1}})
+
+		err := controller.AddMetrics(t.Context(), run.Name, []types.Metric{h})
+		require.ErrorIs(t, err, types.ErrBadRequest)
+	})
+}
+
+func TestArtifactDigest(t *testing.T) {
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: objectStore}
+
+	run := types.NewRun("digest-run", "digest-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	content := []byte("checkpoint")
+	digester := types.NewDigester()
+	_, err := digester.Write(content)
+	require.NoError(t, err)
+
+	artifact, err := types.NewDigestedArtifact("model.pt", string(types.ModelContentType),
+		bytes.NewReader(content), digester.Digest())
+	require.NoError(t, err)
+	require.NoError(t, controller.AddArtifacts(t.Context(), run.Name, []types.Artifact{artifact}))
+
+	saved, body, err := controller.Artifact(t.Context(), run.Name, "model.pt")
+	require.NoError(t, err)
+
+	defer body.Close()
+
+	assert.Equal(t, int64(len(content)), saved.Size)
+	assert.Equal(t, digester.Digest().SHA256, saved.SHA256)
+
+	artifacts, err := controller.Redis.Artifacts(t.Context(), run.Name)
+	require.NoError(t, err)
+	assert.Equal(t, saved.SHA256, artifacts["model.pt"].SHA256)
+}
+
+func TestArtifactUpload(t *testing.T) {
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	run := types.NewRun("upload-run", "upload-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	content := bytes.Repeat([]byte{7}, controllers.ArtifactPartSize+10)
+	digester := types.NewDigester()
+	_, err := digester.Write(content)
+	require.NoError(t, err)
+
+	sha256 := digester.Digest().SHA256
+
+	t.Run("resumes_from_the_last_uploaded_part", func(t *testing.T) {
+		s, err := controller.InitArtifactUpload(t.Context(), run.Name, "model.pt", string(types.ModelContentType),
+			int64(len(content)), sha256)
+		require.NoError(t, err)
+
+		// the connection drops in the middle of the second part
+		s, err = controller.UploadArtifactParts(t.Context(), s.ID, 0, bytes.NewReader(content[:len(content)-5]))
+		require.NoError(t, err)
+		assert.Equal(t, int64(controllers.ArtifactPartSize), s.Offset)
+
+		_, err = controller.CompleteArtifactUpload(t.Context(), s.ID)
+		require.ErrorIs(t, err, types.ErrInvalidInput)
+
+		_, err = controller.UploadArtifactParts(t.Context(), s.ID, 0, bytes.NewReader(content))
+		require.ErrorIs(t, err, types.ErrInvalidInput)
+
+		s, err = controller.UploadArtifactParts(t.Context(), s.ID, s.Offset, bytes.NewReader(content[s.Offset:]))
+		require.NoError(t, err)
+		assert.True(t, s.Complete())
+
+		a, err := controller.CompleteArtifactUpload(t.Context(), s.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), a.Size)
+		assert.Equal(t, sha256, a.SHA256)
+
+		saved, err := controller.Redis.Artifact(t.Context(), run.Name, "model.pt")
+		require.NoError(t, err)
+		assert.Equal(t, *a, saved)
+
+		_, err = controller.ArtifactUpload(t.Context(), s.ID)
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+
+	t.Run("existing_artifacts_cannot_be_uploaded", func(t *testing.T) {
+		_, err := controller.InitArtifactUpload(t.Context(), run.Name, "model.pt", string(types.ModelContentType),
+			int64(len(content)), "")
+		require.ErrorIs(t, err, types.ErrAlreadyInUse)
+	})
+
+	t.Run("mismatching_content_is_rejected", func(t *testing.T) {
+		s, err := controller.InitArtifactUpload(t.Context(), run.Name, "other.pt", string(types.ModelContentType),
+			3, sha256)
+		require.NoError(t, err)
+
+		_, err = controller.UploadArtifactParts(t.Context(), s.ID, 0, bytes.NewReader([]byte{1, 2, 3}))
+		require.NoError(t, err)
+
+		_, err = controller.CompleteArtifactUpload(t.Context(), s.ID)
+		require.ErrorIs(t, err, types.ErrBadRequest)
+
+		_, err = controller.ArtifactUpload(t.Context(), s.ID)
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+
+	t.Run("expired_uploads_are_reaped", func(t *testing.T) {
+		expiring := controllers.Controller{
+			Redis:             controller.Redis,
+			S3:                controller.S3,
+			ArtifactUploadTTL: time.Millisecond,
+		}
+
+		s, err := expiring.InitArtifactUpload(t.Context(), run.Name, "expired.pt", string(types.ModelContentType),
+			3, "")
+		require.NoError(t, err)
+
+		time.Sleep(time.Second)
+
+		_, err = expiring.ArtifactUpload(t.Context(), s.ID)
+		require.ErrorIs(t, err, types.ErrNotFound)
+
+		reaped, err := expiring.ReapArtifactUploads(t.Context())
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, reaped, 1)
+
+		_, err = controller.Redis.UploadSession(t.Context(), s.ID)
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestPresignedArtifactTransfers(t *testing.T) {
+	controller := controllers.Controller{
+		Redis:         store.RedisStore{Client: *client},
+		S3:            objectStore,
+		PresignedURLs: true,
+	}
+
+	run := types.NewRun("presign-run", "presign-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	content := []byte("presigned checkpoint")
+	digester := types.NewDigester()
+	_, err := digester.Write(content)
+	require.NoError(t, err)
+
+	sha256 := digester.Digest().SHA256
+
+	send := func(t *testing.T, presigned *types.PresignedRequest, body []byte) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), presigned.Method, presigned.URL, bytes.NewReader(body))
+		require.NoError(t, err)
+
+		for k, v := range presigned.Headers {
+			req.Header.Set(k, v)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		disabled := controllers.Controller{Redis: controller.Redis, S3: objectStore}
+
+		_, _, err := disabled.ArtifactUploadURL(t.Context(), run.Name, "model.pt", string(types.ModelContentType),
+			int64(len(content)), sha256)
+		require.ErrorIs(t, err, types.ErrInvalidInput)
+	})
+
+	t.Run("uploads_require_a_digest", func(t *testing.T) {
+		_, _, err := controller.ArtifactUploadURL(t.Context(), run.Name, "model.pt", string(types.ModelContentType),
+			int64(len(content)), "")
+		require.ErrorIs(t, err, types.ErrBadRequest)
+	})
+
+	t.Run("upload_and_download", func(t *testing.T) {
+		s, presigned, err := controller.ArtifactUploadURL(t.Context(), run.Name, "model.pt",
+			string(types.ModelContentType), int64(len(content)), sha256)
+		require.NoError(t, err)
+
+		_, err = controller.CompleteArtifactUpload(t.Context(), s.ID)
+		require.ErrorIs(t, err, types.ErrInvalidInput)
+
+		res := send(t, presigned, content)
+		res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		a, err := controller.CompleteArtifactUpload(t.Context(), s.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), a.Size)
+		assert.Equal(t, sha256, a.SHA256)
+
+		_, presigned, err = controller.ArtifactDownloadURL(t.Context(), run.Name, "model.pt")
+		require.NoError(t, err)
+
+		res = send(t, presigned, nil)
+		defer res.Body.Close()
+
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, content, b)
+	})
+
+	t.Run("mismatching_content_is_rejected_by_the_object_store", func(t *testing.T) {
+		s, presigned, err := controller.ArtifactUploadURL(t.Context(), run.Name, "other.pt",
+			string(types.ModelContentType), int64(len(content)), sha256)
+		require.NoError(t, err)
+
+		res := send(t, presigned, bytes.Repeat([]byte{0}, len(content)))
+		res.Body.Close()
+		assert.NotEqual(t, http.StatusOK, res.StatusCode)
+
+		_, err = controller.CompleteArtifactUpload(t.Context(), s.ID)
+		require.ErrorIs(t, err, types.ErrInvalidInput)
+	})
+}
+
+func TestArtifactDeduplication(t *testing.T) {
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: objectStore}
+
+	content := []byte("deduplicated checkpoint")
+	digester := types.NewDigester()
+	_, err := digester.Write(content)
+	require.NoError(t, err)
+
+	digest := digester.Digest()
+
+	first := types.NewRun("dedup-first", "dedup-exp")
+	second := types.NewRun("dedup-second", "dedup-exp")
+
+	for _, run := range []types.Run{first, second} {
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+
+		artifact, err := types.NewDigestedArtifact("model.pt", string(types.ModelContentType),
+			bytes.NewReader(content), digest)
+		require.NoError(t, err)
+		require.NoError(t, controller.AddArtifacts(t.Context(), run.Name, []types.Artifact{artifact}))
+	}
+
+	blob, err := controller.Redis.Blob(t.Context(), digest.SHA256)
+	require.NoError(t, err)
+	assert.Equal(t, digest.Size, blob.Size)
+
+	queued := func(t *testing.T, key string) bool {
+		t.Helper()
+
+		_, err := client.ZScore(t.Context(), store.ArtifactDeletionQueueKey, key).Result()
+		if errors.Is(err, redisv9.Nil) {
+			return false
+		}
+
+		require.NoError(t, err)
+
+		return true
+	}
+
+	t.Run("identical_content_is_stored_once", func(t *testing.T) {
+		for _, run := range []types.Run{first, second} {
+			saved, body, err := controller.Artifact(t.Context(), run.Name, "model.pt")
+			require.NoError(t, err)
+
+			b, err := io.ReadAll(body)
+			body.Close()
+			require.NoError(t, err)
+
+			assert.Equal(t, blob.Key, saved.S3Key)
+			assert.Equal(t, content, b)
+		}
+	})
+
+	t.Run("uploads_of_stored_content_are_deduplicated", func(t *testing.T) {
+		s, err := controller.InitArtifactUpload(t.Context(), first.Name, "copy.pt", string(types.ModelContentType),
+			digest.Size, digest.SHA256)
+		require.NoError(t, err)
+		assert.True(t, s.Deduplicated)
+		assert.True(t, s.Complete())
+
+		a, err := controller.CompleteArtifactUpload(t.Context(), s.ID)
+		require.NoError(t, err)
+		assert.Equal(t, blob.Key, a.S3Key)
+		assert.Equal(t, digest.SHA256, a.SHA256)
+	})
+
+	t.Run("uploads_of_content_of_other_runs_are_not_deduplicated", func(t *testing.T) {
+		third := types.NewRun("dedup-third", "dedup-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), third))
+
+		s, err := controller.InitArtifactUpload(t.Context(), third.Name, "model.pt", string(types.ModelContentType),
+			digest.Size, digest.SHA256)
+		require.NoError(t, err)
+		assert.False(t, s.Deduplicated)
+		assert.False(t, s.Complete())
+
+		require.NoError(t, controller.AbortArtifactUpload(t.Context(), s.ID))
+	})
+
+	t.Run("content_is_deleted_with_its_last_reference", func(t *testing.T) {
+		_, err := controller.DeleteRun(t.Context(), second.Name)
+		require.NoError(t, err)
+		assert.False(t, queued(t, blob.Key))
+
+		_, err = controller.DeleteRun(t.Context(), first.Name)
+		require.NoError(t, err)
+		assert.True(t, queued(t, blob.Key))
+
+		_, err = controller.Redis.Blob(t.Context(), digest.SHA256)
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestDeleteRunSharingRegisteredContent(t *testing.T) {
+	t.Parallel()
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: objectStore}
+
+	content := []byte("shared registered checkpoint")
+	digester := types.NewDigester()
+	_, err := digester.Write(content)
+	require.NoError(t, err)
+
+	digest := digester.Digest()
+
+	registered := types.NewRun("shared-registered", "shared-exp")
+	other := types.NewRun("shared-other", "shared-exp")
+
+	for _, run := range []types.Run{registered, other} {
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+
+		artifact, err := types.NewDigestedArtifact("model.pt", string(types.ModelContentType),
+			bytes.NewReader(content), digest)
+		require.NoError(t, err)
+		require.NoError(t, controller.AddArtifacts(t.Context(), run.Name, []types.Artifact{artifact}))
+	}
+
+	ops := types.RegistryBenchmarkOps{BenchmarkImage: "", BenchmarkGpuPassthrough: false}
+	require.NoError(t, controller.CreateModelRegistry(t.Context(), "shared-registry", ops))
+	require.NoError(t, controller.AddArtifactToRegistry(t.Context(), "shared-registry", registered.Name, "model.pt"))
+
+	_, err = controller.DeleteRun(t.Context(), other.Name)
+	require.NoError(t, err)
+
+	_, err = controller.Redis.Blob(t.Context(), digest.SHA256)
+	require.NoError(t, err)
+
+	_, err = controller.DeleteRun(t.Context(), registered.Name)
+	require.ErrorIs(t, err, types.ErrAlreadyInUse)
+}
+
+func TestBackfillBlobs(t *testing.T) {
+	// not parallel: the backfill records the artifacts of every run, racing
+	// the deletion of runs by other tests
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: s3.MockObjectStore{}}
+
+	digester := types.NewDigester()
+	_, err := digester.Write([]byte("checkpoint saved before blobs"))
+	require.NoError(t, err)
+
+	digest := digester.Digest()
+
+	// artifacts saved before blobs were recorded have none
+	first := types.NewRun("blob-backfill-first", "blob-backfill-exp")
+	second := types.NewRun("blob-backfill-second", "blob-backfill-exp")
+
+	for _, run := range []types.Run{first, second} {
+		require.NoError(t, controller.CreateRun(t.Context(), run))
+		require.NoError(t, controller.Redis.SetArtifact(t.Context(), run.Name, types.SavedArtifact{ //nolint: exhaustruct
+			Name:        "model.pt",
+			ContentType: types.ModelContentType,
+			S3Key:       run.Name + "-model.pt",
+			Size:        digest.Size,
+			SHA256:      digest.SHA256,
+		}))
+	}
+
+	refs := fmt.Sprintf(store.BlobRefsKeyPattern, digest.SHA256)
+
+	for range 2 {
+		require.NoError(t, controller.Redis.BackfillBlobs(t.Context()))
+
+		blob, err := controller.Redis.Blob(t.Context(), digest.SHA256)
+		require.NoError(t, err)
+		assert.Equal(t, digest.Size, blob.Size)
+
+		// only the artifact whose object became the blob's references it,
+		// the other one keeps its own object
+		owner := first
+		if blob.Key != first.Name+"-model.pt" {
+			owner = second
+		}
+
+		assert.Equal(t, owner.Name+"-model.pt", blob.Key)
+
+		members, err := client.SMembers(t.Context(), refs).Result()
+		require.NoError(t, err)
+		assert.Equal(t, []string{controller.Redis.ArtifactRef(owner.Name, "model.pt")}, members)
+	}
+}
+
+func TestDirectoryArtifacts(t *testing.T) {
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: objectStore}
+
+	run := types.NewRun("directory-run", "directory-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	files := map[string]string{
+		"config.json":         `{"model_type": "bert"}`,
+		"tokenizer/vocab.txt": "[CLS]\n[SEP]\n",
+	}
+
+	archive := func(t *testing.T, entries map[string]string) io.Reader {
+		t.Helper()
+
+		var buf bytes.Buffer
+
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "tokenizer/", Mode: 0o755}))
+
+		for name, content := range entries {
+			require.NoError(t, tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg, Name: "./" + name, Size: int64(len(content)), Mode: 0o644,
+			}))
+			_, err := tw.Write([]byte(content))
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+
+		return &buf
+	}
+
+	a, saved, err := controller.AddDirectoryArtifact(t.Context(), run.Name, "bert", archive(t, files))
+	require.NoError(t, err)
+	assert.Equal(t, types.DirectoryContentType, a.ContentType)
+	assert.Equal(t, int64(len(files["config.json"])+len(files["tokenizer/vocab.txt"])), a.Size)
+	require.Len(t, saved, 2)
+
+	t.Run("files_are_listed_by_path", func(t *testing.T) {
+		listed, err := controller.ArtifactFiles(t.Context(), run.Name, "bert")
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+		assert.Equal(t, "config.json", listed[0].Path)
+		assert.Equal(t, "tokenizer/vocab.txt", listed[1].Path)
+	})
+
+	t.Run("files_are_downloaded_one_by_one", func(t *testing.T) {
+		f, body, err := controller.ArtifactFile(t.Context(), run.Name, "bert", "tokenizer/vocab.txt")
+		require.NoError(t, err)
+
+		defer body.Close()
+
+		content, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, files["tokenizer/vocab.txt"], string(content))
+		assert.Equal(t, int64(len(content)), f.Size)
+
+		_, _, err = controller.ArtifactFile(t.Context(), run.Name, "bert", "missing.txt")
+		require.ErrorIs(t, err, types.ErrNotFound)
+
+		_, _, err = controller.Artifact(t.Context(), run.Name, "bert")
+		require.ErrorIs(t, err, types.ErrBadRequest)
+	})
+
+	t.Run("tree_is_downloaded_as_an_archive", func(t *testing.T) {
+		_, listed, err := controller.ArtifactArchive(t.Context(), run.Name, "bert")
+		require.NoError(t, err)
+
+		var tarBuf bytes.Buffer
+		require.NoError(t, controller.WriteArchive(t.Context(), listed, types.TarArchive, &tarBuf))
+
+		tr := tar.NewReader(&tarBuf)
+		got := make(map[string]string)
+
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			require.NoError(t, err)
+
+			content, err := io.ReadAll(tr)
+			require.NoError(t, err)
+
+			got[hdr.Name] = string(content)
+		}
+
+		assert.Equal(t, files, got)
+
+		var zipBuf bytes.Buffer
+		require.NoError(t, controller.WriteArchive(t.Context(), listed, types.ZipArchive, &zipBuf))
+
+		zr, err := zip.NewReader(bytes.NewReader(zipBuf.Bytes()), int64(zipBuf.Len()))
+		require.NoError(t, err)
+		assert.Len(t, zr.File, len(files))
+	})
+
+	t.Run("paths_leaving_the_directory_are_rejected", func(t *testing.T) {
+		_, _, err := controller.AddDirectoryArtifact(t.Context(), run.Name, "escape",
+			archive(t, map[string]string{"../escape.txt": "x"}))
+		require.ErrorIs(t, err, types.ErrBadRequest)
+
+		err = controller.Redis.ArtifactExist(t.Context(), run.Name, "escape")
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+
+	t.Run("directories_are_registered_as_model_entries", func(t *testing.T) {
+		ops := types.RegistryBenchmarkOps{BenchmarkImage: "", BenchmarkGpuPassthrough: false}
+		require.NoError(t, controller.CreateModelRegistry(t.Context(), "directory-registry", ops))
+		require.NoError(t, controller.AddArtifactToRegistry(t.Context(), "directory-registry", run.Name, "bert",
+			"latest"))
+
+		entry, err := controller.TaggedModel(t.Context(), "directory-registry", "latest")
+		require.NoError(t, err)
+		assert.Empty(t, entry.URL)
+		assert.ElementsMatch(t, saved, entry.Files)
+
+		_, _, err = controller.TaggedModelDownloadURL(t.Context(), "directory-registry", "latest")
+		require.Error(t, err)
+	})
+}
+
+func TestArtifactKinds(t *testing.T) {
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: objectStore}
+
+	run := types.NewRun("kinds-run", "kinds-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	content := []byte("epoch,loss\n1,0.5\n2,0.25\n")
+	digester := types.NewDigester()
+	sniffer := types.NewMIMESniffer()
+	_, err := io.MultiWriter(digester, sniffer).Write(content)
+	require.NoError(t, err)
+
+	artifact, err := types.NewSniffedArtifact("metrics.csv", string(types.TableContentType),
+		bytes.NewReader(content), digester.Digest(), sniffer.MIMEType(types.TableContentType, "metrics.csv"))
+	require.NoError(t, err)
+	require.NoError(t, controller.AddArtifacts(t.Context(), run.Name, []types.Artifact{artifact}))
+
+	t.Run("mime_type", func(t *testing.T) {
+		saved, body, err := controller.Artifact(t.Context(), run.Name, "metrics.csv")
+		require.NoError(t, err)
+
+		defer body.Close()
+
+		assert.Equal(t, types.TableContentType, saved.ContentType)
+		assert.Equal(t, "text/csv; charset=utf-8", saved.ServedMIMEType())
+	})
+
+	t.Run("range", func(t *testing.T) {
+		_, r, ranged, body, err := controller.ArtifactRange(t.Context(), run.Name, "metrics.csv", "bytes=0-9", "")
+		require.NoError(t, err)
+
+		defer body.Close()
+
+		got, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, &types.ByteRange{Start: 0, End: 9}, r)
+		assert.True(t, ranged)
+		assert.Equal(t, content[:10], got)
+	})
+
+	t.Run("whole_content", func(t *testing.T) {
+		_, r, _, body, err := controller.ArtifactRange(t.Context(), run.Name, "metrics.csv", "", "")
+		require.NoError(t, err)
+
+		defer body.Close()
+
+		got, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Nil(t, r)
+		assert.Equal(t, content, got)
+	})
+
+	t.Run("unsatisfiable_range", func(t *testing.T) {
+		_, _, _, _, err := controller.ArtifactRange(t.Context(), run.Name, "metrics.csv", "bytes=100-", "")
+		require.ErrorIs(t, err, types.ErrRangeNotSatisfiable)
+	})
+}
+
+func TestRunArtifacts(t *testing.T) {
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: objectStore}
+
+	run := types.NewRun("listed-run", "listed-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	for _, name := range []string{"weights.pt", "notes.txt"} {
+		artifact, err := types.NewArtifact(name, string(types.TextContentType), bytes.NewReader([]byte(name)))
+		require.NoError(t, err)
+		require.NoError(t, controller.AddArtifacts(t.Context(), run.Name, []types.Artifact{artifact}))
+	}
+
+	artifacts, err := controller.RunArtifacts(t.Context(), run.Name)
+	require.NoError(t, err)
+	require.Len(t, artifacts, 2)
+
+	assert.Equal(t, "notes.txt", artifacts[0].Name)
+	assert.Equal(t, "weights.pt", artifacts[1].Name)
+	assert.Equal(t, types.TextContentType, artifacts[0].ContentType)
+	assert.False(t, artifacts[0].CreatedAt.IsZero())
+
+	byRun, err := controller.Artifacts(t.Context(), []string{run.Name})
+	require.NoError(t, err)
+	assert.Equal(t, artifacts, byRun[run.Name])
+
+	_, err = controller.RunArtifacts(t.Context(), "missing-run")
+	require.ErrorIs(t, err, types.ErrNotFound)
+}
+
+func TestCollectArtifactGarbage(t *testing.T) {
+	// objects are listed under their own prefix, so the garbage of other
+	// tests is left alone
+	gcStore := objectStore
+	gcStore.Prefix = "gc-test"
+
+	controller := controllers.Controller{Redis: store.RedisStore{Client: *client}, S3: gcStore}
+
+	run := types.NewRun("gc-run", "gc-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	artifact, err := types.NewArtifact("model.pt", string(types.ModelContentType), bytes.NewReader([]byte{1, 2, 3}))
+	require.NoError(t, err)
+	require.NoError(t, controller.AddArtifacts(t.Context(), run.Name, []types.Artifact{artifact}))
+
+	saved, err := controller.Redis.Artifact(t.Context(), run.Name, "model.pt")
+	require.NoError(t, err)
+
+	orphan, err := gcStore.GenerateKey("orphan.bin")
+	require.NoError(t, err)
+	_, err = gcStore.UploadFile(t.Context(), orphan, bytes.NewReader([]byte{4, 5, 6}))
+	require.NoError(t, err)
+
+	missing := types.SavedArtifact{Name: "missing.pt", ContentType: types.ModelContentType, S3Key: "gc-test/missing.pt"} //nolint: exhaustruct
+	require.NoError(t, controller.Redis.SetArtifact(t.Context(), run.Name, missing))
+
+	dangling := types.DanglingRef{Ref: controller.Redis.ArtifactRef(run.Name, "missing.pt"), Key: missing.S3Key}
+
+	t.Run("dry_run", func(t *testing.T) {
+		report, err := controller.CollectArtifactGarbage(t.Context(), time.Nanosecond, true)
+		require.NoError(t, err)
+
+		assert.Contains(t, report.Orphans, orphan)
+		assert.NotContains(t, report.Orphans, saved.S3Key)
+		assert.Empty(t, report.Deleted)
+		assert.Contains(t, report.Dangling, dangling)
+	})
+
+	t.Run("within_grace_period", func(t *testing.T) {
+		report, err := controller.CollectArtifactGarbage(t.Context(), time.Hour, false)
+		require.NoError(t, err)
+
+		assert.Contains(t, report.Orphans, orphan)
+		assert.NotContains(t, report.Deleted, orphan)
+	})
+
+	t.Run("delete_orphans", func(t *testing.T) {
+		report, err := controller.CollectArtifactGarbage(t.Context(), time.Nanosecond, false)
+		require.NoError(t, err)
+
+		assert.Contains(t, report.Deleted, orphan)
+
+		_, err = gcStore.ObjectSize(t.Context(), orphan)
+		require.ErrorIs(t, err, types.ErrNotFound)
+
+		_, err = gcStore.ObjectSize(t.Context(), saved.S3Key)
+		require.NoError(t, err)
+	})
+
+	t.Run("external_model_entries_are_ignored", func(t *testing.T) {
+		require.NoError(t, controller.CreateModelRegistry(t.Context(), "gc-registry", types.RegistryBenchmarkOps{})) //nolint: exhaustruct
+		require.NoError(t, controller.AddModelEntry(t.Context(), "gc-registry", "https://example.com/model.pt"))
+		require.NoError(t, controller.AddModelEntry(t.Context(), "gc-registry", "s3://other-bucket/gc-test/model.pt"))
+
+		report, err := controller.CollectArtifactGarbage(t.Context(), time.Hour, true)
+		require.NoError(t, err)
+
+		for _, d := range report.Dangling {
+			assert.NotContains(t, d.Ref, "gc-registry")
+		}
+	})
+}
+
+func TestArtifactCompression(t *testing.T) {
+	controller := controllers.Controller{
+		Redis:       store.RedisStore{Client: *client},
+		S3:          objectStore,
+		Compression: map[types.ContentType]types.ContentEncoding{types.TextContentType: types.ZstdEncoding},
+	}
+
+	run := types.NewRun("compressed-run", "compressed-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	content := []byte(strings.Repeat("epoch=1 loss=0.25\n", 200))
+	digester := types.NewDigester()
+	_, err := digester.Write(content)
+	require.NoError(t, err)
+
+	artifact, err := types.NewDigestedArtifact("train.log", string(types.TextContentType),
+		bytes.NewReader(content), digester.Digest())
+	require.NoError(t, err)
+	require.NoError(t, controller.AddArtifacts(t.Context(), run.Name, []types.Artifact{artifact}))
+
+	t.Run("decompressed", func(t *testing.T) {
+		saved, body, err := controller.Artifact(t.Context(), run.Name, "train.log")
+		require.NoError(t, err)
+
+		defer body.Close()
+
+		got, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, types.ZstdEncoding, saved.Encoding)
+		assert.Equal(t, int64(len(content)), saved.Size)
+		assert.Equal(t, content, got)
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		saved, _, _, body, err := controller.ArtifactRange(t.Context(), run.Name, "train.log", "", "gzip, zstd")
+		require.NoError(t, err)
+
+		defer body.Close()
+
+		raw, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, types.ZstdEncoding, saved.Encoding)
+		assert.Less(t, len(raw), len(content))
+
+		dec, err := types.ZstdEncoding.NewDecoder(io.NopCloser(bytes.NewReader(raw)))
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(dec)
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+	})
+
+	t.Run("not_accepted", func(t *testing.T) {
+		saved, r, ranged, body, err := controller.ArtifactRange(t.Context(), run.Name, "train.log", "bytes=0-9", "gzip")
+		require.NoError(t, err)
+
+		defer body.Close()
+
+		got, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Nil(t, r)
+		assert.False(t, ranged)
+		assert.Equal(t, types.IdentityEncoding, saved.Encoding)
+		assert.Equal(t, content, got)
+	})
+
+	t.Run("duplicates_share_the_encoding", func(t *testing.T) {
+		other := types.NewRun("compressed-run-copy", "compressed-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), other))
+
+		copied, err := types.NewDigestedArtifact("train.log", string(types.TextContentType),
+			bytes.NewReader(content), digester.Digest())
+		require.NoError(t, err)
+		require.NoError(t, controller.AddArtifacts(t.Context(), other.Name, []types.Artifact{copied}))
+
+		saved, err := controller.Redis.Artifact(t.Context(), other.Name, "train.log")
+		require.NoError(t, err)
+		assert.Equal(t, types.ZstdEncoding, saved.Encoding)
+	})
+}
+
+func TestStorageQuotas(t *testing.T) {
+	controller := controllers.Controller{
+		Redis:        store.RedisStore{Client: *client},
+		S3:           objectStore,
+		StorageQuota: types.StorageQuota{MaxArtifactSize: 64, Run: 100, Experiment: 150},
+	}
+
+	newArtifact := func(t *testing.T, name string, size int) types.Artifact {
+		t.Helper()
+
+		content := bytes.Repeat([]byte{1}, size)
+		digester := types.NewDigester()
+		_, err := digester.Write(content)
+		require.NoError(t, err)
+
+		a, err := types.NewDigestedArtifact(name, string(types.ModelContentType), bytes.NewReader(content),
+			digester.Digest())
+		require.NoError(t, err)
+
+		return a
+	}
+
+	run := types.NewRun("quota-run", "quota-exp")
+	require.NoError(t, controller.CreateRun(t.Context(), run))
+
+	t.Run("artifacts_are_counted", func(t *testing.T) {
+		require.NoError(t, controller.AddArtifacts(t.Context(), run.Name,
+			[]types.Artifact{newArtifact(t, "a.pt", 40), newArtifact(t, "b.pt", 40)}))
+
+		usage, err := controller.RunStorageUsage(t.Context(), run.Name)
+		require.NoError(t, err)
+		assert.Equal(t, types.StorageUsage{Bytes: 80, Artifacts: 2, Quota: 100}, *usage)
+
+		allowance, err := controller.ArtifactAllowance(t.Context(), run.Name)
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), allowance)
+	})
+
+	t.Run("artifacts_over_the_max_size_are_rejected", func(t *testing.T) {
+		other := types.NewRun("quota-run-large", "quota-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), other))
+
+		err := controller.AddArtifacts(t.Context(), other.Name, []types.Artifact{newArtifact(t, "large.pt", 65)})
+		require.ErrorIs(t, err, types.ErrResourceExhausted)
+
+		err = controller.Redis.ArtifactExist(t.Context(), other.Name, "large.pt")
+		require.ErrorIs(t, err, types.ErrNotFound)
+	})
+
+	t.Run("run_quota", func(t *testing.T) {
+		err := controller.AddArtifacts(t.Context(), run.Name, []types.Artifact{newArtifact(t, "c.pt", 30)})
+		require.ErrorIs(t, err, types.ErrResourceExhausted)
+
+		_, err = controller.InitArtifactUpload(t.Context(), run.Name, "d.pt", string(types.ModelContentType), 30, "")
+		require.ErrorIs(t, err, types.ErrResourceExhausted)
+
+		usage, err := controller.RunStorageUsage(t.Context(), run.Name)
+		require.NoError(t, err)
+		assert.Equal(t, int64(80), usage.Bytes)
+	})
+
+	t.Run("experiment_quota", func(t *testing.T) {
+		other := types.NewRun("quota-run-other", "quota-exp")
+		require.NoError(t, controller.CreateRun(t.Context(), other))
+
+		require.NoError(t, controller.AddArtifacts(t.Context(), other.Name,
+			[]types.Artifact{newArtifact(t, "e.pt", 60)}))
+
+		err := controller.AddArtifacts(t.Context(), other.Name, []types.Artifact{newArtifact(t, "f.pt", 20)})
+		require.ErrorIs(t, err, types.ErrResourceExhausted)
+
+		usage, err := controller.ExperimentStorageUsage(t.Context(), "quota-exp")
+		require.NoError(t, err)
+		assert.Equal(t, types.StorageUsage{Bytes: 140, Artifacts: 3, Quota: 150}, *usage)
+	})
+
+	t.Run("deleted_runs_free_their_storage", func(t *testing.T) {
+		_, err := controller.DeleteRun(t.Context(), run.Name)
+		require.NoError(t, err)
+
+		usage, err := controller.ExperimentStorageUsage(t.Context(), "quota-exp")
+		require.NoError(t, err)
+		assert.Equal(t, types.StorageUsage{Bytes: 60, Artifacts: 1, Quota: 150}, *usage)
+	})
+}
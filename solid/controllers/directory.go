@@ -0,0 +1,407 @@
+package controllers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// AddDirectoryArtifact saves a directory artifact to a run, e.g. a
+// HuggingFace model, from a tar archive of the directory (optionally
+// gzipped) read from archive. The archive is unpacked as it streams in,
+// each regular file being stored as the blob of its content.
+func (c *Controller) AddDirectoryArtifact(ctx context.Context, runID, name string,
+	archive io.Reader,
+) (*types.SavedArtifact, []types.ArtifactFile, error) {
+	if name == "" {
+		return nil, nil, types.NewBadRequest("artifact name is required")
+	}
+
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !ok {
+		return nil, nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	err = c.Redis.ArtifactExist(ctx, id, name)
+	if err == nil {
+		return nil, nil, types.NewAlreadyInUseErr(fmt.Sprintf("artifact <%s> of run <%s> already exists", name, id))
+	} else if !errors.Is(err, types.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	if c.S3 == nil {
+		return nil, nil, types.NewInternalErr("object store is not configured")
+	}
+
+	allowance, err := c.ArtifactAllowance(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := c.storeDirectory(ctx, id, name, archive, allowance)
+	if err != nil {
+		return nil, nil, errors.Join(err, c.releaseArtifactFiles(ctx, id, name, files))
+	}
+
+	a := types.SavedArtifact{
+		Name:        name,
+		ContentType: types.DirectoryContentType,
+		S3Key:       "",
+		Size:        0,
+		SHA256:      "",
+	}
+
+	for _, f := range files {
+		a.Size += f.Size
+	}
+
+	err = c.reserveArtifactStorage(ctx, id, a.Size)
+	if err != nil {
+		return nil, nil, errors.Join(err, c.releaseArtifactFiles(ctx, id, name, files))
+	}
+
+	err = c.Redis.SetDirectoryArtifact(ctx, id, a, files)
+	if err != nil {
+		err = errors.Join(err, c.releaseArtifactStorage(ctx, id, a.Size))
+
+		return nil, nil, errors.Join(err, c.releaseArtifactFiles(ctx, id, name, files))
+	}
+
+	return &a, files, nil
+}
+
+// storeDirectory stores the regular files of a tar archive as the files of
+// the directory artifact name of a run, and returns the files stored, even
+// when it fails part way. Archives whose files add up to more than
+// allowance bytes (unless it is negative) are rejected as soon as the file
+// going over it is reached.
+func (c *Controller) storeDirectory(ctx context.Context, runID, name string,
+	archive io.Reader, allowance int64,
+) ([]types.ArtifactFile, error) {
+	tr, err := openTar(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]types.ArtifactFile, 0)
+	seen := make(map[string]struct{})
+	size := int64(0)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return files, types.NewBadRequest(fmt.Sprintf("malformed archive: %s", err))
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+		case tar.TypeDir, tar.TypeXGlobalHeader:
+			continue
+		default:
+			return files, types.NewBadRequest(fmt.Sprintf("archive entry <%s> is not a regular file", hdr.Name))
+		}
+
+		p, err := types.CleanArtifactPath(hdr.Name)
+		if err != nil {
+			return files, err
+		}
+
+		if _, ok := seen[p]; ok {
+			return files, types.NewBadRequest(fmt.Sprintf("archive holds <%s> more than once", p))
+		}
+
+		seen[p] = struct{}{}
+
+		size += hdr.Size
+		if allowance >= 0 && size > allowance {
+			return files, types.NewResourceExhaustedErr(fmt.Sprintf(
+				"directory artifact <%s> exceeds the %d bytes run <%s> may still store", name, allowance, runID))
+		}
+
+		f, err := c.storeArtifactFile(ctx, runID, name, p, tr)
+		if err != nil {
+			return files, err
+		}
+
+		files = append(files, *f)
+	}
+
+	if len(files) == 0 {
+		return files, types.NewBadRequest("archive has no files")
+	}
+
+	return files, nil
+}
+
+// storeArtifactFile stores the file p of the directory artifact name of a
+// run, read from r, as the blob of its content. Content that is already
+// stored is not uploaded again. Files are compressed as artifacts of kind
+// binary are, see Compression.
+func (c *Controller) storeArtifactFile(ctx context.Context, runID, name, p string,
+	r io.Reader,
+) (*types.ArtifactFile, error) {
+	// the file is spooled to disk so its digest is known before uploading
+	tmp, err := os.CreateTemp("", "artifact_file")
+	if err != nil {
+		return nil, types.NewInternalErr("could not create tmp file")
+	}
+
+	defer tmp.Close()           //nolint: errcheck
+	defer os.Remove(tmp.Name()) //nolint: errcheck
+
+	digester := types.NewDigester()
+	sniffer := types.NewMIMESniffer()
+
+	if _, err := io.Copy(io.MultiWriter(tmp, digester, sniffer), r); err != nil {
+		return nil, types.NewBadRequest(fmt.Sprintf("could not read <%s> from archive: %s", p, err))
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w: could not rewind tmp file: %w", types.ErrInternal, err)
+	}
+
+	digest := digester.Digest()
+	ref := c.Redis.ArtifactFileRef(runID, name, p)
+	f := &types.ArtifactFile{
+		Path:     p,
+		S3Key:    "",
+		Size:     digest.Size,
+		SHA256:   digest.SHA256,
+		MIMEType: sniffer.MIMEType(types.BinaryContentType, p),
+	}
+
+	b, err := c.Redis.AcquireBlob(ctx, ref, types.Blob{SHA256: digest.SHA256, Key: "", Size: digest.Size})
+	if err == nil {
+		f.S3Key, f.Encoding = b.Key, b.Encoding
+
+		return f, nil
+	} else if !errors.Is(err, types.ErrNotFound) {
+		return nil, err
+	}
+
+	artifact, err := types.NewSniffedArtifact(path.Join(name, p), string(types.BinaryContentType), tmp, digest,
+		f.MIMEType)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, cleanup, err := c.compressArtifact(artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cleanup()
+
+	uploaded, err := c.S3.UploadArtifacts(ctx, []types.Artifact{artifact})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(uploaded) != 1 {
+		return nil, types.NewInternalErr(fmt.Sprintf("could not upload <%s>", p))
+	}
+
+	b, err = c.acquireBlob(ctx, ref, types.Blob{
+		SHA256: digest.SHA256, Key: uploaded[0].S3Key, Size: digest.Size, Encoding: uploaded[0].Encoding,
+	})
+	if err != nil {
+		return nil, errors.Join(err, c.Redis.QueueArtifactDeletion(ctx, uploaded[0].S3Key))
+	}
+
+	f.S3Key, f.Encoding = b.Key, b.Encoding
+
+	return f, nil
+}
+
+// releaseArtifactFiles releases the blobs of the files of a directory
+// artifact of a run that could not be saved.
+func (c *Controller) releaseArtifactFiles(ctx context.Context, runID, name string, files []types.ArtifactFile) error {
+	var errs error
+
+	for _, f := range files {
+		err := c.Redis.ReleaseBlob(ctx, c.Redis.ArtifactFileRef(runID, name, f.Path), f.SHA256, f.S3Key)
+		errs = errors.Join(errs, err)
+	}
+
+	return errs
+}
+
+// openTar opens a tar archive, gunzipping it if it is gzipped.
+func openTar(r io.Reader) (*tar.Reader, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, types.NewBadRequest(fmt.Sprintf("could not read archive: %s", err))
+	}
+
+	if !bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return tar.NewReader(br), nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, types.NewBadRequest(fmt.Sprintf("malformed gzip archive: %s", err))
+	}
+
+	return tar.NewReader(gz), nil
+}
+
+// directoryArtifact pulls a directory artifact of a run along with its
+// files.
+func (c *Controller) directoryArtifact(ctx context.Context, runID, name string,
+) (*types.SavedArtifact, []types.ArtifactFile, error) {
+	id := types.NormalizeID(runID)
+
+	a, err := c.Redis.Artifact(ctx, id, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if a.ContentType != types.DirectoryContentType {
+		return nil, nil, types.NewBadRequest(fmt.Sprintf("artifact <%s> of run <%s> is not a directory", name, id))
+	}
+
+	files, err := c.Redis.ArtifactFiles(ctx, id, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &a, files, nil
+}
+
+// singleFileArtifact rejects directory artifacts, whose content is not a
+// single object.
+func singleFileArtifact(a types.SavedArtifact) error {
+	if a.ContentType == types.DirectoryContentType {
+		return types.NewBadRequest(fmt.Sprintf("artifact <%s> is a directory, download its files or an archive", a.Name))
+	}
+
+	return nil
+}
+
+// ArtifactFiles lists the files of a directory artifact, sorted by path.
+func (c *Controller) ArtifactFiles(ctx context.Context, runID, name string) ([]types.ArtifactFile, error) {
+	_, files, err := c.directoryArtifact(ctx, runID, name)
+
+	return files, err
+}
+
+// ArtifactFile fetches a file of a directory artifact and returns a Reader
+// to its content.
+func (c *Controller) ArtifactFile(ctx context.Context, runID, name, filePath string,
+) (*types.ArtifactFile, io.ReadCloser, error) {
+	p, err := types.CleanArtifactPath(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, files, err := c.directoryArtifact(ctx, runID, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, f := range files {
+		if f.Path != p {
+			continue
+		}
+
+		body, err := c.downloadDecoded(ctx, f.S3Key, f.Encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &f, body, nil
+	}
+
+	return nil, nil, types.NewNotFoundErr(fmt.Sprintf("artifact <%s> has no file <%s>", name, p))
+}
+
+// ArtifactArchive returns the files of a directory artifact, whose content
+// is written by WriteArchive.
+func (c *Controller) ArtifactArchive(ctx context.Context, runID, name string,
+) (*types.SavedArtifact, []types.ArtifactFile, error) {
+	return c.directoryArtifact(ctx, runID, name)
+}
+
+// WriteArchive writes files to w as an archive of format, the files of a
+// directory artifact or model entry.
+func (c *Controller) WriteArchive(ctx context.Context, files []types.ArtifactFile, format types.ArchiveFormat,
+	w io.Writer,
+) error {
+	switch format {
+	case types.ZipArchive:
+		zw := zip.NewWriter(w)
+
+		for _, f := range files {
+			fw, err := zw.CreateHeader(&zip.FileHeader{ //nolint: exhaustruct
+				Name:   f.Path,
+				Method: zip.Deflate,
+			})
+			if err != nil {
+				return fmt.Errorf("could not write <%s> to archive: %w", f.Path, err)
+			}
+
+			if err := c.copyArtifactFile(ctx, fw, f); err != nil {
+				return err
+			}
+		}
+
+		return zw.Close() //nolint: wrapcheck
+	case types.TarArchive:
+		tw := tar.NewWriter(w)
+
+		for _, f := range files {
+			err := tw.WriteHeader(&tar.Header{ //nolint: exhaustruct
+				Typeflag: tar.TypeReg,
+				Name:     f.Path,
+				Size:     f.Size,
+				Mode:     0o644, //nolint: mnd
+			})
+			if err != nil {
+				return fmt.Errorf("could not write <%s> to archive: %w", f.Path, err)
+			}
+
+			if err := c.copyArtifactFile(ctx, tw, f); err != nil {
+				return err
+			}
+		}
+
+		return tw.Close() //nolint: wrapcheck
+	default:
+		return types.NewBadRequest(fmt.Sprintf("unknown archive format <%s>", format))
+	}
+}
+
+func (c *Controller) copyArtifactFile(ctx context.Context, w io.Writer, f types.ArtifactFile) error {
+	body, err := c.downloadDecoded(ctx, f.S3Key, f.Encoding)
+	if err != nil {
+		return err
+	}
+
+	defer body.Close() //nolint: errcheck
+
+	if _, err := io.Copy(w, body); err != nil {
+		return fmt.Errorf("could not write <%s> to archive: %w", f.Path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// compressArtifact compresses the content of a into a tmp file when
+// artifacts of its content type are compressed, see Compression, and returns
+// the artifact to upload along with a func removing the tmp file. The tmp
+// file keeps the body of the upload seekable.
+func (c *Controller) compressArtifact(a types.Artifact) (types.Artifact, func(), error) {
+	encoding := c.Compression[a.ContentType()]
+	if encoding == types.IdentityEncoding {
+		return a, func() {}, nil
+	}
+
+	tmp, err := os.CreateTemp("", "artifact_encoded")
+	if err != nil {
+		return nil, nil, types.NewInternalErr("could not create tmp file")
+	}
+
+	cleanup := func() {
+		tmp.Close()           //nolint: errcheck
+		os.Remove(tmp.Name()) //nolint: errcheck
+	}
+
+	enc, err := encoding.NewEncoder(tmp)
+	if err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	if _, err := io.Copy(enc, a.Content()); err != nil {
+		cleanup()
+
+		return nil, nil, fmt.Errorf("%w: could not compress <%s>: %w", types.ErrInternal, a.Name(), err)
+	}
+
+	if err := errors.Join(enc.Close(), seekStart(tmp)); err != nil {
+		cleanup()
+
+		return nil, nil, fmt.Errorf("%w: could not compress <%s>: %w", types.ErrInternal, a.Name(), err)
+	}
+
+	return types.EncodedArtifact{Artifact: a, EncodedContent: tmp, Encoding: encoding}, cleanup, nil
+}
+
+// downloadDecoded downloads the object key, stored with encoding, and
+// returns a Reader to its decompressed content.
+func (c *Controller) downloadDecoded(ctx context.Context, key string, encoding types.ContentEncoding,
+) (io.ReadCloser, error) {
+	body, err := c.S3.DownloadFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := encoding.NewDecoder(body)
+	if err != nil {
+		return nil, errors.Join(err, body.Close())
+	}
+
+	return decoded, nil
+}
+
+// downloadAccepted downloads the object key, stored with encoding, and
+// returns a Reader to its content as is when the Accept-Encoding header
+// acceptEncoding accepts encoding, decompressed otherwise, along with the
+// encoding of the content returned.
+func (c *Controller) downloadAccepted(ctx context.Context, key string, encoding types.ContentEncoding,
+	acceptEncoding string,
+) (types.ContentEncoding, io.ReadCloser, error) {
+	if encoding.AcceptedBy(acceptEncoding) {
+		body, err := c.S3.DownloadFile(ctx, key)
+
+		return encoding, body, err
+	}
+
+	body, err := c.downloadDecoded(ctx, key, encoding)
+
+	return types.IdentityEncoding, body, err
+}
+
+func seekStart(f *os.File) error {
+	_, err := f.Seek(0, io.SeekStart)
+
+	return err //nolint: wrapcheck
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/zeddo123/mlsolid/solid/types"
 )
@@ -15,6 +16,61 @@ func (c *Controller) ExpRuns(ctx context.Context, expID string) ([]string, error
 	return c.Redis.ExpRunIDs(ctx, expID)
 }
 
+// ExpRunsMatching returns the ids of the runs of an experiment matching
+// filter: tags are looked up through the tag index, params compared with
+// types.MatchParams. An empty filter matches all runs.
+func (c *Controller) ExpRunsMatching(ctx context.Context, expID string, filter types.RunFilter) ([]string, error) {
+	if filter.Empty() {
+		return c.ExpRuns(ctx, expID)
+	}
+
+	var (
+		ids []string
+		err error
+	)
+
+	if len(filter.Tags) > 0 {
+		tags, err := types.NormalizeTags(filter.Tags)
+		if err != nil {
+			return nil, err
+		}
+
+		ids, err = c.Redis.ExpRunIDsWithTags(ctx, expID, tags)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ids, err = c.ExpRuns(ctx, expID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Params) == 0 {
+		return ids, nil
+	}
+
+	params, err := c.Redis.RunsParams(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make(map[string]string, len(filter.Params))
+	for name, val := range filter.Params {
+		normalized[types.NormalizeID(name)] = val
+	}
+
+	matching := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if types.MatchParams(params[id], normalized) {
+			matching = append(matching, id)
+		}
+	}
+
+	return matching, nil
+}
+
 func (c *Controller) RunsFromExp(ctx context.Context, expID string) ([]*types.Run, error) {
 	runs, err := c.ExpRuns(ctx, expID)
 	if err != nil {
@@ -24,6 +80,91 @@ func (c *Controller) RunsFromExp(ctx context.Context, expID string) ([]*types.Ru
 	return c.Runs(ctx, runs)
 }
 
+// ExpMetric is like RunsMetric for all runs of an experiment.
+func (c *Controller) ExpMetric(ctx context.Context, expID, metric string,
+	q types.MetricQuery,
+) (map[string]types.Metric, error) {
+	ids, err := c.ExpRuns(ctx, expID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.RunsMetric(ctx, ids, metric, q)
+}
+
+// SearchRuns returns a page of the runs matching q, in q's order, and the
+// cursor of the next page (0 when there are no more pages).
+func (c *Controller) SearchRuns(ctx context.Context, q types.RunQuery) ([]*types.Run, uint64, error) {
+	ids, next, err := c.Redis.SearchRuns(ctx, q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	runs, err := c.Redis.Runs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Runs doesn't keep the order of ids
+	byID := make(map[string]*types.Run, len(runs))
+	for _, run := range runs {
+		byID[run.Name] = run
+	}
+
+	ordered := make([]*types.Run, 0, len(runs))
+
+	for _, id := range ids {
+		if run, ok := byID[id]; ok {
+			ordered = append(ordered, run)
+		}
+	}
+
+	return ordered, next, nil
+}
+
+// DeleteExperiment deletes an experiment and all of its runs, archived ones
+// included, returning the ids of the deleted runs. Nothing is deleted if an
+// artifact of one of the runs is registered in a model registry.
+func (c *Controller) DeleteExperiment(ctx context.Context, expID string) ([]string, error) {
+	id := types.NormalizeID(expID)
+
+	if err := c.Redis.ExpExists(ctx, id); err != nil {
+		return nil, err
+	}
+
+	ids, err := c.Redis.AllExpRunIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.deleteRuns(ctx, ids); err != nil {
+		return nil, err
+	}
+
+	return ids, c.Redis.DeleteExp(ctx, id)
+}
+
+// ArchiveExperiment hides an experiment from experiment listings. Its runs
+// are left as they are.
+func (c *Controller) ArchiveExperiment(ctx context.Context, expID string) error {
+	return c.setExpArchived(ctx, expID, true)
+}
+
+// RestoreExperiment undoes ArchiveExperiment.
+func (c *Controller) RestoreExperiment(ctx context.Context, expID string) error {
+	return c.setExpArchived(ctx, expID, false)
+}
+
+func (c *Controller) setExpArchived(ctx context.Context, expID string, archived bool) error {
+	id := types.NormalizeID(expID)
+
+	if err := c.Redis.ExpExists(ctx, id); err != nil {
+		return err
+	}
+
+	return c.Redis.SetExpArchived(ctx, id, archived)
+}
+
 func (c *Controller) Exps(ctx context.Context) ([]string, error) {
 	return c.Redis.Exps(ctx)
 }
@@ -36,14 +177,61 @@ func (c *Controller) ExpsPage(ctx context.Context, cursor uint64, limit int64) (
 
 // ExpInfo returns info data linked to an experiment (description, etc).
 func (c *Controller) ExpInfo(ctx context.Context, expID string) (types.ExperimentInfo, error) {
-	info, err := c.Redis.ExpInfo(ctx, expID)
+	id := types.NormalizeID(expID)
+
+	if err := c.Redis.ExpExists(ctx, id); err != nil {
+		return types.ExperimentInfo{}, err
+	}
+
+	info, err := c.Redis.ExpInfo(ctx, id)
 	if err != nil {
-		return info, fmt.Errorf("could not pull ExpInfo: %w", err)
+		return info, fmt.Errorf("%w: could not pull ExpInfo: %w", types.ErrInternal, err)
 	}
 
 	return info, nil
 }
 
+// CreateExperiment creates an experiment ahead of its first run, with its
+// info. Runs can then be created in it as usual.
+func (c *Controller) CreateExperiment(ctx context.Context, expID string, info types.ExperimentInfo) error {
+	id := types.NormalizeID(expID)
+	if id == "" {
+		return types.NewBadRequest("experiment id cannot be empty")
+	}
+
+	tags, err := types.NormalizeTags(info.Tags)
+	if err != nil {
+		return err
+	}
+
+	info.Tags = tags
+	info.CreatedAt = time.Now()
+
+	return c.Redis.CreateExp(ctx, id, info)
+}
+
+// UpdateExperiment updates an experiment's info and returns the result.
+func (c *Controller) UpdateExperiment(ctx context.Context, expID string,
+	update types.UpdateExperiment,
+) (types.ExperimentInfo, error) {
+	id := types.NormalizeID(expID)
+
+	if err := c.Redis.ExpExists(ctx, id); err != nil {
+		return types.ExperimentInfo{}, err
+	}
+
+	update, err := update.Normalize()
+	if err != nil {
+		return types.ExperimentInfo{}, err
+	}
+
+	if err := c.Redis.UpdateExpInfo(ctx, id, update); err != nil {
+		return types.ExperimentInfo{}, err
+	}
+
+	return c.ExpInfo(ctx, id)
+}
+
 // SetExpInfo updates an experiment's info data.
 func (c *Controller) SetExpInfo(ctx context.Context, expID string,
 	info types.ExperimentInfo,
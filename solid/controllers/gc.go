@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// DefaultArtifactGCGrace how old an orphaned object must be for
+// CollectArtifactGarbage to delete it, when no grace period is given.
+const DefaultArtifactGCGrace = 24 * time.Hour
+
+// CollectArtifactGarbage reconciles the objects of the object store with
+// what references them: objects nothing references (e.g. left by an upload
+// that failed midway) are orphans, deleted once older than grace unless
+// dryRun is set, and references to missing objects are reported as
+// dangling. Objects of uploads in progress or queued for deletion are
+// neither. References outside of the object store, e.g. model entries
+// registered with http(s) urls, are ignored (see s3.ObjectStore.ObjectKey).
+//
+// Objects are listed before references are read, so an object uploaded in
+// between is seen as an orphan; grace must outlast uploads to keep them.
+func (c *Controller) CollectArtifactGarbage(ctx context.Context, grace time.Duration,
+	dryRun bool,
+) (*types.GCReport, error) {
+	if grace <= 0 {
+		grace = DefaultArtifactGCGrace
+	}
+
+	objects, err := c.S3.ListObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := c.objectRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := c.Redis.PendingObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.GCReport{
+		Scanned:  len(objects),
+		Orphans:  make([]string, 0),
+		Deleted:  make([]string, 0),
+		Dangling: make([]types.DanglingRef, 0),
+	}
+
+	stored := make(map[string]struct{}, len(objects))
+	cutoff := time.Now().Add(-grace)
+
+	for _, o := range objects {
+		stored[o.Key] = struct{}{}
+
+		if _, ok := refs[o.Key]; ok {
+			continue
+		}
+
+		if _, ok := pending[o.Key]; ok {
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, o.Key)
+
+		if dryRun || o.LastModified.After(cutoff) {
+			continue
+		}
+
+		if err := c.S3.DeleteFile(ctx, o.Key); err != nil {
+			c.Logger.Error().Err(err).Str("key", o.Key).Msg("could not delete orphaned artifact object")
+
+			continue
+		}
+
+		report.Deleted = append(report.Deleted, o.Key)
+	}
+
+	for key, keyRefs := range refs {
+		if _, ok := stored[key]; ok {
+			continue
+		}
+
+		if _, ok := pending[key]; ok {
+			continue
+		}
+
+		for _, ref := range keyRefs {
+			report.Dangling = append(report.Dangling, types.DanglingRef{Ref: ref, Key: key})
+		}
+	}
+
+	slices.Sort(report.Orphans)
+	slices.Sort(report.Deleted)
+	slices.SortFunc(report.Dangling, func(a, b types.DanglingRef) int {
+		return strings.Compare(a.Ref, b.Ref)
+	})
+
+	return report, nil
+}
+
+// objectRefs returns the keys of the objects of the object store referenced
+// from the store, each mapped to what references it.
+func (c *Controller) objectRefs(ctx context.Context) (map[string][]string, error) {
+	refs, err := c.Redis.ObjectRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]string, len(refs))
+
+	for ref, from := range refs {
+		if key, ok := c.S3.ObjectKey(ref); ok {
+			keys[key] = append(keys[key], from...)
+		}
+	}
+
+	return keys, nil
+}
+
+// StartArtifactGC periodically collects artifact garbage, see
+// CollectArtifactGarbage, until ctx is done.
+func (c *Controller) StartArtifactGC(ctx context.Context, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			report, err := c.CollectArtifactGarbage(ctx, grace, false)
+			if err != nil {
+				c.Logger.Error().Err(err).Msg("could not collect artifact garbage")
+
+				continue
+			}
+
+			if len(report.Orphans) > 0 || len(report.Dangling) > 0 {
+				c.Logger.Warn().
+					Int("scanned", report.Scanned).
+					Int("orphans", len(report.Orphans)).
+					Int("deleted", len(report.Deleted)).
+					Int("dangling", len(report.Dangling)).
+					Msg("collected artifact garbage")
+			}
+
+			for _, d := range report.Dangling {
+				c.Logger.Warn().Str("ref", d.Ref).Str("key", d.Key).Msg("artifact references a missing object")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
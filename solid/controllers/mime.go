@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"io"
+
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// sniffLen the number of bytes of an object read to detect its MIME type.
+const sniffLen = 512
+
+// sniffObject detects the MIME type of the content of the artifact name of
+// kind ct, stored as the object of b, from its first bytes. Content that
+// cannot be read is detected from name alone, as the MIME type is not worth
+// failing an upload for.
+func (c *Controller) sniffObject(ctx context.Context, b types.Blob, ct types.ContentType, name string) string {
+	if b.Size == 0 {
+		return types.DetectMIMEType(ct, name, nil)
+	}
+
+	var body io.ReadCloser
+
+	var err error
+
+	if b.Encoding == types.IdentityEncoding {
+		body, err = c.S3.DownloadRange(ctx, b.Key, types.ByteRange{Start: 0, End: min(b.Size, sniffLen) - 1})
+	} else {
+		// compressed content is decompressed from its start
+		body, err = c.downloadDecoded(ctx, b.Key, b.Encoding)
+	}
+
+	if err != nil || body == nil {
+		c.Logger.Warn().Err(err).Str("key", b.Key).Msg("could not read artifact content to detect its mime type")
+
+		return types.DetectMIMEType(ct, name, nil)
+	}
+
+	defer body.Close() //nolint: errcheck
+
+	head, err := io.ReadAll(io.LimitReader(body, sniffLen))
+	if err != nil {
+		c.Logger.Warn().Err(err).Str("key", b.Key).Msg("could not read artifact content to detect its mime type")
+	}
+
+	return types.DetectMIMEType(ct, name, head)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/zeddo123/mlsolid/solid/types"
 )
@@ -57,6 +58,15 @@ func (c *Controller) TaggedModel(ctx context.Context, registryName string, tag s
 	return entry, nil
 }
 
+// ModelEntryContent returns a Reader to the content of a model entry, as is
+// when the Accept-Encoding header acceptEncoding accepts the encoding it is
+// stored with, decompressed otherwise, along with the encoding of the
+// content returned.
+func (c *Controller) ModelEntryContent(ctx context.Context, entry types.ModelEntry, acceptEncoding string,
+) (types.ContentEncoding, io.ReadCloser, error) {
+	return c.downloadAccepted(ctx, entry.URL, entry.Encoding, acceptEncoding)
+}
+
 // AddModelEntry adds a new model entry to the registry.
 func (c *Controller) AddModelEntry(ctx context.Context, registryName string, url string, tags ...string) error {
 	registry, err := c.Redis.ModelRegistry(ctx, registryName)
@@ -92,7 +102,31 @@ func (c *Controller) AddArtifactToRegistry(ctx context.Context, registryName str
 		return fmt.Errorf("failed pulling artifact: %w", err)
 	}
 
-	registry.AddArtifact(runID, artifactID, artifact.S3Key, tags...)
+	// the entry keeps its content alive once the run is deleted
+	version := registry.LatestVersion() + 1
+
+	if artifact.ContentType == types.DirectoryContentType {
+		files, err := c.acquireModelEntryFiles(ctx, registry.Name, version, runID, artifactID)
+		if err != nil {
+			return err
+		}
+
+		registry.AddDirectoryArtifact(runID, artifactID, files, tags...)
+	} else {
+		url, encoding := artifact.S3Key, artifact.Encoding
+
+		if artifact.SHA256 != "" {
+			b, err := c.Redis.AcquireBlob(ctx, c.Redis.ModelEntryRef(registry.Name, version),
+				types.Blob{SHA256: artifact.SHA256, Key: artifact.S3Key, Size: artifact.Size, Encoding: artifact.Encoding})
+			if err != nil {
+				return fmt.Errorf("failed referencing model content: %w", err)
+			}
+
+			url, encoding = b.Key, b.Encoding
+		}
+
+		registry.AddEncodedArtifact(runID, artifactID, url, encoding, tags...)
+	}
 
 	err = c.Redis.UpdateModelRegistry(ctx, registry)
 	if err != nil {
@@ -106,6 +140,29 @@ func (c *Controller) AddArtifactToRegistry(ctx context.Context, registryName str
 	return nil
 }
 
+// acquireModelEntryFiles references the blobs of the files of a directory
+// artifact from the model entry version of a registry, and returns them.
+func (c *Controller) acquireModelEntryFiles(ctx context.Context, registryName string, version int,
+	runID, artifactID string,
+) ([]types.ArtifactFile, error) {
+	files, err := c.Redis.ArtifactFiles(ctx, runID, artifactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed pulling artifact files: %w", err)
+	}
+
+	for i, f := range files {
+		b, err := c.Redis.AcquireBlob(ctx, c.Redis.ModelEntryFileRef(registryName, version, f.Path),
+			types.Blob{SHA256: f.SHA256, Key: f.S3Key, Size: f.Size, Encoding: f.Encoding})
+		if err != nil {
+			return nil, fmt.Errorf("failed referencing model content: %w", err)
+		}
+
+		files[i].S3Key, files[i].Encoding = b.Key, b.Encoding
+	}
+
+	return files, nil
+}
+
 // TagModel tags a model entry of a registry with the specified tag.
 func (c *Controller) TagModel(ctx context.Context, registryName string, version int, tags ...string) error {
 	registry, err := c.Redis.ModelRegistry(ctx, registryName)
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// DefaultPresignTTL how long pre-signed URLs are valid for, when
+// Controller.PresignTTL is unset.
+const DefaultPresignTTL = 15 * time.Minute
+
+func (c *Controller) presignTTL() time.Duration {
+	if c.PresignTTL <= 0 {
+		return DefaultPresignTTL
+	}
+
+	return c.PresignTTL
+}
+
+func (c *Controller) presignEnabled() error {
+	if !c.PresignedURLs {
+		return types.NewInvalidInputErr("pre-signed urls are disabled")
+	}
+
+	if c.S3 == nil {
+		return types.NewInternalErr("object store is not configured")
+	}
+
+	return nil
+}
+
+// ArtifactDownloadURL pre-signs a download of an artifact, so its content
+// is transferred straight from the object store.
+func (c *Controller) ArtifactDownloadURL(ctx context.Context, runID, artifact string,
+) (*types.SavedArtifact, *types.PresignedRequest, error) {
+	if err := c.presignEnabled(); err != nil {
+		return nil, nil, err
+	}
+
+	a, err := c.Redis.Artifact(ctx, runID, artifact)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := singleFileArtifact(a); err != nil {
+		return nil, nil, err
+	}
+
+	req, err := c.S3.PresignGet(ctx, a.S3Key, a.Name, c.presignTTL())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &a, &req, nil
+}
+
+// TaggedModelDownloadURL pre-signs a download of the model entry of a
+// registry with tag.
+func (c *Controller) TaggedModelDownloadURL(ctx context.Context, registryName, tag string,
+) (*types.ModelEntry, *types.PresignedRequest, error) {
+	if err := c.presignEnabled(); err != nil {
+		return nil, nil, err
+	}
+
+	entry, err := c.TaggedModel(ctx, registryName, tag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(entry.Files) > 0 {
+		return nil, nil, types.NewBadRequest(fmt.Sprintf("model <%s:%s> is a directory, stream it as an archive",
+			registryName, tag))
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s", registryName, tag, strings.ReplaceAll(entry.URL, "/", "_"))
+
+	req, err := c.S3.PresignGet(ctx, entry.URL, filename, c.presignTTL())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &entry, &req, nil
+}
+
+// ArtifactUploadURL starts an upload of an artifact of size bytes to a run
+// through a pre-signed PUT url. The object store rejects content that does
+// not have the hex encoded SHA-256 sha256, required for pre-signed uploads.
+// The artifact is saved to the run once CompleteArtifactUpload is called.
+// When the content is already stored as an artifact of the run the session
+// is deduplicated, and no request is returned as there is nothing to upload.
+func (c *Controller) ArtifactUploadURL(ctx context.Context, runID, name, contentType string,
+	size int64, sha256 string,
+) (*types.UploadSession, *types.PresignedRequest, error) {
+	if err := c.presignEnabled(); err != nil {
+		return nil, nil, err
+	}
+
+	if sha256 == "" {
+		return nil, nil, types.NewBadRequest("sha256 is required for pre-signed uploads")
+	}
+
+	s, err := c.newUploadSession(ctx, runID, name, contentType, size, sha256)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ok, err := c.deduplicateUpload(ctx, s); ok || err != nil {
+		return s, nil, err
+	}
+
+	key, req, err := c.S3.PresignPut(ctx, name, size, sha256, c.presignTTL())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.S3Key, s.Presigned = key, true
+
+	err = c.Redis.CreateUploadSession(ctx, *s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s, &req, nil
+}
+
+// completePresignedUpload saves the artifact of a pre-signed upload once
+// its content is in the object store. The object store checked the content
+// against the session's SHA-256 when it was uploaded.
+func (c *Controller) completePresignedUpload(ctx context.Context, s *types.UploadSession,
+) (*types.SavedArtifact, error) {
+	size, err := c.S3.ObjectSize(ctx, s.S3Key)
+	if errors.Is(err, types.ErrNotFound) {
+		return nil, types.NewInvalidInputErr(fmt.Sprintf("upload <%s> is incomplete: content was not uploaded", s.ID))
+	} else if err != nil {
+		return nil, err
+	}
+
+	if size != s.Size {
+		err = types.NewBadRequest(fmt.Sprintf("upload <%s> received %d bytes, %d were declared", s.ID, size, s.Size))
+
+		return nil, errors.Join(err, c.abortUpload(ctx, s))
+	}
+
+	if err := c.uploadedArtifactIsNew(ctx, s); err != nil {
+		return nil, err
+	}
+
+	return c.saveUploadedArtifact(ctx, s, types.ArtifactDigest{Size: size, SHA256: s.SHA256})
+}
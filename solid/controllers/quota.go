@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// RunStorageUsage returns the storage used by the artifacts of a run, along
+// with its quota.
+func (c *Controller) RunStorageUsage(ctx context.Context, runID string) (*types.StorageUsage, error) {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	usage, err := c.Redis.RunStorageUsage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	usage.Quota = c.StorageQuota.Run
+
+	return &usage, nil
+}
+
+// ExperimentStorageUsage returns the storage used by the artifacts of the
+// runs of an experiment, along with its quota.
+func (c *Controller) ExperimentStorageUsage(ctx context.Context, expID string) (*types.StorageUsage, error) {
+	id := types.NormalizeID(expID)
+
+	if err := c.Redis.ExpExists(ctx, id); err != nil {
+		return nil, err
+	}
+
+	usage, err := c.Redis.ExpStorageUsage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	usage.Quota = c.StorageQuota.Experiment
+
+	return &usage, nil
+}
+
+// ArtifactAllowance returns the size in bytes of the largest artifact that
+// may currently be saved to a run, -1 when unlimited, so uploads streaming
+// more than that can be rejected before their content is stored.
+func (c *Controller) ArtifactAllowance(ctx context.Context, runID string) (int64, error) {
+	id := types.NormalizeID(runID)
+
+	exps, err := c.Redis.RunsExperiment(ctx, []string{id})
+	if err != nil {
+		return 0, err
+	}
+
+	expID, ok := exps[id]
+	if !ok {
+		return 0, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	run, err := c.Redis.RunStorageUsage(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	exp, err := c.Redis.ExpStorageUsage(ctx, expID)
+	if err != nil {
+		return 0, err
+	}
+
+	run.Quota, exp.Quota = c.StorageQuota.Run, c.StorageQuota.Experiment
+
+	return c.StorageQuota.ArtifactAllowance(run, exp), nil
+}
+
+// checkArtifactAllowance rejects an artifact of size bytes that cannot be
+// saved to a run with an ErrResourceExhausted error, see ArtifactAllowance.
+func (c *Controller) checkArtifactAllowance(ctx context.Context, runID string, size int64) error {
+	if err := c.StorageQuota.CheckArtifactSize(size); err != nil {
+		return err
+	}
+
+	allowance, err := c.ArtifactAllowance(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	if allowance >= 0 && size > allowance {
+		return types.NewResourceExhaustedErr(fmt.Sprintf(
+			"artifact of %d bytes exceeds the %d bytes left in the storage quota of run <%s>", size, allowance, runID))
+	}
+
+	return nil
+}
+
+// reserveArtifactStorage adds artifacts of sizes bytes to the storage usage
+// of a run and of its experiment. Artifacts larger than the maximum artifact
+// size, or taking the run or its experiment over its quota, are rejected
+// with an ErrResourceExhausted error and nothing is reserved.
+func (c *Controller) reserveArtifactStorage(ctx context.Context, runID string, sizes ...int64) error {
+	var total int64
+
+	for _, size := range sizes {
+		if err := c.StorageQuota.CheckArtifactSize(size); err != nil {
+			return err
+		}
+
+		total += size
+	}
+
+	return c.Redis.ReserveStorage(ctx, runID, total, len(sizes), c.StorageQuota)
+}
+
+// releaseArtifactStorage removes artifacts of sizes bytes, reserved but
+// not saved, from the storage usage of a run and of its experiment.
+func (c *Controller) releaseArtifactStorage(ctx context.Context, runID string, sizes ...int64) error {
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	var total int64
+
+	for _, size := range sizes {
+		total += size
+	}
+
+	return c.Redis.ReleaseStorage(ctx, runID, total, len(sizes))
+}
+
+// unsavedArtifactSizes returns the sizes of the artifacts of as missing
+// from saved.
+func unsavedArtifactSizes(as []types.Artifact, saved []types.SavedArtifact) []int64 {
+	names := make(map[string]struct{}, len(saved))
+
+	for _, a := range saved {
+		names[a.Name] = struct{}{}
+	}
+
+	sizes := make([]int64, 0)
+
+	for _, a := range as {
+		if _, ok := names[a.Name()]; !ok {
+			sizes = append(sizes, a.Digest().Size)
+		}
+	}
+
+	return sizes
+}
+
+func artifactSizes(as []types.Artifact) []int64 {
+	sizes := make([]int64, len(as))
+
+	for i, a := range as {
+		sizes[i] = a.Digest().Size
+	}
+
+	return sizes
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -8,10 +9,15 @@ import (
 	"log"
 	"maps"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/zeddo123/mlsolid/solid/types"
 )
 
+// CreateRun saves a new run. A run nested under a parent run (run.ParentID
+// set) must belong to its parent's experiment; it inherits it when its own
+// experiment id is empty.
 func (c *Controller) CreateRun(ctx context.Context, run types.Run) error {
 	ok, err := c.Redis.RunExists(ctx, run.Name)
 	if err != nil {
@@ -22,6 +28,32 @@ func (c *Controller) CreateRun(ctx context.Context, run types.Run) error {
 		return types.NewAlreadyInUseErr(fmt.Sprintf("run id <%s> already in use", run.Name))
 	}
 
+	if run.ParentID != "" {
+		run.ParentID = types.NormalizeID(run.ParentID)
+
+		run.ExperimentID, err = c.parentRunExp(ctx, run)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range run.ArtifactsSlice() {
+		if err := c.StorageQuota.CheckArtifactSize(a.Digest().Size); err != nil {
+			return err
+		}
+	}
+
+	metrics := make(map[string]types.Metric, len(run.Metrics))
+
+	for name, m := range run.Metrics {
+		metrics[name], err = canonicalMetric(m)
+		if err != nil {
+			return err
+		}
+	}
+
+	run.Metrics = metrics
+
 	err = c.Redis.SetRun(ctx, run)
 	if err != nil {
 		return err
@@ -32,17 +64,24 @@ func (c *Controller) CreateRun(ctx context.Context, run types.Run) error {
 			return types.NewInternalErr("object store is not configured")
 		}
 
-		artifacts, uploaderr := c.S3.UploadArtifacts(ctx, run.ArtifactsSlice())
+		as := run.ArtifactsSlice()
+
+		err = c.reserveArtifactStorage(ctx, run.Name, artifactSizes(as)...)
+		if err != nil {
+			return err
+		}
+
+		artifacts, uploaderr := c.storeArtifacts(ctx, run.Name, as)
 		if uploaderr != nil {
 			log.Println("not all artifacts were uploaded", uploaderr)
 		}
 
 		err = c.Redis.SetArtifacts(ctx, run.Name, artifacts)
 		if err != nil {
-			return err
+			return errors.Join(err, c.releaseArtifactStorage(ctx, run.Name, artifactSizes(as)...))
 		}
 
-		return uploaderr
+		return errors.Join(uploaderr, c.releaseArtifactStorage(ctx, run.Name, unsavedArtifactSizes(as, artifacts)...))
 	}
 
 	return nil
@@ -78,6 +117,596 @@ func (c *Controller) Runs(ctx context.Context, ids []string) ([]*types.Run, erro
 	return runs, nil
 }
 
+// UpdateRunStatus moves a run to status, stamping its end time when the
+// status is terminal. Setting a running run to running again acts as a
+// heartbeat. Terminal statuses are final: moving a run out of one returns an
+// ErrInvalidInput error.
+func (c *Controller) UpdateRunStatus(ctx context.Context, runID string, status types.RunStatus) (*types.Run, error) {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	if _, err := types.ParseRunStatus(string(status)); err != nil {
+		return nil, err
+	}
+
+	var end time.Time
+	if status.Terminal() {
+		end = time.Now()
+	}
+
+	err = c.Redis.SetRunStatus(ctx, id, status, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Redis.Run(ctx, id)
+}
+
+// FinishRun ends a run with a terminal status, finished when status is
+// empty.
+func (c *Controller) FinishRun(ctx context.Context, runID string, status types.RunStatus) (*types.Run, error) {
+	if status == "" {
+		status = types.RunFinished
+	}
+
+	if !status.Terminal() {
+		return nil, types.NewBadRequest(fmt.Sprintf("a run cannot finish with status %q", status))
+	}
+
+	return c.UpdateRunStatus(ctx, runID, status)
+}
+
+// ReapStaleRuns marks as failed the running runs that have not heartbeated
+// for longer than timeout, and returns how many were reaped.
+func (c *Controller) ReapStaleRuns(ctx context.Context, timeout time.Duration) (int, error) {
+	before := time.Now().Add(-timeout)
+
+	ids, err := c.Redis.StaleRuns(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	reaped := 0
+
+	for _, id := range ids {
+		ok, err := c.Redis.ReapRun(ctx, id, before)
+		if err != nil {
+			c.Logger.Error().Err(err).Str("run", id).Msg("could not reap stale run")
+
+			continue
+		}
+
+		if ok {
+			reaped++
+		}
+	}
+
+	return reaped, nil
+}
+
+// runReaperInterval how often StartRunReaper looks for stale runs.
+const runReaperInterval = time.Minute
+
+// StartRunReaper periodically fails runs whose client stopped reporting for
+// longer than timeout, until ctx is done.
+func (c *Controller) StartRunReaper(ctx context.Context, timeout time.Duration) {
+	ticker := time.NewTicker(min(runReaperInterval, timeout))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reaped, err := c.ReapStaleRuns(ctx, timeout)
+			if err != nil {
+				c.Logger.Error().Err(err).Msg("could not reap stale runs")
+
+				continue
+			}
+
+			if reaped > 0 {
+				c.Logger.Info().Int("runs", reaped).Msg("marked stale runs as failed")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// DeleteRun deletes a run and the runs nested under it, returning the ids of
+// the deleted runs. Nothing is deleted if an artifact of one of them is
+// registered in a model registry. Artifact objects are removed from S3 in
+// the background, see StartArtifactDeleter.
+func (c *Controller) DeleteRun(ctx context.Context, runID string) ([]string, error) {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	ids, err := c.runTree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, c.deleteRuns(ctx, ids)
+}
+
+// runTree returns runID followed by the ids of all runs nested under it.
+func (c *Controller) runTree(ctx context.Context, runID string) ([]string, error) {
+	ids := []string{runID}
+
+	for i := 0; i < len(ids); i++ {
+		children, err := c.Redis.ChildRunIDs(ctx, ids[i])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range children {
+			if !slices.Contains(ids, child) {
+				ids = append(ids, child)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// deleteRuns deletes ids once it checked none of their artifacts are
+// referenced by a model entry.
+func (c *Controller) deleteRuns(ctx context.Context, ids []string) error {
+	registries, err := c.Redis.ModelRegistries(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		artifacts, err := c.Redis.Artifacts(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range artifacts {
+			if err := artifactInUse(registries, id, a); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if err := c.Redis.DeleteRun(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func artifactInUse(registries []*types.ModelRegistry, runID string, a types.SavedArtifact) error {
+	for _, registry := range registries {
+		if registry == nil {
+			continue
+		}
+
+		for _, entry := range registry.Models {
+			if entry.References(runID, a) {
+				return types.NewAlreadyInUseErr(fmt.Sprintf("artifact <%s> of run <%s> is registered as version %d of <%s>",
+					a.Name, runID, entry.Version, registry.Name))
+			}
+		}
+	}
+
+	return nil
+}
+
+// ArchiveRun hides a run from experiment listings and searches without
+// deleting it. It can still be fetched by id.
+func (c *Controller) ArchiveRun(ctx context.Context, runID string) error {
+	return c.setRunArchived(ctx, runID, true)
+}
+
+// RestoreRun undoes ArchiveRun.
+func (c *Controller) RestoreRun(ctx context.Context, runID string) error {
+	return c.setRunArchived(ctx, runID, false)
+}
+
+func (c *Controller) setRunArchived(ctx context.Context, runID string, archived bool) error {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	return c.Redis.SetRunArchived(ctx, id, archived)
+}
+
+// artifactDeletionBatch how many queued artifacts DeleteQueuedArtifacts
+// deletes at most per call.
+const artifactDeletionBatch = 100
+
+// DeleteQueuedArtifacts deletes from S3 the objects of deleted runs'
+// artifacts, and returns how many were deleted. Objects that could not be
+// deleted stay queued and are retried on the next call.
+func (c *Controller) DeleteQueuedArtifacts(ctx context.Context) (int, error) {
+	keys, err := c.Redis.ArtifactDeletionQueue(ctx, artifactDeletionBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if err := c.S3.DeleteFile(ctx, key); err != nil {
+			c.Logger.Error().Err(err).Str("key", key).Msg("could not delete artifact object")
+
+			continue
+		}
+
+		deleted = append(deleted, key)
+	}
+
+	return len(deleted), c.Redis.AckArtifactDeletion(ctx, deleted...)
+}
+
+// artifactDeletionInterval how often StartArtifactDeleter drains the
+// artifact deletion queue.
+const artifactDeletionInterval = time.Minute
+
+// StartArtifactDeleter periodically deletes the S3 objects of deleted runs'
+// artifacts, until ctx is done.
+func (c *Controller) StartArtifactDeleter(ctx context.Context) {
+	ticker := time.NewTicker(artifactDeletionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := c.DeleteQueuedArtifacts(ctx)
+			if err != nil {
+				c.Logger.Error().Err(err).Msg("could not delete queued artifacts")
+
+				continue
+			}
+
+			if deleted > 0 {
+				c.Logger.Info().Int("artifacts", deleted).Msg("deleted artifacts of deleted runs")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// LogParams logs params on a run. Param names are normalized like metric
+// names. Params are immutable once logged, see store.RedisStore.SetParams.
+func (c *Controller) LogParams(ctx context.Context, runID string, params map[string]types.Param) error {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	normalized := make(map[string]types.Param, len(params))
+
+	for name, p := range params {
+		n := types.NormalizeID(name)
+		if n == "" {
+			return types.NewBadRequest("param name cannot be empty")
+		}
+
+		normalized[n] = p
+	}
+
+	return c.Redis.SetParams(ctx, id, normalized)
+}
+
+// parentRunExp checks the parent of a nested run exists and returns the
+// experiment the run is to be created in.
+func (c *Controller) parentRunExp(ctx context.Context, run types.Run) (string, error) {
+	if run.ParentID == run.Name {
+		return "", types.NewBadRequest(fmt.Sprintf("run <%s> cannot be its own parent", run.Name))
+	}
+
+	parent, err := c.Run(ctx, run.ParentID)
+	if err != nil {
+		return "", err
+	}
+
+	if run.ExperimentID != "" && run.ExperimentID != parent.ExperimentID {
+		return "", types.NewBadRequest(fmt.Sprintf("run <%s> must be in the experiment <%s> of its parent",
+			run.Name, parent.ExperimentID))
+	}
+
+	return parent.ExperimentID, nil
+}
+
+// ChildRuns returns the runs nested under a run (e.g. the trials of a
+// sweep), oldest first.
+func (c *Controller) ChildRuns(ctx context.Context, runID string) ([]*types.Run, error) {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	ids, err := c.Redis.ChildRunIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := c.Redis.Runs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(runs, func(a, b *types.Run) int {
+		return cmp.Or(a.Timestamp.Compare(b.Timestamp), strings.Compare(a.Name, b.Name))
+	})
+
+	return runs, nil
+}
+
+// SetRunTags sets (or overwrites) tags on a run and removes the tags listed
+// in remove, returning the run's resulting tags.
+func (c *Controller) SetRunTags(ctx context.Context, runID string, tags map[string]string,
+	remove []string,
+) (map[string]string, error) {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	normalized, err := types.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, len(remove))
+
+	for i, k := range remove {
+		removed[i], err = types.NormalizeTagKey(k)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = c.Redis.SetRunTags(ctx, id, normalized, removed)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := c.Redis.Run(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return run.Tags, nil
+}
+
+// SetRunNote sets the free-form (markdown) note of a run, replacing any
+// previous note. An empty note clears it.
+func (c *Controller) SetRunNote(ctx context.Context, runID string, note string) error {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	return c.Redis.SetRunNote(ctx, id, note)
+}
+
+// RunsMetric returns the values of a metric logged by each of runIDs,
+// windowed and downsampled as q asks, keyed by run id. The metric is looked
+// up by the exact name it is stored under. Runs that did not log the metric
+// (or that do not exist) are left out.
+func (c *Controller) RunsMetric(ctx context.Context, runIDs []string, metric string,
+	q types.MetricQuery,
+) (map[string]types.Metric, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(runIDs))
+	for i, id := range runIDs {
+		ids[i] = types.NormalizeID(id)
+	}
+
+	metrics, err := c.Redis.RunsMetric(ctx, ids, metric, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, m := range metrics {
+		metrics[id] = types.ApplyMetricQuery(m, q)
+	}
+
+	return metrics, nil
+}
+
+// CompareRuns lays the runs of runIDs side by side, whichever experiments
+// they belong to: their params, metric summaries and the curves of metrics
+// aligned on their steps, see types.RunComparison. Curves are windowed and
+// downsampled per run as q asks; all numeric metrics of the runs are
+// compared when metrics is empty.
+func (c *Controller) CompareRuns(ctx context.Context, runIDs []string, metrics []string,
+	q types.MetricQuery,
+) (*types.RunComparison, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(runIDs))
+
+	for _, id := range runIDs {
+		if id = types.NormalizeID(id); !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) < 2 { //nolint: mnd
+		return nil, types.NewBadRequest("at least two runs are needed for a comparison")
+	}
+
+	exps, err := c.Redis.RunsExperiment(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if _, ok := exps[id]; !ok {
+			return nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+		}
+	}
+
+	params, err := c.Redis.RunsParams(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := c.Redis.RunsMetricSummaries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// metrics are compared under the exact names they are stored, and
+	// summarized, under
+	names := slices.Clone(metrics)
+
+	if len(names) == 0 {
+		for _, s := range summaries {
+			names = append(names, slices.Collect(maps.Keys(s))...)
+		}
+
+		slices.Sort(names)
+		names = slices.Compact(names)
+	}
+
+	curves := make(map[string]types.AlignedCurve, len(names))
+
+	for _, name := range names {
+		ms, err := c.RunsMetric(ctx, ids, name, q)
+		if err != nil {
+			return nil, err
+		}
+
+		curves[name] = types.AlignCurves(ms)
+	}
+
+	return &types.RunComparison{
+		RunIDs:      ids,
+		Experiments: exps,
+		Params:      types.CompareParams(ids, params),
+		Summaries:   summaries,
+		Curves:      curves,
+	}, nil
+}
+
+// metricDiscoveryInterval bounds how long RunMetricEvents blocks when it
+// follows all metrics of a run, so metrics logged for the first time while
+// it waits are picked up by the next call.
+const metricDiscoveryInterval = 2 * time.Second
+
+// RunMetricEvents pulls the values logged to the metrics of a run past
+// cursor, in the order they were written, and advances cursor past them.
+// Only metrics are followed, all of them when empty. When no value is
+// available yet it waits up to block for new ones; a negative block never
+// waits. done is set once the run has ended and all its values were read.
+func (c *Controller) RunMetricEvents(ctx context.Context, runID string, metrics []string,
+	cursor *types.MetricCursor, block time.Duration,
+) ([]types.MetricEvent, bool, error) {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !ok {
+		return nil, false, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	// metric streams are keyed by the exact names metrics are stored under
+	names := slices.Clone(metrics)
+
+	if len(names) == 0 {
+		names, err = c.Redis.RunMetricNames(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+
+		block = min(block, metricDiscoveryInterval)
+	}
+
+	// read before the values, so values logged right before the run ended
+	// are still read before done is reported
+	status, err := c.Redis.RunStatus(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if status.Terminal() {
+		block = -1
+	}
+
+	if len(names) == 0 {
+		if block > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(block):
+			}
+		}
+
+		return []types.MetricEvent{}, status.Terminal(), nil
+	}
+
+	events, err := c.Redis.MetricEvents(ctx, id, names, cursor, block)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cursor.Advance(events)
+
+	return events, len(events) == 0 && status.Terminal(), nil
+}
+
 func (c *Controller) AddMetrics(ctx context.Context, runID string, m []types.Metric) error {
 	ok, err := c.Redis.RunExists(ctx, types.NormalizeID(runID))
 	if err != nil {
@@ -91,12 +720,55 @@ func (c *Controller) AddMetrics(ctx context.Context, runID string, m []types.Met
 	mapping := make(map[string]types.Metric)
 
 	for _, m := range m {
+		m, err := canonicalMetric(m)
+		if err != nil {
+			return err
+		}
+
 		mapping[m.Name()] = m
 	}
 
-	err = c.Redis.SetMetrics(ctx, runID, mapping)
+	// values must keep the kind of the metric stream they are appended to,
+	// see store.RedisStore.SetMetrics
+	return c.Redis.SetMetrics(ctx, runID, mapping)
+}
+
+// canonicalMetric validates a metric, and converts its values to the types
+// they are stored as (see types.CanonicalMetric).
+func canonicalMetric(m types.Metric) (types.Metric, error) { //nolint: ireturn
+	if m == nil {
+		return nil, types.NewBadRequest("metric cannot be empty")
+	}
+
+	m, err := types.CanonicalMetric(m)
 	if err != nil {
-		return types.NewInternalErr("could not set metrics")
+		return nil, err
+	}
+
+	if err := validateMetric(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// validateMetric checks a metric holds values of a supported kind, and
+// that its histograms are well-formed.
+func validateMetric(m types.Metric) error {
+	if m == nil {
+		return types.NewBadRequest("metric cannot be empty")
+	}
+
+	if _, ok := types.MetricKind(m); !ok {
+		return types.NewBadRequest(fmt.Sprintf("metric %q holds values of an unsupported type", m.Name()))
+	}
+
+	for _, v := range m.ValsToCommit() {
+		if h, ok := v.(types.Histogram); ok {
+			if err := h.Validate(); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -126,17 +798,25 @@ func (c *Controller) AddArtifacts(ctx context.Context, runID string, as []types.
 		return types.NewInternalErr("object store is not configured")
 	}
 
-	artifacts, uploadErr := c.S3.UploadArtifacts(ctx, toUpload)
-
-	err := c.Redis.SetArtifacts(ctx, runID, artifacts)
+	// the storage of the artifacts is reserved before they are uploaded,
+	// and released for the ones that could not be saved
+	err := c.reserveArtifactStorage(ctx, runID, artifactSizes(toUpload)...)
 	if err != nil {
 		return err
 	}
 
-	return uploadErr
+	artifacts, uploadErr := c.storeArtifacts(ctx, runID, toUpload)
+
+	err = c.Redis.SetArtifacts(ctx, runID, artifacts)
+	if err != nil {
+		return errors.Join(err, c.releaseArtifactStorage(ctx, runID, artifactSizes(toUpload)...))
+	}
+
+	return errors.Join(uploadErr, c.releaseArtifactStorage(ctx, runID, unsavedArtifactSizes(toUpload, artifacts)...))
 }
 
-// Artifact fetches an artifact and returns a Reader to its content.
+// Artifact fetches an artifact and returns a Reader to its content,
+// decompressed when it is stored compressed.
 func (c *Controller) Artifact(ctx context.Context, runID string,
 	artifact string,
 ) (*types.SavedArtifact, io.ReadCloser, error) {
@@ -145,7 +825,11 @@ func (c *Controller) Artifact(ctx context.Context, runID string,
 		return nil, nil, err
 	}
 
-	body, err := c.S3.DownloadFile(ctx, a.S3Key)
+	if err := singleFileArtifact(a); err != nil {
+		return nil, nil, err
+	}
+
+	body, err := c.downloadDecoded(ctx, a.S3Key, a.Encoding)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -153,11 +837,72 @@ func (c *Controller) Artifact(ctx context.Context, runID string,
 	return &a, body, nil
 }
 
-// Artifacts returns a Map of runID to artifact ids i.e a list of artifact ids for each run.
+// ArtifactRange fetches an artifact of a run and returns a Reader to the
+// bytes of its content within the range of the Range header rangeHeader,
+// along with the range, and whether ranges of its content are honoured. The
+// whole content is returned, with a nil range, when rangeHeader is empty or
+// ignored, see types.ParseByteRange. Ranges of artifacts saved before their
+// size was recorded are ignored.
+//
+// Compressed content is returned as is when the Accept-Encoding header
+// acceptEncoding accepts its encoding, and decompressed otherwise, the
+// artifact returned then having the identity encoding. Ranges of compressed
+// content are ignored, whether it is decompressed or not.
+func (c *Controller) ArtifactRange(ctx context.Context, runID, artifact, rangeHeader, acceptEncoding string,
+) (*types.SavedArtifact, *types.ByteRange, bool, io.ReadCloser, error) {
+	a, err := c.Redis.Artifact(ctx, runID, artifact)
+	if err != nil {
+		return nil, nil, false, nil, err
+	}
+
+	if err := singleFileArtifact(a); err != nil {
+		return nil, nil, false, nil, err
+	}
+
+	if a.Encoding != types.IdentityEncoding {
+		var body io.ReadCloser
+
+		a.Encoding, body, err = c.downloadAccepted(ctx, a.S3Key, a.Encoding, acceptEncoding)
+		if err != nil {
+			return nil, nil, false, nil, err
+		}
+
+		return &a, nil, false, body, nil
+	}
+
+	var r *types.ByteRange
+
+	// artifacts saved before their digest have no recorded size to range over
+	ranged := a.SHA256 != ""
+
+	if ranged {
+		r, err = types.ParseByteRange(rangeHeader, a.Size)
+		if err != nil {
+			return &a, nil, ranged, nil, err
+		}
+	}
+
+	var body io.ReadCloser
+
+	if r == nil {
+		body, err = c.S3.DownloadFile(ctx, a.S3Key)
+	} else {
+		body, err = c.S3.DownloadRange(ctx, a.S3Key, *r)
+	}
+
+	if err != nil {
+		return nil, nil, false, nil, err
+	}
+
+	return &a, r, ranged, body, nil
+}
+
+// Artifacts returns a Map of runID to the artifacts of each run, sorted by
+// name.
 func (c *Controller) Artifacts(ctx context.Context,
 	runIDs []string,
-) (map[string][]string, error) {
-	out := make(map[string][]string, len(runIDs))
+) (map[string][]types.SavedArtifact, error) {
+	out := make(map[string][]types.SavedArtifact, len(runIDs))
 
 	for _, id := range runIDs {
 		runArtifacts, err := c.Redis.Artifacts(ctx, id)
@@ -165,8 +910,35 @@ func (c *Controller) Artifacts(ctx context.Context,
 			continue
 		}
 
-		out[id] = slices.Collect(maps.Keys(runArtifacts))
+		out[id] = sortedArtifacts(runArtifacts)
 	}
 
 	return out, nil
 }
+
+// RunArtifacts returns the artifacts of a run, sorted by name.
+func (c *Controller) RunArtifacts(ctx context.Context, runID string) ([]types.SavedArtifact, error) {
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	artifacts, err := c.Redis.Artifacts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedArtifacts(artifacts), nil
+}
+
+func sortedArtifacts(artifacts map[string]types.SavedArtifact) []types.SavedArtifact {
+	return slices.SortedFunc(maps.Values(artifacts), func(a, b types.SavedArtifact) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+}
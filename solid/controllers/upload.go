@@ -0,0 +1,464 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+const (
+	// ArtifactPartSize the size of the parts resumable artifact uploads are
+	// split in. S3 requires all parts but the last to be at least 5 MiB.
+	ArtifactPartSize = 16 << 20
+
+	// DefaultArtifactUploadTTL how long an artifact upload session lives
+	// without receiving content, when Controller.ArtifactUploadTTL is unset.
+	DefaultArtifactUploadTTL = 24 * time.Hour
+
+	// uploadReaperInterval how often StartUploadReaper looks for abandoned
+	// uploads.
+	uploadReaperInterval = 10 * time.Minute
+)
+
+func (c *Controller) uploadTTL() time.Duration {
+	if c.ArtifactUploadTTL <= 0 {
+		return DefaultArtifactUploadTTL
+	}
+
+	return c.ArtifactUploadTTL
+}
+
+// InitArtifactUpload starts a resumable upload of an artifact of size bytes
+// to a run, backed by an S3 multipart upload. sha256 is optional; when set
+// the artifact is rejected on completion if its content does not match it,
+// and the session is deduplicated if that content is already stored as an
+// artifact of the run.
+func (c *Controller) InitArtifactUpload(ctx context.Context, runID, name, contentType string,
+	size int64, sha256 string,
+) (*types.UploadSession, error) {
+	s, err := c.newUploadSession(ctx, runID, name, contentType, size, sha256)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok, err := c.deduplicateUpload(ctx, s); ok || err != nil {
+		return s, err
+	}
+
+	mp, err := c.S3.CreateMultipartUpload(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.S3Key, s.S3UploadID = mp.Key, mp.UploadID
+
+	err = c.Redis.CreateUploadSession(ctx, *s)
+	if err != nil {
+		c.abortMultipartUpload(ctx, mp.Key, mp.UploadID)
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// newUploadSession validates the upload of an artifact to a run and
+// returns its session, without an object in S3 yet.
+func (c *Controller) newUploadSession(ctx context.Context, runID, name, contentType string,
+	size int64, sha256 string,
+) (*types.UploadSession, error) {
+	if name == "" {
+		return nil, types.NewBadRequest("artifact name is required")
+	}
+
+	if !types.IsValidContentType(contentType) {
+		return nil, types.NewBadRequest("unknown content type for artifact")
+	}
+
+	if size <= 0 {
+		return nil, types.NewBadRequest("artifact size must be positive")
+	}
+
+	if sha256 != "" {
+		if err := types.ValidateSHA256(sha256); err != nil {
+			return nil, err
+		}
+	}
+
+	id := types.NormalizeID(runID)
+
+	ok, err := c.Redis.RunExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", id))
+	}
+
+	err = c.Redis.ArtifactExist(ctx, id, name)
+	if err == nil {
+		return nil, types.NewAlreadyInUseErr(fmt.Sprintf("artifact <%s> of run <%s> already exists", name, id))
+	} else if !errors.Is(err, types.ErrNotFound) {
+		return nil, err
+	}
+
+	if c.S3 == nil {
+		return nil, types.NewInternalErr("object store is not configured")
+	}
+
+	// uploads the run has no storage left for are rejected before any
+	// content is sent
+	if err := c.checkArtifactAllowance(ctx, id, size); err != nil {
+		return nil, err
+	}
+
+	return &types.UploadSession{
+		ID:           uuid.NewString(),
+		RunID:        id,
+		Name:         name,
+		ContentType:  types.ContentType(contentType),
+		Size:         size,
+		SHA256:       sha256,
+		PartSize:     ArtifactPartSize,
+		Offset:       0,
+		S3Key:        "",
+		S3UploadID:   "",
+		Parts:        []types.UploadedPart{},
+		DigestState:  nil,
+		Presigned:    false,
+		Deduplicated: false,
+		ExpiresAt:    time.Now().Add(c.uploadTTL()),
+	}, nil
+}
+
+// ArtifactUpload returns an artifact upload session, e.g. to find the
+// offset to resume uploading from after a dropped connection.
+func (c *Controller) ArtifactUpload(ctx context.Context, uploadID string) (*types.UploadSession, error) {
+	s, err := c.Redis.UploadSession(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Expired(time.Now()) {
+		return nil, types.NewNotFoundErr(fmt.Sprintf("upload <%s> expired", uploadID))
+	}
+
+	return &s, nil
+}
+
+// UploadArtifactParts uploads the content of an artifact read from r,
+// starting at offset, which must be the offset of the upload session. The
+// content is uploaded in parts of the session's part size; each uploaded
+// part moves the session's offset and expiry forward, so an upload cut
+// short resumes from the last uploaded part. Content of a part that r ends
+// before completing is dropped, and must be sent again.
+func (c *Controller) UploadArtifactParts(ctx context.Context, uploadID string, offset int64,
+	r io.Reader,
+) (*types.UploadSession, error) {
+	s, err := c.ArtifactUpload(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Presigned {
+		return s, types.NewBadRequest(fmt.Sprintf("upload <%s> is uploaded with a pre-signed url", uploadID))
+	}
+
+	if offset != s.Offset {
+		return s, types.NewInvalidInputErr(fmt.Sprintf("upload <%s> resumes at offset %d, got %d",
+			uploadID, s.Offset, offset))
+	}
+
+	digester, err := s.Digester()
+	if err != nil {
+		return s, err
+	}
+
+	buf := make([]byte, s.PartSize)
+
+	for !s.Complete() {
+		number, length := s.NextPart()
+
+		n, err := io.ReadFull(r, buf[:length])
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return s, nil
+		} else if err != nil {
+			return s, fmt.Errorf("could not read part %d of upload <%s>: %w", number, uploadID, err)
+		}
+
+		etag, err := c.S3.UploadPart(ctx, s.S3Key, s.S3UploadID, number, bytes.NewReader(buf[:n]))
+		if err != nil {
+			return s, err
+		}
+
+		_, _ = digester.Write(buf[:n])
+
+		state, err := digester.State()
+		if err != nil {
+			return s, err
+		}
+
+		next := *s
+		next.Parts = append(next.Parts, types.UploadedPart{Number: number, ETag: etag})
+		next.Offset += int64(n)
+		next.DigestState = state
+		next.ExpiresAt = time.Now().Add(c.uploadTTL())
+
+		err = c.Redis.CommitUploadPart(ctx, next, s.Offset)
+		if err != nil {
+			return s, err
+		}
+
+		s = &next
+	}
+
+	n, _ := r.Read(buf[:1])
+	if n > 0 {
+		return s, types.NewBadRequest(fmt.Sprintf("upload <%s> received more than the %d bytes declared", uploadID, s.Size))
+	}
+
+	return s, nil
+}
+
+// CompleteArtifactUpload assembles the uploaded parts of an artifact and
+// saves it to its run. The upload is aborted if the content does not match
+// the SHA-256 sent when it started, or if the artifact was saved by another
+// upload in the meantime. An upload whose artifact would take its run or
+// experiment over its storage quota is rejected with an ErrResourceExhausted
+// error, and can be completed once storage is freed.
+func (c *Controller) CompleteArtifactUpload(ctx context.Context, uploadID string) (*types.SavedArtifact, error) {
+	s, err := c.ArtifactUpload(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.reserveArtifactStorage(ctx, s.RunID, s.Size); err != nil {
+		return nil, err
+	}
+
+	a, err := c.completeArtifactUpload(ctx, s)
+	if a == nil {
+		return nil, errors.Join(err, c.releaseArtifactStorage(ctx, s.RunID, s.Size))
+	}
+
+	return a, err
+}
+
+// completeArtifactUpload saves the artifact of an upload session, see
+// CompleteArtifactUpload.
+func (c *Controller) completeArtifactUpload(ctx context.Context, s *types.UploadSession,
+) (*types.SavedArtifact, error) {
+	if s.Presigned {
+		return c.completePresignedUpload(ctx, s)
+	}
+
+	if s.Deduplicated {
+		return c.completeDeduplicatedUpload(ctx, s)
+	}
+
+	if !s.Complete() {
+		return nil, types.NewInvalidInputErr(fmt.Sprintf("upload <%s> is incomplete: %d of %d bytes uploaded",
+			s.ID, s.Offset, s.Size))
+	}
+
+	digester, err := s.Digester()
+	if err != nil {
+		return nil, err
+	}
+
+	digest := digester.Digest()
+
+	if err := digest.Verify(s.SHA256); err != nil {
+		return nil, errors.Join(err, c.abortUpload(ctx, s))
+	}
+
+	if err := c.uploadedArtifactIsNew(ctx, s); err != nil {
+		return nil, err
+	}
+
+	err = c.S3.CompleteMultipartUpload(ctx, s.S3Key, s.S3UploadID, s.Parts)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.saveUploadedArtifact(ctx, s, digest)
+}
+
+// uploadedArtifactIsNew aborts s if its artifact was saved by another
+// upload in the meantime.
+func (c *Controller) uploadedArtifactIsNew(ctx context.Context, s *types.UploadSession) error {
+	err := c.Redis.ArtifactExist(ctx, s.RunID, s.Name)
+	if err == nil {
+		err = types.NewAlreadyInUseErr(fmt.Sprintf("artifact <%s> of run <%s> already exists", s.Name, s.RunID))
+
+		return errors.Join(err, c.abortUpload(ctx, s))
+	} else if !errors.Is(err, types.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// saveUploadedArtifact saves the artifact of a completed upload session to
+// its run, its content becoming the blob of its SHA-256, and deletes the
+// session.
+func (c *Controller) saveUploadedArtifact(ctx context.Context, s *types.UploadSession,
+	digest types.ArtifactDigest,
+) (*types.SavedArtifact, error) {
+	a := types.SavedArtifact{
+		Name:        s.Name,
+		ContentType: s.ContentType,
+		S3Key:       s.S3Key,
+		Size:        digest.Size,
+		SHA256:      digest.SHA256,
+		MIMEType: c.sniffObject(ctx, types.Blob{SHA256: digest.SHA256, Key: s.S3Key, Size: digest.Size},
+			s.ContentType, s.Name),
+	}
+
+	b, err := c.acquireArtifactBlob(ctx, s.RunID, a)
+	if err != nil {
+		return nil, err
+	}
+
+	a.S3Key, a.Encoding = b.Key, b.Encoding
+
+	err = c.Redis.SetArtifact(ctx, s.RunID, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, c.Redis.DeleteUploadSession(ctx, s.ID)
+}
+
+// AbortArtifactUpload aborts an artifact upload, deleting its uploaded
+// parts.
+func (c *Controller) AbortArtifactUpload(ctx context.Context, uploadID string) error {
+	s, err := c.Redis.UploadSession(ctx, uploadID)
+	if err != nil {
+		return err
+	}
+
+	return c.abortUpload(ctx, &s)
+}
+
+// abortUpload aborts the multipart upload of s, or deletes the object
+// uploaded with its pre-signed url, and deletes the session. Deduplicated
+// sessions have nothing to abort. Multipart
+// uploads that could not be aborted are left to ReapArtifactUploads.
+func (c *Controller) abortUpload(ctx context.Context, s *types.UploadSession) error {
+	switch {
+	case s.Deduplicated:
+		// nothing was uploaded
+	case s.Presigned:
+		if err := c.S3.DeleteFile(ctx, s.S3Key); err != nil {
+			c.Logger.Error().Err(err).Str("key", s.S3Key).Msg("could not delete pre-signed upload")
+		}
+	default:
+		c.abortMultipartUpload(ctx, s.S3Key, s.S3UploadID)
+	}
+
+	return c.Redis.DeleteUploadSession(ctx, s.ID)
+}
+
+func (c *Controller) abortMultipartUpload(ctx context.Context, key, uploadID string) {
+	err := c.S3.AbortMultipartUpload(ctx, key, uploadID)
+	if err != nil {
+		c.Logger.Error().Err(err).Str("key", key).Msg("could not abort multipart upload")
+	}
+}
+
+// ReapArtifactUploads aborts expired upload sessions, as well as multipart
+// uploads older than the upload TTL that no session refers to (e.g. left
+// behind when a session could not be saved), and returns how many uploads
+// were aborted.
+func (c *Controller) ReapArtifactUploads(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	ids, err := c.Redis.ExpiredUploadSessions(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	reaped := 0
+
+	for _, id := range ids {
+		s, err := c.Redis.UploadSession(ctx, id)
+		if errors.Is(err, types.ErrNotFound) {
+			// stale index entry
+			err = c.Redis.DeleteUploadSession(ctx, id)
+		} else if err == nil {
+			err = c.abortUpload(ctx, &s)
+			reaped++
+		}
+
+		if err != nil {
+			c.Logger.Error().Err(err).Str("upload", id).Msg("could not reap expired upload")
+		}
+	}
+
+	sessions, err := c.Redis.UploadSessions(ctx)
+	if err != nil {
+		return reaped, err
+	}
+
+	live := make(map[string]struct{}, len(sessions))
+	for _, s := range sessions {
+		live[s.S3UploadID] = struct{}{}
+	}
+
+	uploads, err := c.S3.MultipartUploads(ctx)
+	if err != nil {
+		return reaped, err
+	}
+
+	before := now.Add(-c.uploadTTL())
+
+	for _, u := range uploads {
+		if _, ok := live[u.UploadID]; ok || !u.Initiated.Before(before) {
+			continue
+		}
+
+		err := c.S3.AbortMultipartUpload(ctx, u.Key, u.UploadID)
+		if err != nil {
+			c.Logger.Error().Err(err).Str("key", u.Key).Msg("could not abort abandoned multipart upload")
+
+			continue
+		}
+
+		reaped++
+	}
+
+	return reaped, nil
+}
+
+// StartUploadReaper periodically aborts abandoned artifact uploads, see
+// ReapArtifactUploads, until ctx is done.
+func (c *Controller) StartUploadReaper(ctx context.Context) {
+	ticker := time.NewTicker(uploadReaperInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reaped, err := c.ReapArtifactUploads(ctx)
+			if err != nil {
+				c.Logger.Error().Err(err).Msg("could not reap abandoned uploads")
+
+				continue
+			}
+
+			if reaped > 0 {
+				c.Logger.Info().Int("uploads", reaped).Msg("aborted abandoned artifact uploads")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
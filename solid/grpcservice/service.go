@@ -2,6 +2,7 @@
 package grpcservice
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -121,7 +122,10 @@ func (s *Service) Experiment(ctx context.Context,
 ) (*mlsolidv1.ExperimentResponse, error) {
 	id := req.GetExpId()
 
-	runIDs, err := s.Controller.ExpRuns(ctx, id)
+	runIDs, err := s.Controller.ExpRunsMatching(ctx, id, types.RunFilter{
+		Params: req.GetParamFilters(),
+		Tags:   req.GetTagFilters(),
+	})
 	if err != nil {
 		return nil, ParseError(err)
 	}
@@ -134,9 +138,44 @@ func (s *Service) Experiment(ctx context.Context,
 	return &mlsolidv1.ExperimentResponse{
 		RunIds: runIDs,
 		Desc:   info.Description,
+		Info:   ParseExperimentInfo(info),
 	}, nil
 }
 
+func (s *Service) CreateExperiment(ctx context.Context,
+	req *mlsolidv1.CreateExperimentRequest,
+) (*mlsolidv1.CreateExperimentResponse, error) {
+	err := s.Controller.CreateExperiment(ctx, req.GetExperimentId(), types.ExperimentInfo{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Owner:       req.GetOwner(),
+		Tags:        req.GetTags(),
+		CreatedAt:   time.Time{},
+	})
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.CreateExperimentResponse{ExperimentId: types.NormalizeID(req.GetExperimentId())}, nil
+}
+
+func (s *Service) UpdateExperiment(ctx context.Context,
+	req *mlsolidv1.UpdateExperimentRequest,
+) (*mlsolidv1.UpdateExperimentResponse, error) {
+	info, err := s.Controller.UpdateExperiment(ctx, req.GetExperimentId(), types.UpdateExperiment{
+		Name:        req.Name,
+		Description: req.Description,
+		Owner:       req.Owner,
+		Tags:        req.GetTags(),
+		RemoveTags:  req.GetRemoveTags(),
+	})
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.UpdateExperimentResponse{Info: ParseExperimentInfo(info)}, nil
+}
+
 func (s *Service) Experiments(ctx context.Context,
 	_ *mlsolidv1.ExperimentsRequest,
 ) (*mlsolidv1.ExperimentsResponse, error) {
@@ -152,6 +191,7 @@ func (s *Service) CreateRun(ctx context.Context,
 	req *mlsolidv1.CreateRunRequest,
 ) (*mlsolidv1.CreateRunResponse, error) {
 	run := types.NewRun(req.GetRunId(), req.GetExperimentId())
+	run.ParentID = req.GetParentRunId()
 
 	err := s.Controller.CreateRun(ctx, run)
 	if err != nil {
@@ -167,11 +207,60 @@ func (s *Service) Run(ctx context.Context, req *mlsolidv1.RunRequest) (*mlsolidv
 		return nil, ParseError(err)
 	}
 
+	var artifacts []*mlsolidv1.ArtifactInfo
+
+	if req.GetIncludeArtifacts() {
+		as, err := s.Controller.RunArtifacts(ctx, run.Name)
+		if err != nil {
+			return nil, ParseError(err)
+		}
+
+		artifacts = ParseArtifactInfos(as)
+	}
+
 	return &mlsolidv1.RunResponse{
-		RunId:        run.Name,
-		ExperimentId: run.ExperimentID,
-		Timestamp:    timestamppb.New(run.Timestamp),
-		Metrics:      ParseMetrics(run.Metrics),
+		RunId:           run.Name,
+		ExperimentId:    run.ExperimentID,
+		Timestamp:       timestamppb.New(run.Timestamp),
+		Metrics:         ParseMetrics(run.Metrics),
+		Status:          ParseRunStatus(run.Status),
+		EndTime:         parseEndTime(run.EndTime),
+		Params:          ParseParams(run.Params),
+		Tags:            run.Tags,
+		Note:            run.Note,
+		ParentRunId:     run.ParentID,
+		ChildRunIds:     run.Children,
+		Archived:        run.Archived,
+		MetricSummaries: ParseMetricSummaries(run.Summaries),
+		Artifacts:       artifacts,
+	}, nil
+}
+
+func (s *Service) UpdateRunStatus(ctx context.Context,
+	req *mlsolidv1.UpdateRunStatusRequest,
+) (*mlsolidv1.UpdateRunStatusResponse, error) {
+	run, err := s.Controller.UpdateRunStatus(ctx, req.GetRunId(), parseGrpcRunStatus(req.GetStatus()))
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.UpdateRunStatusResponse{
+		Status:  ParseRunStatus(run.Status),
+		EndTime: parseEndTime(run.EndTime),
+	}, nil
+}
+
+func (s *Service) FinishRun(ctx context.Context,
+	req *mlsolidv1.FinishRunRequest,
+) (*mlsolidv1.FinishRunResponse, error) {
+	run, err := s.Controller.FinishRun(ctx, req.GetRunId(), parseGrpcRunStatus(req.GetStatus()))
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.FinishRunResponse{
+		Status:  ParseRunStatus(run.Status),
+		EndTime: parseEndTime(run.EndTime),
 	}, nil
 }
 
@@ -184,10 +273,56 @@ func (s *Service) Runs(ctx context.Context, req *mlsolidv1.RunsRequest) (*mlsoli
 	return NewRunsResponse(runs), nil
 }
 
+// defaultSearchLimit page size of SearchRuns when the request sets none.
+const defaultSearchLimit = 50
+
+func (s *Service) SearchRuns(ctx context.Context,
+	req *mlsolidv1.SearchRunsRequest,
+) (*mlsolidv1.SearchRunsResponse, error) {
+	limit := req.GetLimit()
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	q, err := types.NewRunQuery(req.GetExperimentIds(), req.GetFilter(), req.GetOrderBy(), req.GetCursor(), limit)
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	runs, next, err := s.Controller.SearchRuns(ctx, q)
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.SearchRunsResponse{
+		Runs:       NewRunsResponse(runs).GetRuns(),
+		NextCursor: next,
+	}, nil
+}
+
+func (s *Service) ChildRuns(ctx context.Context,
+	req *mlsolidv1.ChildRunsRequest,
+) (*mlsolidv1.ChildRunsResponse, error) {
+	runs, err := s.Controller.ChildRuns(ctx, req.GetRunId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.ChildRunsResponse{
+		Runs:       NewRunsResponse(runs).GetRuns(),
+		Aggregates: ParseMetricAggregates(types.AggregateFinalMetrics(runs)),
+	}, nil
+}
+
 func (s *Service) AddMetrics(ctx context.Context,
 	req *mlsolidv1.AddMetricsRequest,
 ) (*mlsolidv1.AddMetricsResponse, error) {
-	err := s.Controller.AddMetrics(ctx, req.GetRunId(), parseGrpcMetric(req.GetMetrics()))
+	metrics, err := parseGrpcMetric(req.GetMetrics())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	err = s.Controller.AddMetrics(ctx, req.GetRunId(), metrics)
 	if err != nil {
 		return nil, ParseError(err)
 	}
@@ -195,8 +330,135 @@ func (s *Service) AddMetrics(ctx context.Context,
 	return &mlsolidv1.AddMetricsResponse{Added: true}, nil
 }
 
+func (s *Service) MetricValues(ctx context.Context,
+	req *mlsolidv1.MetricValuesRequest,
+) (*mlsolidv1.MetricValuesResponse, error) {
+	metrics, err := s.Controller.RunsMetric(ctx, req.GetRunIds(), req.GetMetricName(), parseGrpcMetricQuery(req))
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.MetricValuesResponse{Metrics: ParseMetrics(metrics)}, nil
+}
+
+func (s *Service) CompareRuns(ctx context.Context,
+	req *mlsolidv1.CompareRunsRequest,
+) (*mlsolidv1.CompareRunsResponse, error) {
+	comparison, err := s.Controller.CompareRuns(ctx, req.GetRunIds(), req.GetMetricNames(), parseGrpcCompareQuery(req))
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return NewCompareRunsResponse(comparison), nil
+}
+
+func (s *Service) LogParams(ctx context.Context,
+	req *mlsolidv1.LogParamsRequest,
+) (*mlsolidv1.LogParamsResponse, error) {
+	params, err := parseGrpcParams(req.GetParams())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	err = s.Controller.LogParams(ctx, req.GetRunId(), params)
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.LogParamsResponse{Logged: true}, nil
+}
+
+func (s *Service) SetRunTags(ctx context.Context,
+	req *mlsolidv1.SetRunTagsRequest,
+) (*mlsolidv1.SetRunTagsResponse, error) {
+	tags, err := s.Controller.SetRunTags(ctx, req.GetRunId(), req.GetTags(), req.GetRemoveTags())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.SetRunTagsResponse{Tags: tags}, nil
+}
+
+func (s *Service) SetRunNote(ctx context.Context,
+	req *mlsolidv1.SetRunNoteRequest,
+) (*mlsolidv1.SetRunNoteResponse, error) {
+	err := s.Controller.SetRunNote(ctx, req.GetRunId(), req.GetNote())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.SetRunNoteResponse{Set: true}, nil
+}
+
+func (s *Service) DeleteRun(ctx context.Context,
+	req *mlsolidv1.DeleteRunRequest,
+) (*mlsolidv1.DeleteRunResponse, error) {
+	ids, err := s.Controller.DeleteRun(ctx, req.GetRunId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.DeleteRunResponse{DeletedRunIds: ids}, nil
+}
+
+func (s *Service) ArchiveRun(ctx context.Context,
+	req *mlsolidv1.ArchiveRunRequest,
+) (*mlsolidv1.ArchiveRunResponse, error) {
+	err := s.Controller.ArchiveRun(ctx, req.GetRunId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.ArchiveRunResponse{Archived: true}, nil
+}
+
+func (s *Service) RestoreRun(ctx context.Context,
+	req *mlsolidv1.RestoreRunRequest,
+) (*mlsolidv1.RestoreRunResponse, error) {
+	err := s.Controller.RestoreRun(ctx, req.GetRunId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.RestoreRunResponse{Restored: true}, nil
+}
+
+func (s *Service) DeleteExperiment(ctx context.Context,
+	req *mlsolidv1.DeleteExperimentRequest,
+) (*mlsolidv1.DeleteExperimentResponse, error) {
+	ids, err := s.Controller.DeleteExperiment(ctx, req.GetExperimentId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.DeleteExperimentResponse{DeletedRunIds: ids}, nil
+}
+
+func (s *Service) ArchiveExperiment(ctx context.Context,
+	req *mlsolidv1.ArchiveExperimentRequest,
+) (*mlsolidv1.ArchiveExperimentResponse, error) {
+	err := s.Controller.ArchiveExperiment(ctx, req.GetExperimentId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.ArchiveExperimentResponse{Archived: true}, nil
+}
+
+func (s *Service) RestoreExperiment(ctx context.Context,
+	req *mlsolidv1.RestoreExperimentRequest,
+) (*mlsolidv1.RestoreExperimentResponse, error) {
+	err := s.Controller.RestoreExperiment(ctx, req.GetExperimentId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.RestoreExperimentResponse{Restored: true}, nil
+}
+
 func (s *Service) Artifact(req *mlsolidv1.ArtifactRequest, stream mlsolidv1grpc.MlsolidService_ArtifactServer) error {
-	artifact, body, err := s.Controller.Artifact(stream.Context(), req.GetRunId(), req.GetArtifactName())
+	artifact, _, _, body, err := s.Controller.ArtifactRange(stream.Context(), req.GetRunId(), req.GetArtifactName(), "",
+		req.GetAcceptEncoding())
 	if err != nil {
 		return ParseError(err)
 	}
@@ -207,9 +469,13 @@ func (s *Service) Artifact(req *mlsolidv1.ArtifactRequest, stream mlsolidv1grpc.
 
 	err = stream.Send(&mlsolidv1.ArtifactResponse{Request: &mlsolidv1.ArtifactResponse_Metadata{
 		Metadata: &mlsolidv1.MetaData{
-			Name:  artifact.Name,
-			Type:  string(artifact.ContentType),
-			RunId: req.GetRunId(),
+			Name:     artifact.Name,
+			Type:     string(artifact.ContentType),
+			RunId:    req.GetRunId(),
+			Sha256:   artifact.SHA256,
+			Size:     uint64(artifact.Size), //nolint: gosec
+			MimeType: artifact.ServedMIMEType(),
+			Encoding: string(artifact.Encoding),
 		},
 	}})
 	if err != nil {
@@ -243,6 +509,39 @@ func (s *Service) Artifact(req *mlsolidv1.ArtifactRequest, stream mlsolidv1grpc.
 	return nil
 }
 
+func (s *Service) RunArtifacts(ctx context.Context,
+	req *mlsolidv1.RunArtifactsRequest,
+) (*mlsolidv1.RunArtifactsResponse, error) {
+	artifacts, err := s.Controller.RunArtifacts(ctx, req.GetRunId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.RunArtifactsResponse{Artifacts: ParseArtifactInfos(artifacts)}, nil
+}
+
+func (s *Service) RunStorageUsage(ctx context.Context,
+	req *mlsolidv1.RunStorageUsageRequest,
+) (*mlsolidv1.StorageUsageResponse, error) {
+	usage, err := s.Controller.RunStorageUsage(ctx, req.GetRunId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return NewStorageUsageResponse(usage, s.Controller.StorageQuota), nil
+}
+
+func (s *Service) ExperimentStorageUsage(ctx context.Context,
+	req *mlsolidv1.ExperimentStorageUsageRequest,
+) (*mlsolidv1.StorageUsageResponse, error) {
+	usage, err := s.Controller.ExperimentStorageUsage(ctx, req.GetExperimentId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return NewStorageUsageResponse(usage, s.Controller.StorageQuota), nil
+}
+
 func (s *Service) AddArtifact(stream mlsolidv1grpc.MlsolidService_AddArtifactServer) error { //nolint: cyclop
 	const MaxBufferSize = 4024
 
@@ -254,6 +553,8 @@ func (s *Service) AddArtifact(stream mlsolidv1grpc.MlsolidService_AddArtifactSer
 
 	var runID string
 
+	var sha256 string
+
 	fs, err := os.CreateTemp("", "artifact_file")
 	if err != nil {
 		return status.Errorf(codes.Internal, "could not create tmp file")
@@ -262,6 +563,17 @@ func (s *Service) AddArtifact(stream mlsolidv1grpc.MlsolidService_AddArtifactSer
 	defer fs.Close()           //nolint: errcheck
 	defer os.Remove(fs.Name()) //nolint: errcheck
 
+	// the digest is computed over what is written to the tmp file, i.e what
+	// is uploaded
+	digester := types.NewDigester()
+	sniffer := types.NewMIMESniffer()
+	w := io.MultiWriter(fs, digester, sniffer)
+
+	// streams going over what the run may still store are rejected before
+	// the rest of their content fills the tmp file
+	allowance := s.Controller.StorageQuota.ArtifactAllowance(types.StorageUsage{}, types.StorageUsage{})
+	received := int64(0)
+
 	for {
 		request, err := stream.Recv()
 		if errors.Is(err, io.EOF) {
@@ -277,18 +589,30 @@ func (s *Service) AddArtifact(stream mlsolidv1grpc.MlsolidService_AddArtifactSer
 
 			runID = metadata.Metadata.GetRunId()
 			artifactName = metadata.Metadata.GetName()
+			sha256 = metadata.Metadata.GetSha256()
 
 			contentType = metadata.Metadata.GetType()
 			if !types.IsValidContentType(contentType) {
 				return status.Error(codes.InvalidArgument, "unknown content type for artifact")
 			}
 
+			allowance, err = s.Controller.ArtifactAllowance(stream.Context(), runID)
+			if err != nil {
+				return ParseError(err)
+			}
+
 		case *mlsolidv1.AddArtifactRequest_Content:
 			content, ok := request.GetRequest().(*mlsolidv1.AddArtifactRequest_Content)
 			if !ok {
 				return status.Errorf(codes.InvalidArgument, "could not read artifact request content")
 			}
 
+			received += int64(len(content.Content.GetContent()))
+			if allowance >= 0 && received > allowance {
+				return status.Errorf(codes.ResourceExhausted,
+					"artifact exceeds the %d bytes run <%s> may still store", allowance, runID)
+			}
+
 			_, err = buf.Write(content.Content.GetContent())
 			if err != nil {
 				return status.Errorf(codes.Internal, "could not write data chunk %v", err)
@@ -296,7 +620,7 @@ func (s *Service) AddArtifact(stream mlsolidv1grpc.MlsolidService_AddArtifactSer
 
 			// when buffer hits max buffer size, write to tmp file
 			if buf.Len() >= MaxBufferSize {
-				_, err := fs.Write(buf.Bytes())
+				_, err := w.Write(buf.Bytes())
 				if err != nil {
 					return status.Errorf(codes.Internal, "could not write to tmp file")
 				}
@@ -307,7 +631,7 @@ func (s *Service) AddArtifact(stream mlsolidv1grpc.MlsolidService_AddArtifactSer
 	}
 
 	// Clean remaining bytes in buffer
-	_, err = fs.Write(buf.Bytes())
+	_, err = w.Write(buf.Bytes())
 	if err != nil {
 		return status.Errorf(codes.Internal, "could not write to tmp file")
 	}
@@ -320,7 +644,15 @@ func (s *Service) AddArtifact(stream mlsolidv1grpc.MlsolidService_AddArtifactSer
 		return status.Errorf(codes.Internal, "failed reading tmp file")
 	}
 
-	artifact, err := types.NewArtifact(artifactName, contentType, fs)
+	digest := digester.Digest()
+
+	err = digest.Verify(sha256)
+	if err != nil {
+		return ParseError(err)
+	}
+
+	artifact, err := types.NewSniffedArtifact(artifactName, contentType, fs, digest,
+		sniffer.MIMEType(types.ContentType(contentType), artifactName))
 	if err != nil {
 		return ParseError(err)
 	}
@@ -333,8 +665,293 @@ func (s *Service) AddArtifact(stream mlsolidv1grpc.MlsolidService_AddArtifactSer
 	return stream.SendAndClose(&mlsolidv1.AddArtifactResponse{ //nolint: exhaustruct
 		Name:   artifactName,
 		Status: mlsolidv1.Status_STATUS_SUCCESS,
-		Size:   uint64(buf.Len()), //nolint: gosec
+		Size:   uint64(digest.Size), //nolint: gosec
+		Sha256: digest.SHA256,
+	})
+}
+
+func (s *Service) InitArtifactUpload(ctx context.Context,
+	req *mlsolidv1.InitArtifactUploadRequest,
+) (*mlsolidv1.ArtifactUploadResponse, error) {
+	session, err := s.Controller.InitArtifactUpload(ctx, req.GetRunId(), req.GetName(), req.GetType(),
+		int64(req.GetSize()), req.GetSha256()) //nolint: gosec
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return NewArtifactUploadResponse(session), nil
+}
+
+// contentChunkSize the size of the content chunks streamed by
+// contentWriter.
+const contentChunkSize = 64 << 10
+
+// contentReader reads the content chunks of a client stream following its
+// header, next returning the next chunk.
+type contentReader struct {
+	next func() (*mlsolidv1.Content, error)
+	buf  []byte
+}
+
+func (r *contentReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		content, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+
+		r.buf = content.GetContent()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+// contentWriter sends what is written to it as content chunks of a server
+// stream.
+type contentWriter struct {
+	send func(*mlsolidv1.Content) error
+}
+
+func (w contentWriter) Write(p []byte) (int, error) {
+	if err := w.send(&mlsolidv1.Content{Content: p}); err != nil {
+		return 0, status.Error(codes.Internal, "could not send chunk to client")
+	}
+
+	return len(p), nil
+}
+
+// newContentWriter returns a writer buffering content into chunks of
+// contentChunkSize bytes sent with send; it must be flushed once done.
+func newContentWriter(send func(*mlsolidv1.Content) error) *bufio.Writer {
+	return bufio.NewWriterSize(contentWriter{send: send}, contentChunkSize)
+}
+
+func (s *Service) UploadArtifactParts(stream mlsolidv1grpc.MlsolidService_UploadArtifactPartsServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "could not read upload header")
+	}
+
+	header, ok := req.GetRequest().(*mlsolidv1.UploadArtifactPartsRequest_Header)
+	if !ok {
+		return status.Error(codes.InvalidArgument, "upload header must be sent first")
+	}
+
+	r := &contentReader{next: func() (*mlsolidv1.Content, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err //nolint: wrapcheck
+		}
+
+		content, ok := req.GetRequest().(*mlsolidv1.UploadArtifactPartsRequest_Content)
+		if !ok {
+			return nil, types.NewBadRequest("upload header can only be sent first")
+		}
+
+		return content.Content, nil
+	}, buf: nil}
+
+	session, err := s.Controller.UploadArtifactParts(stream.Context(), header.Header.GetUploadId(),
+		int64(header.Header.GetOffset()), r) //nolint: gosec
+	if err != nil {
+		return ParseError(err)
+	}
+
+	return stream.SendAndClose(NewArtifactUploadResponse(session))
+}
+
+func (s *Service) ArtifactUpload(ctx context.Context,
+	req *mlsolidv1.ArtifactUploadRequest,
+) (*mlsolidv1.ArtifactUploadResponse, error) {
+	session, err := s.Controller.ArtifactUpload(ctx, req.GetUploadId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return NewArtifactUploadResponse(session), nil
+}
+
+func (s *Service) CompleteArtifactUpload(ctx context.Context,
+	req *mlsolidv1.CompleteArtifactUploadRequest,
+) (*mlsolidv1.AddArtifactResponse, error) {
+	artifact, err := s.Controller.CompleteArtifactUpload(ctx, req.GetUploadId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.AddArtifactResponse{ //nolint: exhaustruct
+		Name:   artifact.Name,
+		Status: mlsolidv1.Status_STATUS_SUCCESS,
+		Size:   uint64(artifact.Size), //nolint: gosec
+		Sha256: artifact.SHA256,
+	}, nil
+}
+
+func (s *Service) AbortArtifactUpload(ctx context.Context,
+	req *mlsolidv1.AbortArtifactUploadRequest,
+) (*mlsolidv1.AbortArtifactUploadResponse, error) {
+	err := s.Controller.AbortArtifactUpload(ctx, req.GetUploadId())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.AbortArtifactUploadResponse{Aborted: true}, nil
+}
+
+func (s *Service) ArtifactDownloadURL(ctx context.Context,
+	req *mlsolidv1.ArtifactRequest,
+) (*mlsolidv1.ArtifactDownloadURLResponse, error) {
+	artifact, presigned, err := s.Controller.ArtifactDownloadURL(ctx, req.GetRunId(), req.GetArtifactName())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.ArtifactDownloadURLResponse{
+		Metadata: &mlsolidv1.MetaData{
+			Name:   artifact.Name,
+			Type:   string(artifact.ContentType),
+			RunId:  req.GetRunId(),
+			Sha256: artifact.SHA256,
+			Size:   uint64(artifact.Size), //nolint: gosec
+		},
+		Request: ParsePresignedRequest(presigned),
+	}, nil
+}
+
+func (s *Service) ArtifactUploadURL(ctx context.Context,
+	req *mlsolidv1.InitArtifactUploadRequest,
+) (*mlsolidv1.ArtifactUploadURLResponse, error) {
+	session, presigned, err := s.Controller.ArtifactUploadURL(ctx, req.GetRunId(), req.GetName(), req.GetType(),
+		int64(req.GetSize()), req.GetSha256()) //nolint: gosec
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.ArtifactUploadURLResponse{
+		Session: NewArtifactUploadResponse(session).GetSession(),
+		Request: ParsePresignedRequest(presigned),
+	}, nil
+}
+
+func (s *Service) AddDirectoryArtifact(stream mlsolidv1grpc.MlsolidService_AddDirectoryArtifactServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "could not read directory artifact header")
+	}
+
+	header, ok := req.GetRequest().(*mlsolidv1.AddDirectoryArtifactRequest_Header)
+	if !ok {
+		return status.Error(codes.InvalidArgument, "directory artifact header must be sent first")
+	}
+
+	r := &contentReader{next: func() (*mlsolidv1.Content, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err //nolint: wrapcheck
+		}
+
+		content, ok := req.GetRequest().(*mlsolidv1.AddDirectoryArtifactRequest_Content)
+		if !ok {
+			return nil, types.NewBadRequest("directory artifact header can only be sent first")
+		}
+
+		return content.Content, nil
+	}, buf: nil}
+
+	artifact, files, err := s.Controller.AddDirectoryArtifact(stream.Context(), header.Header.GetRunId(),
+		header.Header.GetName(), r)
+	if err != nil {
+		return ParseError(err)
+	}
+
+	return stream.SendAndClose(&mlsolidv1.AddDirectoryArtifactResponse{
+		Name:  artifact.Name,
+		Size:  uint64(artifact.Size), //nolint: gosec
+		Files: ParseArtifactFiles(files),
+	})
+}
+
+func (s *Service) ArtifactFiles(ctx context.Context,
+	req *mlsolidv1.ArtifactRequest,
+) (*mlsolidv1.ArtifactFilesResponse, error) {
+	files, err := s.Controller.ArtifactFiles(ctx, req.GetRunId(), req.GetArtifactName())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.ArtifactFilesResponse{Files: ParseArtifactFiles(files)}, nil
+}
+
+func (s *Service) ArtifactFile(req *mlsolidv1.ArtifactFileRequest,
+	stream mlsolidv1grpc.MlsolidService_ArtifactFileServer,
+) error {
+	file, body, err := s.Controller.ArtifactFile(stream.Context(), req.GetRunId(), req.GetArtifactName(), req.GetPath())
+	if err != nil {
+		return ParseError(err)
+	}
+	defer body.Close()
+
+	err = stream.Send(&mlsolidv1.ArtifactResponse{Request: &mlsolidv1.ArtifactResponse_Metadata{
+		Metadata: &mlsolidv1.MetaData{
+			Name:     file.Path,
+			Type:     string(types.BinaryContentType),
+			RunId:    req.GetRunId(),
+			Sha256:   file.SHA256,
+			Size:     uint64(file.Size), //nolint: gosec
+			MimeType: file.MIMEType,
+		},
+	}})
+	if err != nil {
+		return status.Error(codes.Internal, "could not send metadata of artifact file")
+	}
+
+	w := newContentWriter(func(c *mlsolidv1.Content) error {
+		return stream.Send(&mlsolidv1.ArtifactResponse{Request: &mlsolidv1.ArtifactResponse_Content{Content: c}})
+	})
+
+	if _, err := io.Copy(w, body); err != nil {
+		return status.Error(codes.Internal, "could not send artifact file")
+	}
+
+	return w.Flush() //nolint: wrapcheck
+}
+
+func (s *Service) ArtifactArchive(req *mlsolidv1.ArtifactArchiveRequest,
+	stream mlsolidv1grpc.MlsolidService_ArtifactArchiveServer,
+) error {
+	format, err := types.ParseArchiveFormat(req.GetFormat())
+	if err != nil {
+		return ParseError(err)
+	}
+
+	artifact, files, err := s.Controller.ArtifactArchive(stream.Context(), req.GetRunId(), req.GetArtifactName())
+	if err != nil {
+		return ParseError(err)
+	}
+
+	err = stream.Send(&mlsolidv1.ArtifactResponse{Request: &mlsolidv1.ArtifactResponse_Metadata{
+		Metadata: &mlsolidv1.MetaData{
+			Name:  artifact.Name + "." + string(format),
+			Type:  string(types.DirectoryContentType),
+			RunId: req.GetRunId(),
+		},
+	}})
+	if err != nil {
+		return status.Error(codes.Internal, "could not send metadata of artifact")
+	}
+
+	w := newContentWriter(func(c *mlsolidv1.Content) error {
+		return stream.Send(&mlsolidv1.ArtifactResponse{Request: &mlsolidv1.ArtifactResponse_Content{Content: c}})
 	})
+
+	if err := s.Controller.WriteArchive(stream.Context(), files, format, w); err != nil {
+		return ParseError(err)
+	}
+
+	return w.Flush() //nolint: wrapcheck
 }
 
 func (s *Service) CreateModelRegistry(ctx context.Context,
@@ -388,8 +1005,9 @@ func (s *Service) TaggedModel(ctx context.Context, req *mlsolidv1.TaggedModelReq
 
 	return &mlsolidv1.TaggedModelResponse{
 		Entry: &mlsolidv1.ModelEntry{
-			Url:  entry.URL,
-			Tags: entry.Tags,
+			Url:   entry.URL,
+			Tags:  entry.Tags,
+			Files: ParseArtifactFiles(entry.Files),
 		},
 	}, nil
 }
@@ -402,16 +1020,27 @@ func (s *Service) StreamTaggedModel(req *mlsolidv1.StreamTaggedModelRequest,
 		return ParseError(err)
 	}
 
+	if len(entry.Files) > 0 {
+		return s.streamTaggedDirectoryModel(req, entry, stream)
+	}
+
 	bufferSize := 1024
 	buffer := make([]byte, bufferSize)
 
 	fileName := strings.ReplaceAll(entry.URL, "/", "_")
 
+	encoding, body, err := s.Controller.ModelEntryContent(stream.Context(), entry, req.GetAcceptEncoding())
+	if err != nil {
+		return ParseError(err)
+	}
+	defer body.Close()
+
 	err = stream.Send(&mlsolidv1.StreamTaggedModelResponse{
 		Response: &mlsolidv1.StreamTaggedModelResponse_Metadata{
 			Metadata: &mlsolidv1.MetaData{
-				Name: fmt.Sprintf("%s_%s_%s", req.GetName(), req.GetTag(), fileName),
-				Type: string(types.ModelContentType),
+				Name:     fmt.Sprintf("%s_%s_%s", req.GetName(), req.GetTag(), fileName),
+				Type:     string(types.ModelContentType),
+				Encoding: string(encoding),
 			},
 		},
 	})
@@ -419,12 +1048,6 @@ func (s *Service) StreamTaggedModel(req *mlsolidv1.StreamTaggedModelRequest,
 		return status.Error(codes.Internal, "could not send metadata of model entry")
 	}
 
-	body, err := s.Controller.S3.DownloadFile(stream.Context(), entry.URL)
-	if err != nil {
-		return ParseError(err)
-	}
-	defer body.Close()
-
 	for {
 		n, err := body.Read(buffer)
 		if errors.Is(err, io.EOF) {
@@ -450,6 +1073,59 @@ func (s *Service) StreamTaggedModel(req *mlsolidv1.StreamTaggedModelRequest,
 	return nil
 }
 
+// streamTaggedDirectoryModel streams a model entry registered from a
+// directory artifact as an archive.
+func (s *Service) streamTaggedDirectoryModel(req *mlsolidv1.StreamTaggedModelRequest, entry types.ModelEntry,
+	stream mlsolidv1grpc.MlsolidService_StreamTaggedModelServer,
+) error {
+	format, err := types.ParseArchiveFormat(req.GetFormat())
+	if err != nil {
+		return ParseError(err)
+	}
+
+	err = stream.Send(&mlsolidv1.StreamTaggedModelResponse{
+		Response: &mlsolidv1.StreamTaggedModelResponse_Metadata{
+			Metadata: &mlsolidv1.MetaData{
+				Name: fmt.Sprintf("%s_%s_%s.%s", req.GetName(), req.GetTag(), entry.Name, format),
+				Type: string(types.DirectoryContentType),
+			},
+		},
+	})
+	if err != nil {
+		return status.Error(codes.Internal, "could not send metadata of model entry")
+	}
+
+	w := newContentWriter(func(c *mlsolidv1.Content) error {
+		return stream.Send(&mlsolidv1.StreamTaggedModelResponse{
+			Response: &mlsolidv1.StreamTaggedModelResponse_Content{Content: c},
+		})
+	})
+
+	if err := s.Controller.WriteArchive(stream.Context(), entry.Files, format, w); err != nil {
+		return ParseError(err)
+	}
+
+	return w.Flush() //nolint: wrapcheck
+}
+
+func (s *Service) TaggedModelDownloadURL(ctx context.Context,
+	req *mlsolidv1.TaggedModelRequest,
+) (*mlsolidv1.ArtifactDownloadURLResponse, error) {
+	entry, presigned, err := s.Controller.TaggedModelDownloadURL(ctx, req.GetName(), req.GetTag())
+	if err != nil {
+		return nil, ParseError(err)
+	}
+
+	return &mlsolidv1.ArtifactDownloadURLResponse{
+		Metadata: &mlsolidv1.MetaData{
+			Name:  entry.Name,
+			Type:  string(types.ModelContentType),
+			RunId: entry.Run,
+		},
+		Request: ParsePresignedRequest(presigned),
+	}, nil
+}
+
 func (s *Service) TagModel(ctx context.Context, req *mlsolidv1.TagModelRequest) (*mlsolidv1.TagModelResponse, error) {
 	err := s.Controller.TagModel(ctx, req.GetName(), int(req.GetVersion()), req.GetTags()...)
 	if err != nil {
@@ -683,3 +1359,80 @@ func (s *Service) BestModel(ctx context.Context,
 		BestModels: best,
 	}, nil
 }
+
+// watchRunMetricsBlock bounds how long WatchRunMetrics blocks on the metric
+// streams before checking whether the client is still there.
+const watchRunMetricsBlock = 5 * time.Second
+
+// WatchRunMetrics rpc method.
+func (s *Service) WatchRunMetrics(req *mlsolidv1.WatchRunMetricsRequest,
+	stream mlsolidv1grpc.MlsolidService_WatchRunMetricsServer,
+) error {
+	ctx := stream.Context()
+	cursor := types.NewMetricCursor(req.GetAfterId())
+
+	for {
+		events, done, err := s.Controller.RunMetricEvents(ctx, req.GetRunId(), req.GetMetricNames(),
+			cursor, watchRunMetricsBlock)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			return ParseError(err)
+		}
+
+		for _, e := range events {
+			err := stream.Send(NewWatchRunMetricsResponse(e))
+			if err != nil {
+				return status.Error(codes.Internal, "could not send metric value to client")
+			}
+		}
+
+		if done {
+			err := stream.Send(&mlsolidv1.WatchRunMetricsResponse{End: true}) //nolint: exhaustruct
+			if err != nil {
+				return status.Error(codes.Internal, "could not send metric value to client")
+			}
+
+			return nil
+		}
+	}
+}
+
+// watchBenchmarkRunBlock bounds how long WatchBenchmarkRun blocks on the log
+// stream before checking whether the client is still there.
+const watchBenchmarkRunBlock = 5 * time.Second
+
+// WatchBenchmarkRun rpc method.
+func (s *Service) WatchBenchmarkRun(req *mlsolidv1.WatchBenchmarkRunRequest,
+	stream mlsolidv1grpc.MlsolidService_WatchBenchmarkRunServer,
+) error {
+	ctx := stream.Context()
+	after := req.GetAfterId()
+
+	for {
+		logs, err := s.Controller.BenchRunLogs(ctx, req.GetBenchmarkId(), req.GetRegistry(), req.GetVersion(),
+			after, watchBenchmarkRunBlock)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			return ParseError(err)
+		}
+
+		for _, l := range logs {
+			err := stream.Send(NewWatchBenchmarkRunResponse(l))
+			if err != nil {
+				return status.Error(codes.Internal, "could not send benchmark run log to client")
+			}
+
+			if l.End {
+				return nil
+			}
+
+			after = l.ID
+		}
+	}
+}
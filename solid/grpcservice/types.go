@@ -2,6 +2,9 @@ package grpcservice
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	mlsolidv1 "buf.build/gen/go/zeddo123/mlsolid/protocolbuffers/go/mlsolid/v1"
 	"github.com/zeddo123/mlsolid/solid/types"
@@ -25,6 +28,8 @@ func ParseError(err error) error {
 		return status.Error(codes.NotFound, err.Error())
 	} else if errors.Is(err, types.ErrAlreadyInUse) {
 		return status.Error(codes.AlreadyExists, err.Error())
+	} else if errors.Is(err, types.ErrResourceExhausted) {
+		return status.Error(codes.ResourceExhausted, err.Error())
 	}
 
 	return status.Error(codes.Internal, err.Error())
@@ -40,87 +45,405 @@ func NewRunsResponse(runs []*types.Run) *mlsolidv1.RunsResponse {
 	return &mlsolidv1.RunsResponse{Runs: rs}
 }
 
+// ParseExperimentInfo converts experiment info to its grpc form.
+func ParseExperimentInfo(info types.ExperimentInfo) *mlsolidv1.ExperimentInfo {
+	res := &mlsolidv1.ExperimentInfo{
+		Name:        info.Name,
+		Description: info.Description,
+		Owner:       info.Owner,
+		Tags:        info.Tags,
+		CreatedAt:   nil,
+	}
+
+	if !info.CreatedAt.IsZero() {
+		res.CreatedAt = timestamppb.New(info.CreatedAt)
+	}
+
+	return res
+}
+
+// ParseMetricAggregates converts metric aggregates to their grpc form.
+func ParseMetricAggregates(aggs map[string]types.MetricAggregate) map[string]*mlsolidv1.MetricAggregate {
+	res := make(map[string]*mlsolidv1.MetricAggregate, len(aggs))
+
+	for name, agg := range aggs {
+		res[name] = &mlsolidv1.MetricAggregate{
+			Count:    int64(agg.Count),
+			Mean:     agg.Mean,
+			Min:      agg.Min,
+			Max:      agg.Max,
+			MinRunId: agg.MinRun,
+			MaxRunId: agg.MaxRun,
+		}
+	}
+
+	return res
+}
+
 func parseRun(run *types.Run) *mlsolidv1.Run {
 	return &mlsolidv1.Run{
-		RunId:        run.Name,
-		ExperimentId: run.ExperimentID,
-		Timestamp:    timestamppb.New(run.Timestamp),
-		Metrics:      ParseMetrics(run.Metrics),
+		RunId:           run.Name,
+		ExperimentId:    run.ExperimentID,
+		Timestamp:       timestamppb.New(run.Timestamp),
+		Metrics:         ParseMetrics(run.Metrics),
+		Status:          ParseRunStatus(run.Status),
+		EndTime:         parseEndTime(run.EndTime),
+		Params:          ParseParams(run.Params),
+		Tags:            run.Tags,
+		Note:            run.Note,
+		ParentRunId:     run.ParentID,
+		ChildRunIds:     run.Children,
+		Archived:        run.Archived,
+		MetricSummaries: ParseMetricSummaries(run.Summaries),
 	}
 }
 
-func ParseMetrics(m map[string]types.Metric) map[string]*mlsolidv1.Metric {
-	res := make(map[string]*mlsolidv1.Metric)
+// ParseMetricSummaries converts metric summaries to their grpc counterpart.
+func ParseMetricSummaries(summaries map[string]types.MetricSummary) map[string]*mlsolidv1.MetricSummary {
+	res := make(map[string]*mlsolidv1.MetricSummary, len(summaries))
+
+	for name, s := range summaries {
+		res[name] = &mlsolidv1.MetricSummary{
+			Count:    s.Count,
+			Min:      s.Min,
+			Max:      s.Max,
+			Last:     s.Last,
+			Mean:     s.Mean(),
+			MinStep:  s.MinStep,
+			MaxStep:  s.MaxStep,
+			LastStep: s.LastStep,
+		}
+	}
 
-	for k, v := range m {
-		res[k] = ParseMetric(v)
+	return res
+}
+
+// ParseParams converts run params to their grpc counterpart.
+func ParseParams(params map[string]types.Param) map[string]*mlsolidv1.Param {
+	res := make(map[string]*mlsolidv1.Param, len(params))
+
+	for name, p := range params {
+		switch v := p.Value.(type) {
+		case string:
+			res[name] = &mlsolidv1.Param{Value: &mlsolidv1.Param_Str{Str: v}}
+		case int64:
+			res[name] = &mlsolidv1.Param{Value: &mlsolidv1.Param_Int{Int: v}}
+		case float64:
+			res[name] = &mlsolidv1.Param{Value: &mlsolidv1.Param_Double{Double: v}}
+		case bool:
+			res[name] = &mlsolidv1.Param{Value: &mlsolidv1.Param_Bool{Bool: v}}
+		}
 	}
 
 	return res
 }
 
-func ParseMetric(m types.Metric) *mlsolidv1.Metric {
-	var fn func(v any) *mlsolidv1.Val
+func parseGrpcParams(params map[string]*mlsolidv1.Param) (map[string]types.Param, error) {
+	res := make(map[string]types.Param, len(params))
+
+	for name, p := range params {
+		var v any
+
+		switch val := p.GetValue().(type) {
+		case *mlsolidv1.Param_Str:
+			v = val.Str
+		case *mlsolidv1.Param_Int:
+			v = val.Int
+		case *mlsolidv1.Param_Double:
+			v = val.Double
+		case *mlsolidv1.Param_Bool:
+			v = val.Bool
+		default:
+			return nil, types.NewBadRequest(fmt.Sprintf("param %q has no value", name))
+		}
 
-	switch m.(type) {
-	case *types.GenericMetric[float64]:
-		fn = func(v any) *mlsolidv1.Val {
-			return &mlsolidv1.Val{Val: &mlsolidv1.Val_Double{Double: v.(float64)}}
+		param, err := types.NewParam(v)
+		if err != nil {
+			return nil, err
 		}
-	case *types.GenericMetric[string]:
-		fn = func(v any) *mlsolidv1.Val {
-			return &mlsolidv1.Val{Val: &mlsolidv1.Val_Str{Str: v.(string)}}
+
+		res[name] = param
+	}
+
+	return res, nil
+}
+
+// ParseRunStatus converts a run status to its grpc counterpart.
+func ParseRunStatus(status types.RunStatus) mlsolidv1.RunStatus {
+	switch status {
+	case types.RunRunning:
+		return mlsolidv1.RunStatus_RUN_STATUS_RUNNING
+	case types.RunFinished:
+		return mlsolidv1.RunStatus_RUN_STATUS_FINISHED
+	case types.RunFailed:
+		return mlsolidv1.RunStatus_RUN_STATUS_FAILED
+	case types.RunKilled:
+		return mlsolidv1.RunStatus_RUN_STATUS_KILLED
+	default:
+		return mlsolidv1.RunStatus_RUN_STATUS_UNSPECIFIED
+	}
+}
+
+// parseGrpcRunStatus converts a grpc run status, returning an empty status
+// for RUN_STATUS_UNSPECIFIED.
+func parseGrpcRunStatus(status mlsolidv1.RunStatus) types.RunStatus {
+	switch status {
+	case mlsolidv1.RunStatus_RUN_STATUS_RUNNING:
+		return types.RunRunning
+	case mlsolidv1.RunStatus_RUN_STATUS_FINISHED:
+		return types.RunFinished
+	case mlsolidv1.RunStatus_RUN_STATUS_FAILED:
+		return types.RunFailed
+	case mlsolidv1.RunStatus_RUN_STATUS_KILLED:
+		return types.RunKilled
+	default:
+		return ""
+	}
+}
+
+func parseGrpcMetricQuery(req *mlsolidv1.MetricValuesRequest) types.MetricQuery {
+	q := types.MetricQuery{
+		StartStep:  req.StartStep,
+		EndStep:    req.EndStep,
+		Since:      nil,
+		Until:      nil,
+		MaxPoints:  int(req.GetMaxPoints()),
+		Downsample: types.DownsampleLTTB,
+		LastOnly:   req.GetLastOnly(),
+	}
+
+	if req.GetSince() != nil {
+		since := req.GetSince().AsTime()
+		q.Since = &since
+	}
+
+	if req.GetUntil() != nil {
+		until := req.GetUntil().AsTime()
+		q.Until = &until
+	}
+
+	if req.GetDownsample() == mlsolidv1.DownsampleMethod_DOWNSAMPLE_METHOD_MINMAX {
+		q.Downsample = types.DownsampleMinMax
+	}
+
+	return q
+}
+
+func parseGrpcCompareQuery(req *mlsolidv1.CompareRunsRequest) types.MetricQuery {
+	q := types.MetricQuery{ //nolint: exhaustruct
+		StartStep:  req.StartStep,
+		EndStep:    req.EndStep,
+		MaxPoints:  int(req.GetMaxPoints()),
+		Downsample: types.DownsampleLTTB,
+	}
+
+	if req.GetDownsample() == mlsolidv1.DownsampleMethod_DOWNSAMPLE_METHOD_MINMAX {
+		q.Downsample = types.DownsampleMinMax
+	}
+
+	return q
+}
+
+// NewCompareRunsResponse converts a run comparison to a grpc response.
+func NewCompareRunsResponse(c *types.RunComparison) *mlsolidv1.CompareRunsResponse {
+	res := &mlsolidv1.CompareRunsResponse{
+		RunIds:      c.RunIDs,
+		Experiments: c.Experiments,
+		Params:      make(map[string]*mlsolidv1.ParamComparison, len(c.Params)),
+		Summaries:   make(map[string]*mlsolidv1.RunMetricSummaries, len(c.Summaries)),
+		Curves:      make(map[string]*mlsolidv1.AlignedCurve, len(c.Curves)),
+	}
+
+	for name, p := range c.Params {
+		res.Params[name] = &mlsolidv1.ParamComparison{
+			Values:  ParseParams(p.Values),
+			Differs: p.Differs,
+		}
+	}
+
+	for id, s := range c.Summaries {
+		res.Summaries[id] = &mlsolidv1.RunMetricSummaries{Summaries: ParseMetricSummaries(s)}
+	}
+
+	for name, curve := range c.Curves {
+		aligned := &mlsolidv1.AlignedCurve{
+			Steps:  curve.Steps,
+			Values: make(map[string]*mlsolidv1.CurveValues, len(curve.Values)),
 		}
-	case *types.GenericMetric[int64]:
-		fn = func(v any) *mlsolidv1.Val {
-			return &mlsolidv1.Val{Val: &mlsolidv1.Val_Int{Int: v.(int64)}}
+
+		for id, vals := range curve.Values {
+			values := make([]float64, len(vals))
+
+			for i, v := range vals {
+				values[i] = math.NaN()
+				if v != nil {
+					values[i] = *v
+				}
+			}
+
+			aligned.Values[id] = &mlsolidv1.CurveValues{Values: values}
 		}
+
+		res.Curves[name] = aligned
+	}
+
+	return res
+}
+
+func parseEndTime(end time.Time) *timestamppb.Timestamp {
+	if end.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(end)
+}
+
+func ParseMetrics(m map[string]types.Metric) map[string]*mlsolidv1.Metric {
+	res := make(map[string]*mlsolidv1.Metric)
+
+	for k, v := range m {
+		res[k] = ParseMetric(v)
 	}
 
+	return res
+}
+
+func ParseMetric(m types.Metric) *mlsolidv1.Metric {
 	vals := make([]*mlsolidv1.Val, len(m.Vals()))
 
+	metas := m.Metas()
+
 	for i, val := range m.Vals() {
-		vals[i] = fn(val)
+		vals[i] = parseVal(val, metas[i])
 	}
 
+	kind, _ := types.MetricKind(m)
+
 	return &mlsolidv1.Metric{
-		Name: m.Name(),
-		Vals: vals,
+		Name:      m.Name(),
+		Vals:      vals,
+		ValueType: ParseValueKind(kind),
+	}
+}
+
+// ParseValueKind converts a metric value kind to its grpc counterpart.
+func ParseValueKind(kind types.ValueKind) mlsolidv1.ValueType {
+	switch kind {
+	case types.IntKind:
+		return mlsolidv1.ValueType_VALUE_TYPE_INT
+	case types.FloatKind:
+		return mlsolidv1.ValueType_VALUE_TYPE_DOUBLE
+	case types.StringKind:
+		return mlsolidv1.ValueType_VALUE_TYPE_STRING
+	case types.BoolKind:
+		return mlsolidv1.ValueType_VALUE_TYPE_BOOL
+	case types.HistogramKind:
+		return mlsolidv1.ValueType_VALUE_TYPE_HISTOGRAM
+	case types.VectorKind:
+		return mlsolidv1.ValueType_VALUE_TYPE_VECTOR
+	default:
+		return mlsolidv1.ValueType_VALUE_TYPE_UNSPECIFIED
+	}
+}
+
+func parseGrpcValueType(t mlsolidv1.ValueType) (types.ValueKind, bool) {
+	switch t {
+	case mlsolidv1.ValueType_VALUE_TYPE_INT:
+		return types.IntKind, true
+	case mlsolidv1.ValueType_VALUE_TYPE_DOUBLE:
+		return types.FloatKind, true
+	case mlsolidv1.ValueType_VALUE_TYPE_STRING:
+		return types.StringKind, true
+	case mlsolidv1.ValueType_VALUE_TYPE_BOOL:
+		return types.BoolKind, true
+	case mlsolidv1.ValueType_VALUE_TYPE_HISTOGRAM:
+		return types.HistogramKind, true
+	case mlsolidv1.ValueType_VALUE_TYPE_VECTOR:
+		return types.VectorKind, true
+	default:
+		return "", false
+	}
+}
+
+// parseGrpcVal converts a grpc value to a metric value, nil when unset.
+func parseGrpcVal(val *mlsolidv1.Val) any {
+	switch v := val.GetVal().(type) {
+	case *mlsolidv1.Val_Int:
+		return v.Int
+	case *mlsolidv1.Val_Double:
+		return v.Double
+	case *mlsolidv1.Val_Str:
+		return v.Str
+	case *mlsolidv1.Val_Bool:
+		return v.Bool
+	case *mlsolidv1.Val_Histogram:
+		return types.Histogram{Edges: v.Histogram.GetEdges(), Counts: v.Histogram.GetCounts()}
+	case *mlsolidv1.Val_Vector:
+		return types.Vector(v.Vector.GetValues())
+	default:
+		return nil
 	}
 }
 
-func parseGrpcMetric(ms []*mlsolidv1.Metric) []types.Metric {
-	metrics := make([]types.Metric, len(ms))
+// parseGrpcMetric converts grpc metrics to metrics. All values of a metric
+// must be of its value type (int values being accepted for double metrics),
+// which defaults to the type of its first value. Metrics without values are
+// skipped.
+func parseGrpcMetric(ms []*mlsolidv1.Metric) ([]types.Metric, error) {
+	metrics := make([]types.Metric, 0, len(ms))
 
-	for i, m := range ms {
+	for _, m := range ms {
 		if m == nil || len(m.GetVals()) == 0 {
 			continue
 		}
 
-		var metric types.Metric
-
-		switch m.GetVals()[0].GetVal().(type) {
-		case *mlsolidv1.Val_Str:
-			metric = types.NewGenericMetric[string](m.GetName(), len(m.GetVals()))
-			for _, val := range m.GetVals() {
-				metric.AddVal(val.GetStr())
+		kind, ok := parseGrpcValueType(m.GetValueType())
+		if !ok {
+			kind, ok = types.KindOf(parseGrpcVal(m.GetVals()[0]))
+			if !ok {
+				return nil, types.NewBadRequest(fmt.Sprintf("metric %q has a value without type", m.GetName()))
 			}
-		case *mlsolidv1.Val_Double:
-			metric = types.NewGenericMetric[float64](m.GetName(), len(m.GetVals()))
-			for _, val := range m.GetVals() {
-				metric.AddVal(val.GetDouble())
+		}
+
+		metric, err := types.NewMetricOfKind(m.GetName(), kind, len(m.GetVals()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, val := range m.GetVals() {
+			v := parseGrpcVal(val)
+
+			if i, ok := v.(int64); ok && kind == types.FloatKind {
+				v = float64(i)
 			}
-		case *mlsolidv1.Val_Int:
-			metric = types.NewGenericMetric[int64](m.GetName(), len(m.GetVals()))
-			for _, val := range m.GetVals() {
-				metric.AddVal(val.GetInt())
+
+			if k, _ := types.KindOf(v); k != kind {
+				return nil, types.NewBadRequest(fmt.Sprintf("metric %q holds %s values, got a %T value", m.GetName(), kind, v))
 			}
+
+			metric.AddValWithMeta(v, parseGrpcValMeta(val))
 		}
 
-		metrics[i] = metric
+		metrics = append(metrics, metric)
 	}
 
-	return metrics
+	return metrics, nil
+}
+
+func parseGrpcValMeta(val *mlsolidv1.Val) types.ValMeta {
+	meta := types.ValMeta{} //nolint: exhaustruct
+
+	if val.Step != nil {
+		step := val.GetStep()
+		meta.Step = &step
+	}
+
+	if val.GetTimestamp() != nil {
+		t := val.GetTimestamp().AsTime()
+		meta.Timestamp = &t
+	}
+
+	return meta
 }
 
 func parseModelRegistry(r *types.ModelRegistry) *mlsolidv1.ModelRegistryResponse {
@@ -134,8 +457,9 @@ func parseModelRegistry(r *types.ModelRegistry) *mlsolidv1.ModelRegistryResponse
 
 	for i, entry := range r.Models {
 		resp.ModelEntries[i] = &mlsolidv1.ModelEntry{
-			Url:  entry.URL,
-			Tags: entry.Tags,
+			Url:   entry.URL,
+			Tags:  entry.Tags,
+			Files: ParseArtifactFiles(entry.Files),
 		}
 	}
 
@@ -174,3 +498,138 @@ func parseBenchmarkMetrics(metrics []*mlsolidv1.BenchmarkMetric) []types.BenchMe
 
 	return out
 }
+
+// parseVal converts a metric value and its ValMeta to a grpc value.
+func parseVal(v any, meta types.ValMeta) *mlsolidv1.Val {
+	val := &mlsolidv1.Val{Step: meta.Step}
+
+	switch v := v.(type) {
+	case float64:
+		val.Val = &mlsolidv1.Val_Double{Double: v}
+	case int64:
+		val.Val = &mlsolidv1.Val_Int{Int: v}
+	case string:
+		val.Val = &mlsolidv1.Val_Str{Str: v}
+	case bool:
+		val.Val = &mlsolidv1.Val_Bool{Bool: v}
+	case types.Histogram:
+		val.Val = &mlsolidv1.Val_Histogram{Histogram: &mlsolidv1.Histogram{Edges: v.Edges, Counts: v.Counts}}
+	case types.Vector:
+		val.Val = &mlsolidv1.Val_Vector{Vector: &mlsolidv1.Vector{Values: v}}
+	}
+
+	if meta.Timestamp != nil {
+		val.Timestamp = timestamppb.New(*meta.Timestamp)
+	}
+
+	return val
+}
+
+// NewWatchRunMetricsResponse converts a metric event to a grpc response.
+func NewWatchRunMetricsResponse(e types.MetricEvent) *mlsolidv1.WatchRunMetricsResponse {
+	return &mlsolidv1.WatchRunMetricsResponse{
+		Id:         e.ID,
+		MetricName: e.Metric,
+		Val:        parseVal(e.Value, e.ValMeta),
+	}
+}
+
+func NewWatchBenchmarkRunResponse(l types.BenchRunLog) *mlsolidv1.WatchBenchmarkRunResponse {
+	res := &mlsolidv1.WatchBenchmarkRunResponse{
+		Id:        l.ID,
+		Line:      l.Line,
+		Timestamp: timestamppb.New(l.Timestamp),
+		End:       l.End,
+	}
+
+	if l.Progress != nil {
+		res.Progress = &mlsolidv1.BenchmarkProgress{
+			Done:  l.Progress.Done,
+			Total: l.Progress.Total,
+		}
+	}
+
+	return res
+}
+
+// NewArtifactUploadResponse converts an artifact upload session to a grpc
+// response.
+func NewArtifactUploadResponse(s *types.UploadSession) *mlsolidv1.ArtifactUploadResponse {
+	return &mlsolidv1.ArtifactUploadResponse{
+		Session: &mlsolidv1.ArtifactUploadSession{
+			UploadId:     s.ID,
+			RunId:        s.RunID,
+			Name:         s.Name,
+			Size:         uint64(s.Size),     //nolint: gosec
+			Offset:       uint64(s.Offset),   //nolint: gosec
+			PartSize:     uint64(s.PartSize), //nolint: gosec
+			ExpiresAt:    timestamppb.New(s.ExpiresAt),
+			Deduplicated: s.Deduplicated,
+		},
+	}
+}
+
+// ParsePresignedRequest converts a pre-signed request to its grpc form.
+func ParsePresignedRequest(req *types.PresignedRequest) *mlsolidv1.PresignedRequest {
+	if req == nil {
+		return nil
+	}
+
+	return &mlsolidv1.PresignedRequest{
+		Url:       req.URL,
+		Method:    req.Method,
+		Headers:   req.Headers,
+		ExpiresAt: timestamppb.New(req.ExpiresAt),
+	}
+}
+
+// ParseArtifactInfos converts the metadata of saved artifacts to their grpc
+// form.
+func ParseArtifactInfos(as []types.SavedArtifact) []*mlsolidv1.ArtifactInfo {
+	out := make([]*mlsolidv1.ArtifactInfo, len(as))
+
+	for i, a := range as {
+		out[i] = &mlsolidv1.ArtifactInfo{
+			Name:     a.Name,
+			Type:     string(a.ContentType),
+			Size:     uint64(a.Size), //nolint: gosec
+			Sha256:   a.SHA256,
+			MimeType: a.ServedMIMEType(),
+			Encoding: string(a.Encoding),
+		}
+
+		if !a.CreatedAt.IsZero() {
+			out[i].CreatedAt = timestamppb.New(a.CreatedAt)
+		}
+	}
+
+	return out
+}
+
+// NewStorageUsageResponse converts the storage usage of a run or an
+// experiment to its grpc form.
+func NewStorageUsageResponse(usage *types.StorageUsage, quota types.StorageQuota) *mlsolidv1.StorageUsageResponse {
+	return &mlsolidv1.StorageUsageResponse{
+		Bytes:           uint64(max(usage.Bytes, 0)),           //nolint: gosec
+		Artifacts:       uint64(max(usage.Artifacts, 0)),       //nolint: gosec
+		Quota:           uint64(max(usage.Quota, 0)),           //nolint: gosec
+		MaxArtifactSize: uint64(max(quota.MaxArtifactSize, 0)), //nolint: gosec
+	}
+}
+
+// ParseArtifactFiles converts the files of a directory artifact to their
+// grpc form.
+func ParseArtifactFiles(files []types.ArtifactFile) []*mlsolidv1.ArtifactFile {
+	out := make([]*mlsolidv1.ArtifactFile, len(files))
+
+	for i, f := range files {
+		out[i] = &mlsolidv1.ArtifactFile{
+			Path:     f.Path,
+			Size:     uint64(f.Size), //nolint: gosec
+			Sha256:   f.SHA256,
+			MimeType: f.MIMEType,
+		}
+	}
+
+	return out
+}
@@ -3,17 +3,22 @@ package s3
 import (
 	"context"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/zeddo123/mlsolid/solid/types"
 )
 
@@ -22,8 +27,21 @@ const IDByteSize = 6
 type ObjectStore interface {
 	UploadFile(ctx context.Context, key string, body io.Reader) (string, error)
 	DownloadFile(ctx context.Context, key string) (io.ReadCloser, error)
+	DownloadRange(ctx context.Context, key string, r types.ByteRange) (io.ReadCloser, error)
+	DeleteFile(ctx context.Context, key string) error
 	DownloadURL(ctx context.Context, url string) (io.ReadCloser, error)
 	UploadArtifacts(ctx context.Context, artifacts []types.Artifact) ([]types.SavedArtifact, error)
+	CreateMultipartUpload(ctx context.Context, name string) (types.MultipartUpload, error)
+	UploadPart(ctx context.Context, key, uploadID string, number int32, body io.ReadSeeker) (string, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []types.UploadedPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+	MultipartUploads(ctx context.Context) ([]types.MultipartUpload, error)
+	PresignPut(ctx context.Context, name string, size int64, sha256 string,
+		ttl time.Duration) (string, types.PresignedRequest, error)
+	PresignGet(ctx context.Context, key, filename string, ttl time.Duration) (types.PresignedRequest, error)
+	ObjectSize(ctx context.Context, key string) (int64, error)
+	ListObjects(ctx context.Context) ([]types.StoredObject, error)
+	ObjectKey(ref string) (string, bool)
 }
 
 type Store struct {
@@ -88,17 +106,29 @@ func (s Store) UploadArtifacts(ctx context.Context, as []types.Artifact) ([]type
 			return nil, fmt.Errorf("could not upload <%s>: %w", a.Name(), err)
 		}
 
-		_, err = s.UploadFile(ctx, key, a.Content())
+		// compressed artifacts are stored with their encoding
+		var encoding types.ContentEncoding
+		if e, ok := a.(types.EncodedArtifact); ok {
+			encoding = e.Encoding
+		}
+
+		_, err = s.putObject(ctx, key, a.Content(), a.MIMEType(), encoding)
 		if err != nil {
 			errs = fmt.Errorf("%w: could not upload artifact <%s> : %w", errs, a.Name(), err)
 
 			continue
 		}
 
+		digest := a.Digest()
+
 		artifacts = append(artifacts, types.SavedArtifact{
 			Name:        a.Name(),
 			ContentType: a.ContentType(),
 			S3Key:       key,
+			Size:        digest.Size,
+			SHA256:      digest.SHA256,
+			MIMEType:    a.MIMEType(),
+			Encoding:    encoding,
 		})
 	}
 
@@ -106,15 +136,33 @@ func (s Store) UploadArtifacts(ctx context.Context, as []types.Artifact) ([]type
 }
 
 func (s Store) UploadFile(ctx context.Context, key string, body io.Reader) (string, error) {
+	return s.putObject(ctx, key, body, "", types.IdentityEncoding)
+}
+
+// putObject uploads an object, stored with its MIME type when it is known
+// and with the Content-Encoding of its compression.
+func (s Store) putObject(ctx context.Context, key string, body io.Reader, mimeType string,
+	encoding types.ContentEncoding,
+) (string, error) {
 	if s.client == nil {
 		return "", types.ErrNotInitialized
 	}
 
-	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+	in := &s3.PutObjectInput{
 		Bucket: &s.Bucket,
 		Key:    &key,
 		Body:   body,
-	})
+	}
+
+	if mimeType != "" {
+		in.ContentType = &mimeType
+	}
+
+	if encoding != types.IdentityEncoding {
+		in.ContentEncoding = aws.String(string(encoding))
+	}
+
+	_, err := s.client.PutObject(ctx, in)
 	if err != nil {
 		return "", err
 	}
@@ -139,6 +187,310 @@ func (s Store) DownloadFile(ctx context.Context, key string) (io.ReadCloser, err
 	return obj.Body, nil
 }
 
+// DownloadRange downloads the bytes of an object within r.
+func (s Store) DownloadRange(ctx context.Context, key string, r types.ByteRange) (io.ReadCloser, error) {
+	if s.client == nil {
+		return nil, types.ErrNotInitialized
+	}
+
+	obj, err := s.client.GetObject(ctx, &s3.GetObjectInput{ //nolint: exhaustruct
+		Bucket: &s.Bucket,
+		Key:    &key,
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", r.Start, r.End)),
+	})
+	if err != nil {
+		return nil, types.NewInternalErr(err.Error())
+	}
+
+	return obj.Body, nil
+}
+
+// DeleteFile deletes an object. Deleting a missing object is not an error.
+func (s Store) DeleteFile(ctx context.Context, key string) error {
+	if s.client == nil {
+		return types.ErrNotInitialized
+	}
+
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{ //nolint: exhaustruct
+		Bucket: &s.Bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return types.NewInternalErr(err.Error())
+	}
+
+	return nil
+}
+
+// CreateMultipartUpload starts a multipart upload of a new object for the
+// artifact name.
+func (s Store) CreateMultipartUpload(ctx context.Context, name string) (types.MultipartUpload, error) {
+	if s.client == nil {
+		return types.MultipartUpload{}, types.ErrNotInitialized
+	}
+
+	key, err := s.GenerateKey(name)
+	if err != nil {
+		return types.MultipartUpload{}, fmt.Errorf("could not upload <%s>: %w", name, err)
+	}
+
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{ //nolint: exhaustruct
+		Bucket: &s.Bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return types.MultipartUpload{}, types.NewInternalErr(err.Error())
+	}
+
+	return types.MultipartUpload{
+		Key:       key,
+		UploadID:  aws.ToString(out.UploadId),
+		Initiated: time.Now(),
+	}, nil
+}
+
+// UploadPart uploads a part of a multipart upload, replacing any part
+// uploaded before with the same number, and returns its ETag.
+func (s Store) UploadPart(ctx context.Context, key, uploadID string, number int32, body io.ReadSeeker) (string, error) {
+	if s.client == nil {
+		return "", types.ErrNotInitialized
+	}
+
+	out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{ //nolint: exhaustruct
+		Bucket:     &s.Bucket,
+		Key:        &key,
+		UploadId:   &uploadID,
+		PartNumber: &number,
+		Body:       body,
+	})
+	if err != nil {
+		return "", types.NewInternalErr(err.Error())
+	}
+
+	return aws.ToString(out.ETag), nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the object.
+func (s Store) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []types.UploadedPart) error {
+	if s.client == nil {
+		return types.ErrNotInitialized
+	}
+
+	completed := make([]s3types.CompletedPart, len(parts))
+
+	for i, p := range parts {
+		completed[i] = s3types.CompletedPart{ //nolint: exhaustruct
+			ETag:       aws.String(p.ETag),
+			PartNumber: aws.Int32(p.Number),
+		}
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{ //nolint: exhaustruct
+		Bucket:          &s.Bucket,
+		Key:             &key,
+		UploadId:        &uploadID,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return types.NewInternalErr(err.Error())
+	}
+
+	return nil
+}
+
+// AbortMultipartUpload aborts a multipart upload, deleting its parts.
+func (s Store) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	if s.client == nil {
+		return types.ErrNotInitialized
+	}
+
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{ //nolint: exhaustruct
+		Bucket:   &s.Bucket,
+		Key:      &key,
+		UploadId: &uploadID,
+	})
+	if err != nil {
+		return types.NewInternalErr(err.Error())
+	}
+
+	return nil
+}
+
+// MultipartUploads lists the multipart uploads in progress under the
+// store's prefix.
+func (s Store) MultipartUploads(ctx context.Context) ([]types.MultipartUpload, error) {
+	if s.client == nil {
+		return nil, types.ErrNotInitialized
+	}
+
+	uploads := make([]types.MultipartUpload, 0)
+
+	paginator := s3.NewListMultipartUploadsPaginator(s.client, &s3.ListMultipartUploadsInput{ //nolint: exhaustruct
+		Bucket: &s.Bucket,
+		Prefix: aws.String(s.Prefix + "/"),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, types.NewInternalErr(err.Error())
+		}
+
+		for _, u := range page.Uploads {
+			uploads = append(uploads, types.MultipartUpload{
+				Key:       aws.ToString(u.Key),
+				UploadID:  aws.ToString(u.UploadId),
+				Initiated: aws.ToTime(u.Initiated),
+			})
+		}
+	}
+
+	return uploads, nil
+}
+
+// PresignPut generates the key of a new object for the artifact name and
+// pre-signs a PUT of its content. S3 rejects the upload unless its content
+// is size bytes long and has the hex encoded SHA-256 sha256.
+func (s Store) PresignPut(ctx context.Context, name string, size int64, sha256 string,
+	ttl time.Duration,
+) (string, types.PresignedRequest, error) {
+	if s.client == nil {
+		return "", types.PresignedRequest{}, types.ErrNotInitialized
+	}
+
+	key, err := s.GenerateKey(name)
+	if err != nil {
+		return "", types.PresignedRequest{}, fmt.Errorf("could not upload <%s>: %w", name, err)
+	}
+
+	digest, err := hex.DecodeString(sha256)
+	if err != nil {
+		return "", types.PresignedRequest{}, types.NewBadRequest(fmt.Sprintf("malformed sha256 %q", sha256))
+	}
+
+	req, err := s3.NewPresignClient(s.client).PresignPutObject(ctx, &s3.PutObjectInput{ //nolint: exhaustruct
+		Bucket:         &s.Bucket,
+		Key:            &key,
+		ContentLength:  &size,
+		ChecksumSHA256: aws.String(base64.StdEncoding.EncodeToString(digest)),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", types.PresignedRequest{}, types.NewInternalErr(err.Error())
+	}
+
+	return key, newPresignedRequest(req, ttl), nil
+}
+
+// PresignGet pre-signs a GET of an object, downloaded as filename.
+func (s Store) PresignGet(ctx context.Context, key, filename string, ttl time.Duration) (types.PresignedRequest, error) {
+	if s.client == nil {
+		return types.PresignedRequest{}, types.ErrNotInitialized
+	}
+
+	req, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{ //nolint: exhaustruct
+		Bucket:                     &s.Bucket,
+		Key:                        &key,
+		ResponseContentDisposition: aws.String(mime.FormatMediaType("attachment", map[string]string{"filename": filename})),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return types.PresignedRequest{}, types.NewInternalErr(err.Error())
+	}
+
+	return newPresignedRequest(req, ttl), nil
+}
+
+func newPresignedRequest(req *v4.PresignedHTTPRequest, ttl time.Duration) types.PresignedRequest {
+	headers := make(map[string]string, len(req.SignedHeader))
+
+	for k := range req.SignedHeader {
+		// set by the client's http library from the url
+		if strings.EqualFold(k, "host") {
+			continue
+		}
+
+		headers[k] = req.SignedHeader.Get(k)
+	}
+
+	return types.PresignedRequest{
+		URL:       req.URL,
+		Method:    req.Method,
+		Headers:   headers,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
+// ObjectSize returns the size of an object, an ErrNotFound error when it
+// does not exist.
+func (s Store) ObjectSize(ctx context.Context, key string) (int64, error) {
+	if s.client == nil {
+		return 0, types.ErrNotInitialized
+	}
+
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{ //nolint: exhaustruct
+		Bucket: &s.Bucket,
+		Key:    &key,
+	})
+
+	var notFound *s3types.NotFound
+	if errors.As(err, &notFound) {
+		return 0, types.NewNotFoundErr(fmt.Sprintf("object <%s> not found", key))
+	} else if err != nil {
+		return 0, types.NewInternalErr(err.Error())
+	}
+
+	return aws.ToInt64(out.ContentLength), nil
+}
+
+// ListObjects lists the objects under the prefix of the store.
+func (s Store) ListObjects(ctx context.Context) ([]types.StoredObject, error) {
+	if s.client == nil {
+		return nil, types.ErrNotInitialized
+	}
+
+	objects := make([]types.StoredObject, 0)
+
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{ //nolint: exhaustruct
+		Bucket: &s.Bucket,
+		Prefix: aws.String(s.Prefix + "/"),
+	})
+
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, types.NewInternalErr(err.Error())
+		}
+
+		for _, o := range page.Contents {
+			objects = append(objects, types.StoredObject{
+				Key:          aws.ToString(o.Key),
+				Size:         aws.ToInt64(o.Size),
+				LastModified: aws.ToTime(o.LastModified),
+			})
+		}
+	}
+
+	return objects, nil
+}
+
+// ObjectKey returns the key of the object of the store ref points at, ref
+// being either a key or a s3://<bucket>/<key> url. Refs pointing outside of
+// the bucket or prefix of the store, e.g. http(s) urls of model entries, are
+// not keys of the store.
+func (s Store) ObjectKey(ref string) (string, bool) {
+	key := ref
+
+	if strings.Contains(ref, "://") {
+		u, err := url.Parse(ref)
+		if err != nil || u.Scheme != "s3" || u.Host != s.Bucket {
+			return "", false
+		}
+
+		key = strings.TrimPrefix(u.Path, "/")
+	}
+
+	return key, strings.HasPrefix(key, s.Prefix+"/")
+}
+
 func (s *Store) GenerateKey(name string) (string, error) {
 	r, err := generateID(IDByteSize)
 	if err != nil {
@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/zeddo123/mlsolid/solid/types"
@@ -58,13 +60,24 @@ func (r *RedisStore) SetArtifacts(ctx context.Context, runID string, as []types.
 	return nil
 }
 
+// setArtifact saves an artifact of a run, created now unless its CreatedAt
+// is set.
 func (r *RedisStore) setArtifact(ctx context.Context, p redis.Pipeliner,
 	runID string, a types.SavedArtifact,
 ) *redis.IntCmd { //nolint: unparam
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+
 	return p.HSet(ctx, r.makeArtifactKey(a.Name, runID), map[string]string{
-		"Name":  a.Name,
-		"Type":  string(a.ContentType),
-		"S3Key": a.S3Key,
+		"Name":      a.Name,
+		"Type":      string(a.ContentType),
+		"S3Key":     a.S3Key,
+		"Size":      strconv.FormatInt(a.Size, 10),
+		"SHA256":    a.SHA256,
+		"MIME":      a.MIMEType,
+		"Encoding":  string(a.Encoding),
+		"CreatedAt": a.CreatedAt.Format(time.RFC3339Nano),
 	})
 }
 
@@ -93,11 +106,7 @@ func (r *RedisStore) Artifacts(ctx context.Context, runID string) (map[string]ty
 			continue
 		}
 
-		artifacts[mapping["Name"]] = types.SavedArtifact{
-			Name:        mapping["Name"],
-			ContentType: types.ContentType(mapping["Type"]),
-			S3Key:       mapping["S3Key"],
-		}
+		artifacts[mapping["Name"]] = parseArtifact(mapping)
 	}
 
 	return artifacts, nil
@@ -123,11 +132,26 @@ func (r *RedisStore) Artifact(ctx context.Context, runID string, id string) (typ
 		return types.SavedArtifact{}, types.NewNotFoundErr("could not find artifact") //nolint: wrapcheck
 	}
 
+	return parseArtifact(mapping), nil
+}
+
+// parseArtifact parses an artifact hash. Artifacts saved before their size
+// and digest were recorded have a zero Size and an empty SHA256, and an
+// empty MIMEType and a zero CreatedAt when they were not recorded either.
+func parseArtifact(mapping map[string]string) types.SavedArtifact {
+	size, _ := strconv.ParseInt(mapping["Size"], 10, 64)
+	createdAt, _ := time.Parse(time.RFC3339Nano, mapping["CreatedAt"])
+
 	return types.SavedArtifact{
 		Name:        mapping["Name"],
 		ContentType: types.ContentType(mapping["Type"]),
 		S3Key:       mapping["S3Key"],
-	}, nil
+		Size:        size,
+		SHA256:      mapping["SHA256"],
+		MIMEType:    mapping["MIME"],
+		Encoding:    types.ContentEncoding(mapping["Encoding"]),
+		CreatedAt:   createdAt,
+	}
 }
 
 func (r *RedisStore) artifact(ctx context.Context, p redis.Pipeliner,
@@ -145,3 +169,37 @@ func (r *RedisStore) artifacts(ctx context.Context, p redis.Pipeliner, keys []st
 
 	return cmds
 }
+
+// ArtifactDeletionQueue returns up to limit S3 keys queued for deletion,
+// oldest first.
+func (r *RedisStore) ArtifactDeletionQueue(ctx context.Context, limit int64) ([]string, error) {
+	keys, err := r.Client.ZRange(ctx, ArtifactDeletionQueueKey, 0, limit-1).Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not read artifact deletion queue")
+	}
+
+	return keys, nil
+}
+
+// AckArtifactDeletion removes S3 keys from the deletion queue once their
+// objects are deleted.
+func (r *RedisStore) AckArtifactDeletion(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := r.Client.ZRem(ctx, ArtifactDeletionQueueKey, toAny(keys)...).Err()
+	if err != nil {
+		return types.NewInternalErr("could not update artifact deletion queue")
+	}
+
+	return nil
+}
+
+func queueArtifactDeletion(ctx context.Context, p redis.Pipeliner, s3Key string) {
+	if s3Key == "" {
+		return
+	}
+
+	p.ZAddNX(ctx, ArtifactDeletionQueueKey, redis.Z{Score: float64(time.Now().Unix()), Member: s3Key})
+}
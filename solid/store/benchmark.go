@@ -3,8 +3,10 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strconv"
 	"time"
 
@@ -12,12 +14,20 @@ import (
 	"github.com/zeddo123/mlsolid/solid/types"
 )
 
-// activeBenchRunTTL bounds how long an "ActiveBenchRun" field can survive
-// without being refreshed, so a run that never gets cleared (crashed
-// process, RemActiveBenchRun failing to reach Redis) self-heals instead of
-// showing as active forever. Set well above any expected benchmark run
-// duration.
-const activeBenchRunTTL = 5 * 24 * time.Hour
+// activeBenchRunTTL bounds how long an active run can survive without being
+// heartbeated, so a run that never gets cleared (crashed process,
+// RemActiveBenchRun failing to reach Redis) self-heals instead of showing
+// as active forever. Set well above the interval at which runners call
+// HeartbeatActiveBenchRun.
+const activeBenchRunTTL = 10 * time.Minute
+
+// benchRunLogsTTL bounds how long the log stream of a benchmark run is kept
+// around once it stops being written to, and benchRunLogsMaxLen how many
+// lines it holds (older lines are trimmed first).
+const (
+	benchRunLogsTTL    = 2 * 24 * time.Hour
+	benchRunLogsMaxLen = 10000
+)
 
 // CreateBenchmark creates a new benchmark.
 func (r *RedisStore) CreateBenchmark(ctx context.Context, b types.Bench) (bool, error) {
@@ -195,8 +205,9 @@ func (r *RedisStore) Benchmark(ctx context.Context, benchID string) (*types.Benc
 	data := r.Client.HGetAll(ctx, key)
 	registries := r.Client.SMembers(ctx, r.makeBenchmarkRegistriesKey(benchID))
 	metrics := r.Client.HGetAll(ctx, r.makeBenchmarkMetricsKey(benchID))
+	activeRuns := r.Client.HVals(ctx, r.makeBenchmarkActiveRunsKey(benchID))
 
-	return parseBenchmark(benchID, data, metrics, registries)
+	return parseBenchmark(benchID, data, metrics, registries, activeRuns)
 }
 
 // BenchmarkMetrics pulls metrics linked to a benchmark.
@@ -346,48 +357,153 @@ func (r *RedisStore) BenchmarkRuns(ctx context.Context, benchID string) ([]*type
 	return runs, nil
 }
 
-// SetActiveBenchRun records run as the benchmark run currently in progress
-// for benchID, so callers can tell which run is executing before it
-// finishes and is written via RecordRuns. It is stored in the
-// "ActiveBenchRun" field of the benchmark's hash as a JSON blob and
-// overwrites whatever active run was previously set, so only one run is
-// ever considered active per benchmark. Callers are responsible for
-// clearing it with RemActiveBenchRun once the run completes.
+// SetActiveBenchRun records run as in progress for benchID, so callers can
+// tell which runs are executing before they finish and are written via
+// RecordRuns. Active runs are kept in a per-benchmark hash keyed by
+// registry/version, so several versions can be benchmarked concurrently;
+// setting a run that is already active replaces it. Each entry expires
+// activeBenchRunTTL after it was last set or heartbeated. Callers are
+// responsible for clearing it with RemActiveBenchRun once the run completes.
+//
+// Any log stream left over from a previous run of the same registry and
+// version is dropped, so watchers only ever see the output of this run.
 func (r *RedisStore) SetActiveBenchRun(ctx context.Context, benchID string, run types.BenchRun) error {
-	key := r.makeBenchmarkKey(benchID)
+	key := r.makeBenchmarkActiveRunsKey(benchID)
+	field := r.makeActiveBenchRunField(run.Registry, run.Version)
 
 	content, err := json.Marshal(run)
 	if err != nil {
 		return fmt.Errorf("%w: could not marshal benchmark run due to %w", types.ErrInternal, err)
 	}
 
-	_, err = r.Client.HSet(ctx, key, "ActiveBenchRun", content).Result()
+	p := r.Client.TxPipeline()
+
+	p.Del(ctx, r.makeBenchmarkRunLogsKey(benchID, run.Registry, run.Version))
+	p.HSet(ctx, key, field, content)
+	p.HExpire(ctx, key, activeBenchRunTTL, field)
+	// drop the single active run field used before runs were tracked per
+	// registry/version
+	p.HDel(ctx, r.makeBenchmarkKey(benchID), "ActiveBenchRun")
+
+	_, err = p.Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("%w: could not set active benchmark field of benchmark %q due to %w",
+		return fmt.Errorf("%w: could not set active benchmark run of benchmark %q due to %w",
 			types.ErrInternal, benchID, err)
 	}
 
-	_, err = r.Client.HExpire(ctx, key, activeBenchRunTTL, "ActiveBenchRun").Result()
+	return nil
+}
+
+// HeartbeatActiveBenchRun pushes back the expiry of the active run of
+// registry/version on benchID by activeBenchRunTTL. It returns a not found
+// error when that run is not (or no longer) active.
+func (r *RedisStore) HeartbeatActiveBenchRun(ctx context.Context, benchID, registry string, version int64) error {
+	res, err := r.Client.HExpire(ctx, r.makeBenchmarkActiveRunsKey(benchID), activeBenchRunTTL,
+		r.makeActiveBenchRunField(registry, version)).Result()
 	if err != nil {
-		return fmt.Errorf("%w: could not set expiry on active benchmark field of benchmark %q due to %w",
+		return fmt.Errorf("%w: could not refresh active benchmark run of benchmark %q due to %w",
 			types.ErrInternal, benchID, err)
 	}
 
+	// HEXPIRE reports -2 for a field (or key) that does not exist
+	if len(res) != 1 || res[0] == -2 {
+		return fmt.Errorf("%w: run %s:%d is not active on benchmark %q",
+			types.ErrNotFound, registry, version, benchID)
+	}
+
 	return nil
 }
 
-// RemActiveBenchRun clears the "ActiveBenchRun" field set by
-// SetActiveBenchRun, e.g. once a run has finished and been recorded. It is
-// a no-op, not an error, when no active run is set.
-func (r *RedisStore) RemActiveBenchRun(ctx context.Context, benchID string) error {
-	_, err := r.Client.HDel(ctx, r.makeBenchmarkKey(benchID), "ActiveBenchRun").Result()
+// RemActiveBenchRun clears the active run of registry/version set by
+// SetActiveBenchRun, e.g. once it has finished and been recorded. It is a
+// no-op, not an error, when that run is not active.
+func (r *RedisStore) RemActiveBenchRun(ctx context.Context, benchID, registry string, version int64) error {
+	_, err := r.Client.HDel(ctx, r.makeBenchmarkActiveRunsKey(benchID),
+		r.makeActiveBenchRunField(registry, version)).Result()
 	if err != nil {
-		return fmt.Errorf("%w: could not delete active benchmark run field due to %w", types.ErrInternal, err)
+		return fmt.Errorf("%w: could not delete active benchmark run due to %w", types.ErrInternal, err)
 	}
 
 	return nil
 }
 
+// AppendBenchRunLogs appends lines of container output to the log stream of
+// the benchmark run identified by benchID, registry and version. The stream
+// is capped to roughly benchRunLogsMaxLen lines and expires benchRunLogsTTL
+// after the last append.
+func (r *RedisStore) AppendBenchRunLogs(ctx context.Context, benchID, registry string, version int64,
+	logs ...types.BenchRunLog,
+) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	key := r.makeBenchmarkRunLogsKey(benchID, registry, version)
+
+	p := r.Client.Pipeline()
+
+	for _, l := range logs {
+		values := map[string]any{
+			"Line":      l.Line,
+			"Timestamp": l.Timestamp.Format(time.RFC3339Nano),
+			"End":       l.End,
+		}
+
+		if l.Progress != nil {
+			values["Done"] = l.Progress.Done
+			values["Total"] = l.Progress.Total
+		}
+
+		p.XAdd(ctx, &redis.XAddArgs{ //nolint: exhaustruct
+			Stream: key,
+			MaxLen: benchRunLogsMaxLen,
+			Approx: true,
+			Values: values,
+		})
+	}
+
+	p.Expire(ctx, key, benchRunLogsTTL)
+
+	_, err := p.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: could not append benchmark run logs: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// BenchRunLogs reads the log entries of a benchmark run written after the
+// entry with id after ("" or "0" reads from the start of the stream). When
+// no entry is available yet it blocks for up to block before returning an
+// empty slice; a negative block never waits.
+func (r *RedisStore) BenchRunLogs(ctx context.Context, benchID, registry string, version int64,
+	after string, block time.Duration,
+) ([]types.BenchRunLog, error) {
+	if after == "" {
+		after = "0"
+	}
+
+	res, err := r.Client.XRead(ctx, &redis.XReadArgs{ //nolint: exhaustruct
+		Streams: []string{r.makeBenchmarkRunLogsKey(benchID, registry, version), after},
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return []types.BenchRunLog{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: could not read benchmark run logs: %w", types.ErrInternal, err)
+	}
+
+	logs := make([]types.BenchRunLog, 0)
+
+	for _, stream := range res {
+		for _, msg := range stream.Messages {
+			logs = append(logs, r.parseBenchRunLog(msg))
+		}
+	}
+
+	return logs, nil
+}
+
 // Benchmarks returns all known benchmarks.
 func (r *RedisStore) Benchmarks(ctx context.Context) ([]string, error) {
 	benchs, err := r.zIndexAll(ctx, BenchmarksKey)
@@ -448,6 +564,7 @@ func (r *RedisStore) BenchmarksWithIDs(ctx context.Context, ids []string) ([]*ty
 		data       *redis.MapStringStringCmd
 		metrics    *redis.MapStringStringCmd
 		registries *redis.StringSliceCmd
+		activeRuns *redis.StringSliceCmd
 	}
 
 	results := make([]*types.Bench, len(ids))
@@ -460,6 +577,7 @@ func (r *RedisStore) BenchmarksWithIDs(ctx context.Context, ids []string) ([]*ty
 			data:       p.HGetAll(ctx, r.makeBenchmarkKey(id)),
 			registries: p.SMembers(ctx, r.makeBenchmarkRegistriesKey(id)),
 			metrics:    p.HGetAll(ctx, r.makeBenchmarkMetricsKey(id)),
+			activeRuns: p.HVals(ctx, r.makeBenchmarkActiveRunsKey(id)),
 		}
 	}
 
@@ -471,7 +589,7 @@ func (r *RedisStore) BenchmarksWithIDs(ctx context.Context, ids []string) ([]*ty
 	for idx, id := range ids {
 		prslt := partialResults[id]
 
-		bench, err := parseBenchmark(id, prslt.data, prslt.metrics, prslt.registries)
+		bench, err := parseBenchmark(id, prslt.data, prslt.metrics, prslt.registries, prslt.activeRuns)
 		if err != nil {
 			log.Println("could not parse benchmark:", err)
 
@@ -507,7 +625,7 @@ func (r *RedisStore) BenchmarkExists(ctx context.Context, benchID string) (bool,
 }
 
 func parseBenchmark(benchID string, data, metrics *redis.MapStringStringCmd,
-	registries *redis.StringSliceCmd,
+	registries, activeRuns *redis.StringSliceCmd,
 ) (*types.Bench, error) {
 	mapping, err := data.Result()
 	if err != nil {
@@ -549,33 +667,55 @@ func parseBenchmark(benchID string, data, metrics *redis.MapStringStringCmd,
 		return nil, fmt.Errorf("could not parse benchmark metrics: %w", err)
 	}
 
-	var benchRun types.BenchRun
+	active, err := parseActiveBenchRuns(activeRuns)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse active benchmark runs: %w", err)
+	}
+
+	return &types.Bench{
+		ID:              benchID,
+		Name:            mapping["Name"],
+		Paused:          paused,
+		EagerStart:      eager,
+		AutoTag:         autotag,
+		Tag:             mapping["Tag"],
+		DecisionMetric:  mapping["DecisionMetric"],
+		DatasetName:     mapping["DatasetName"],
+		DatasetURL:      mapping["DatasetURL"],
+		FromS3:          froms3,
+		Timestamp:       timestamp,
+		Registries:      regs,
+		Metrics:         mets,
+		ActiveBenchRuns: active,
+	}, nil
+}
 
-	// "ActiveBenchRun" is absent for the common case of a benchmark with no
-	// run in progress, so only attempt to parse it when present.
-	if run, ok := mapping["ActiveBenchRun"]; ok && run != "" {
-		err = json.Unmarshal([]byte(mapping["ActiveBenchRun"]), &benchRun)
+func parseActiveBenchRuns(runs *redis.StringSliceCmd) ([]types.BenchRun, error) {
+	vals, err := runs.Result()
+	if err != nil {
+		return nil, fmt.Errorf("could not pull active benchmark runs: %w", err)
+	}
+
+	active := make([]types.BenchRun, 0, len(vals))
+
+	for _, v := range vals {
+		var run types.BenchRun
+
+		err := json.Unmarshal([]byte(v), &run)
 		if err != nil {
-			log.Printf("could not parse active benchmark run Run=%s err=%s\n", mapping["ActiveBenchRun"], err)
+			log.Printf("could not parse active benchmark run Run=%s err=%s\n", v, err)
+
+			continue
 		}
+
+		active = append(active, run)
 	}
 
-	return &types.Bench{
-		ID:             benchID,
-		Name:           mapping["Name"],
-		Paused:         paused,
-		EagerStart:     eager,
-		AutoTag:        autotag,
-		Tag:            mapping["Tag"],
-		DecisionMetric: mapping["DecisionMetric"],
-		DatasetName:    mapping["DatasetName"],
-		DatasetURL:     mapping["DatasetURL"],
-		FromS3:         froms3,
-		Timestamp:      timestamp,
-		Registries:     regs,
-		Metrics:        mets,
-		ActiveBenchRun: benchRun,
-	}, nil
+	slices.SortFunc(active, func(a, b types.BenchRun) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return active, nil
 }
 
 func parseBenchmarkMetrics(metrics *redis.MapStringStringCmd) ([]types.BenchMetric, error) {
@@ -600,6 +740,39 @@ func parseBenchmarkMetrics(metrics *redis.MapStringStringCmd) ([]types.BenchMetr
 	return benchMetrics, nil
 }
 
+func (r *RedisStore) parseBenchRunLog(msg redis.XMessage) types.BenchRunLog {
+	l := types.BenchRunLog{ID: msg.ID} //nolint: exhaustruct
+
+	l.Line, _ = msg.Values["Line"].(string)
+
+	if ts, ok := msg.Values["Timestamp"].(string); ok {
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			r.Logger.Error().Err(err).Str("timestamp", ts).Msg("could not parse benchmark run log timestamp")
+		}
+
+		l.Timestamp = t
+	}
+
+	if end, ok := msg.Values["End"].(string); ok {
+		l.End, _ = strconv.ParseBool(end)
+	}
+
+	done, hasDone := msg.Values["Done"].(string)
+	total, hasTotal := msg.Values["Total"].(string)
+
+	if hasDone && hasTotal {
+		d, derr := strconv.ParseInt(done, 10, 64)
+		t, terr := strconv.ParseInt(total, 10, 64)
+
+		if derr == nil && terr == nil {
+			l.Progress = &types.BenchProgress{Done: d, Total: t}
+		}
+	}
+
+	return l
+}
+
 func (r *RedisStore) parseBenchRun(m map[string]string) (*types.BenchRun, error) {
 	reg := m["Registry"]
 
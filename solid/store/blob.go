@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// acquireBlobSrc adds the reference ARGV[1] to the blob KEYS[1] (its refs
+// set being KEYS[2]) and returns the blob's object key and encoding. A blob
+// that does not exist yet is created with the object key ARGV[2], size
+// ARGV[3] and encoding ARGV[4], unless ARGV[2] is empty, in which case nil
+// is returned.
+const acquireBlobSrc = `
+local key = redis.call('HGET', KEYS[1], 'Key')
+local encoding = redis.call('HGET', KEYS[1], 'Encoding') or ''
+
+if not key then
+  if ARGV[2] == '' then
+    return false
+  end
+
+  key = ARGV[2]
+  encoding = ARGV[4]
+  redis.call('HSET', KEYS[1], 'Key', key, 'Size', ARGV[3], 'Encoding', encoding)
+end
+
+redis.call('SADD', KEYS[2], ARGV[1])
+
+return {key, encoding}
+`
+
+// releaseBlobSrc removes the reference ARGV[1] of an artifact stored at the
+// object key ARGV[2] from the blob KEYS[1] (its refs set being KEYS[2]).
+// The blob is deleted, and its object queued for deletion in KEYS[3] at
+// time ARGV[3], once its last reference goes. Artifacts whose object is not
+// the blob's (stored before blobs were recorded) have their own object
+// queued for deletion.
+const releaseBlobSrc = `
+redis.call('SREM', KEYS[2], ARGV[1])
+
+local key = redis.call('HGET', KEYS[1], 'Key')
+
+if key ~= ARGV[2] then
+  if ARGV[2] ~= '' then
+    redis.call('ZADD', KEYS[3], 'NX', ARGV[3], ARGV[2])
+  end
+
+  return 0
+end
+
+if redis.call('SCARD', KEYS[2]) == 0 then
+  redis.call('DEL', KEYS[1])
+  redis.call('ZADD', KEYS[3], 'NX', ARGV[3], key)
+end
+
+return 0
+`
+
+var (
+	acquireBlobScript = redis.NewScript(acquireBlobSrc) //nolint: gochecknoglobals
+	releaseBlobScript = redis.NewScript(releaseBlobSrc) //nolint: gochecknoglobals
+)
+
+// ArtifactRef returns the blob reference of the artifact name of a run.
+func (r *RedisStore) ArtifactRef(runID, name string) string {
+	return r.makeArtifactKey(name, runID)
+}
+
+// ModelEntryRef returns the blob reference of a model entry.
+func (r *RedisStore) ModelEntryRef(registry string, version int) string {
+	return fmt.Sprintf("%s:%d", r.makeModelRegistryKey(registry), version)
+}
+
+// Blob pulls the blob holding the content with the SHA-256 sha256.
+func (r *RedisStore) Blob(ctx context.Context, sha256 string) (types.Blob, error) {
+	m, err := r.Client.HGetAll(ctx, r.makeBlobKey(sha256)).Result()
+	if err != nil {
+		return types.Blob{}, fmt.Errorf("%w: could not pull blob: %w", types.ErrInternal, err)
+	}
+
+	if m["Key"] == "" {
+		return types.Blob{}, types.NewNotFoundErr(fmt.Sprintf("blob <%s> not found", sha256))
+	}
+
+	size, _ := strconv.ParseInt(m["Size"], 10, 64)
+
+	return types.Blob{SHA256: sha256, Key: m["Key"], Size: size, Encoding: types.ContentEncoding(m["Encoding"])}, nil
+}
+
+// AcquireBlob references the blob of b.SHA256 from ref and returns it. When
+// no such blob exists, b is recorded as the blob of its content, unless its
+// Key is empty in which case an ErrNotFound error is returned. The returned
+// blob has the object key and encoding of the blob recorded. Callers whose
+// object lost to an existing blob should queue it for deletion.
+func (r *RedisStore) AcquireBlob(ctx context.Context, ref string, b types.Blob) (types.Blob, error) {
+	keys := []string{r.makeBlobKey(b.SHA256), r.makeBlobRefsKey(b.SHA256)}
+
+	res, err := acquireBlobScript.Run(ctx, &r.Client, keys, ref, b.Key, b.Size, string(b.Encoding)).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return types.Blob{}, types.NewNotFoundErr(fmt.Sprintf("blob <%s> not found", b.SHA256))
+	} else if err != nil || len(res) != 2 { //nolint: mnd
+		return types.Blob{}, fmt.Errorf("%w: could not reference blob: %w", types.ErrInternal, err)
+	}
+
+	b.Key, b.Encoding = res[0], types.ContentEncoding(res[1])
+
+	return b, nil
+}
+
+// QueueArtifactDeletion queues an object for deletion, see
+// ArtifactDeletionQueueKey.
+func (r *RedisStore) QueueArtifactDeletion(ctx context.Context, s3Key string) error {
+	_, err := r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		queueArtifactDeletion(ctx, p, s3Key)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not queue artifact deletion: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// ReleaseBlob removes the reference ref, of content with the SHA-256 sha256
+// stored at s3Key, from its blob, see releaseBlob.
+func (r *RedisStore) ReleaseBlob(ctx context.Context, ref, sha256, s3Key string) error {
+	_, err := r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		r.releaseBlob(ctx, p, ref, sha256, s3Key)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not release blob: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// releaseArtifact releases the objects of an artifact of a run being
+// deleted, files being the files of directory artifacts: artifacts stored
+// as blobs release their references, other ones have their object queued
+// for deletion.
+func (r *RedisStore) releaseArtifact(ctx context.Context, p redis.Pipeliner, runID string, a types.SavedArtifact,
+	files []types.ArtifactFile,
+) {
+	for _, f := range files {
+		r.releaseBlob(ctx, p, r.ArtifactFileRef(runID, a.Name, f.Path), f.SHA256, f.S3Key)
+	}
+
+	if a.SHA256 == "" {
+		queueArtifactDeletion(ctx, p, a.S3Key)
+
+		return
+	}
+
+	r.releaseBlob(ctx, p, r.ArtifactRef(runID, a.Name), a.SHA256, a.S3Key)
+}
+
+// releaseBlob removes the reference ref, of content with the SHA-256 sha256
+// stored at s3Key, from its blob; the blob's object is queued for deletion
+// once its last reference goes.
+func (r *RedisStore) releaseBlob(ctx context.Context, p redis.Pipeliner, ref, sha256, s3Key string) {
+	keys := []string{r.makeBlobKey(sha256), r.makeBlobRefsKey(sha256), ArtifactDeletionQueueKey}
+
+	// EVAL rather than EVALSHA, as a missing script cannot be retried
+	// from within a pipeline
+	releaseBlobScript.Eval(ctx, p, keys, ref, s3Key, time.Now().Unix())
+}
+
+// BackfillBlobs records the artifacts saved with a digest before blobs were
+// recorded as the blobs of their content, so later uploads of the same
+// content share them. Artifacts whose content already has a blob keep their
+// own object, as model entries may point at it. It is idempotent.
+func (r *RedisStore) BackfillBlobs(ctx context.Context) error {
+	keys, err := r.scanKeys(ctx, "artifact:*")
+	if err != nil {
+		return types.NewInternalErr("could not scan artifact keys")
+	}
+
+	for _, key := range keys {
+		m, err := r.Client.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("could not read artifact: %w", err)
+		}
+
+		a := parseArtifact(m)
+		if a.SHA256 == "" || a.S3Key == "" {
+			continue
+		}
+
+		b, err := r.Blob(ctx, a.SHA256)
+		if errors.Is(err, types.ErrNotFound) {
+			b = types.Blob{SHA256: a.SHA256, Key: a.S3Key, Size: a.Size, Encoding: a.Encoding}
+		} else if err != nil {
+			return err
+		}
+
+		if b.Key != a.S3Key {
+			continue
+		}
+
+		if _, err := r.AcquireBlob(ctx, key, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// ArtifactFileRef returns the blob reference of a file of the directory
+// artifact name of a run.
+func (r *RedisStore) ArtifactFileRef(runID, name, path string) string {
+	return r.ArtifactRef(runID, name) + "/" + path
+}
+
+// ModelEntryFileRef returns the blob reference of a file of a model entry
+// registered from a directory artifact.
+func (r *RedisStore) ModelEntryFileRef(registry string, version int, path string) string {
+	return r.ModelEntryRef(registry, version) + "/" + path
+}
+
+// SetDirectoryArtifact saves a directory artifact of a run along with its
+// files.
+func (r *RedisStore) SetDirectoryArtifact(ctx context.Context, runID string, a types.SavedArtifact,
+	files []types.ArtifactFile,
+) error {
+	fields := make(map[string]any, len(files))
+
+	for _, f := range files {
+		b, err := json.Marshal(f)
+		if err != nil {
+			return types.NewInternalErr("could not process artifact file")
+		}
+
+		fields[f.Path] = b
+	}
+
+	_, err := r.Client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		key := r.makeArtifactFilesKey(a.Name, runID)
+
+		p.Del(ctx, key)
+		p.HSet(ctx, key, fields)
+		r.setArtifact(ctx, p, runID, a)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not save directory artifact: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// ArtifactFiles pulls the files of a directory artifact of a run, sorted by
+// path.
+func (r *RedisStore) ArtifactFiles(ctx context.Context, runID, name string) ([]types.ArtifactFile, error) {
+	m, err := r.Client.HGetAll(ctx, r.makeArtifactFilesKey(name, runID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not pull artifact files: %w", types.ErrInternal, err)
+	}
+
+	files := make([]types.ArtifactFile, 0, len(m))
+
+	for _, raw := range m {
+		var f types.ArtifactFile
+
+		if err := json.Unmarshal([]byte(raw), &f); err != nil {
+			return nil, types.NewInternalErr("could not parse artifact file")
+		}
+
+		files = append(files, f)
+	}
+
+	slices.SortFunc(files, func(a, b types.ArtifactFile) int { return cmp.Compare(a.Path, b.Path) })
+
+	return files, nil
+}
@@ -32,8 +32,20 @@ func (r *RedisStore) Exp(ctx context.Context, expID string) (*types.Experiment,
 	}, nil
 }
 
-// ExpRunIDs returns run ids of an experiment.
+// ExpRunIDs returns the ids of the runs of an experiment, archived runs
+// excluded.
 func (r *RedisStore) ExpRunIDs(ctx context.Context, expID string) ([]string, error) {
+	ids, err := r.Client.SDiff(ctx, r.makeExpKey(expID), ArchivedRunsKey).Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not fetch experiment runs")
+	}
+
+	return ids, nil
+}
+
+// AllExpRunIDs returns the ids of all runs of an experiment, archived runs
+// included.
+func (r *RedisStore) AllExpRunIDs(ctx context.Context, expID string) ([]string, error) {
 	p := r.Client.Pipeline()
 
 	res := r.expRunIDs(ctx, p, expID)
@@ -55,9 +67,10 @@ func (r *RedisStore) expRunIDs(ctx context.Context, p redis.Pipeliner, expID str
 	return p.SMembers(ctx, r.makeExpKey(expID))
 }
 
-// ExpExists returns whether experiment exists.
+// ExpExists returns whether experiment exists, either because runs were
+// recorded under it or because it was created with CreateExp.
 func (r *RedisStore) ExpExists(ctx context.Context, expID string) error {
-	c, err := r.Client.Exists(ctx, r.makeExpKey(expID)).Result()
+	c, err := r.Client.Exists(ctx, r.makeExpKey(expID), r.makeExperimentInfoKey(expID)).Result()
 	if err != nil {
 		return types.NewInternalErr("could not check if Experiment exists")
 	}
@@ -134,12 +147,177 @@ func (r *RedisStore) SetExpInfo(ctx context.Context, expID string, info types.Ex
 		return errors.New("invalid expID") //nolint: err113
 	}
 
+	fields := info.Hash()
+	if len(fields) == 0 {
+		return nil
+	}
+
 	key := r.makeExperimentInfoKey(expID)
 
-	err := r.Client.HSet(ctx, key, info).Err()
+	err := r.Client.HSet(ctx, key, fields).Err()
 	if err != nil {
 		return fmt.Errorf("could not save experiment info: %w", err)
 	}
 
 	return nil
 }
+
+// CreateExp creates an experiment with no runs yet and adds it to
+// ExpsIndexKey. It fails with ErrAlreadyInUse if the experiment exists.
+func (r *RedisStore) CreateExp(ctx context.Context, expID string, info types.ExperimentInfo) error {
+	expKey := r.makeExpKey(expID)
+	infoKey := r.makeExperimentInfoKey(expID)
+
+	// the conflict is returned as is rather than as a transaction failure,
+	// and the score only allocated once the experiment is known to be new
+	var (
+		conflict error
+		score    int64
+	)
+
+	fn := func(tx *redis.Tx) error {
+		c, err := tx.Exists(ctx, expKey, infoKey).Result()
+		if err != nil {
+			return fmt.Errorf("could not check if experiment exists: %w", err)
+		}
+
+		if c > 0 {
+			conflict = types.NewAlreadyInUseErr(fmt.Sprintf("experiment <%s> already exists", expID))
+
+			return nil
+		}
+
+		if score == 0 {
+			score, err = tx.Incr(ctx, ExpsCounterKey).Result()
+			if err != nil {
+				return fmt.Errorf("could not allocate experiment index score: %w", err)
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.HSet(ctx, infoKey, info.Hash())
+			p.ZAddNX(ctx, ExpsIndexKey, redis.Z{Score: float64(score), Member: expID})
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not run Tx: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := r.runTx(ctx, fn, transactionMaxTries, expKey, infoKey); err != nil {
+		return err
+	}
+
+	return conflict
+}
+
+// UpdateExpInfo applies update to an experiment's info.
+func (r *RedisStore) UpdateExpInfo(ctx context.Context, expID string, update types.UpdateExperiment) error {
+	key := r.makeExperimentInfoKey(expID)
+
+	keyVals := make(map[string]any, 3+len(update.Tags)) //nolint: mnd
+
+	if update.Name != nil {
+		keyVals["Name"] = *update.Name
+	}
+
+	if update.Description != nil {
+		keyVals["Description"] = *update.Description
+	}
+
+	if update.Owner != nil {
+		keyVals["Owner"] = *update.Owner
+	}
+
+	for k, v := range update.Tags {
+		keyVals[types.ExpTagField(k)] = v
+	}
+
+	removed := make([]string, 0, len(update.RemoveTags))
+
+	for _, k := range update.RemoveTags {
+		if _, ok := update.Tags[k]; !ok {
+			removed = append(removed, types.ExpTagField(k))
+		}
+	}
+
+	_, err := r.Client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		if len(removed) > 0 {
+			p.HDel(ctx, key, removed...)
+		}
+
+		if len(keyVals) > 0 {
+			p.HSet(ctx, key, keyVals)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not update experiment info: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// DeleteExp removes an experiment's run index and info, and drops it from
+// the experiment indexes. Its runs must be deleted first (see DeleteRun).
+func (r *RedisStore) DeleteExp(ctx context.Context, expID string) error {
+	_, err := r.Client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, r.makeExpKey(expID), r.makeExperimentInfoKey(expID), r.makeExpStorageUsageKey(expID))
+		p.ZRem(ctx, ExpsIndexKey, expID)
+		p.ZRem(ctx, ArchivedExpsKey, expID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not delete experiment: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// SetExpArchived archives an experiment, moving it from ExpsIndexKey to
+// ArchivedExpsKey so it is hidden from experiment listings, or restores it.
+// It is a no-op if the experiment already is in the requested state.
+func (r *RedisStore) SetExpArchived(ctx context.Context, expID string, archived bool) error {
+	from, to := ExpsIndexKey, ArchivedExpsKey
+	if !archived {
+		from, to = to, from
+	}
+
+	fn := func(tx *redis.Tx) error {
+		score, err := tx.ZScore(ctx, from, expID).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("could not read experiment index: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.ZAdd(ctx, to, redis.Z{Score: score, Member: expID})
+			p.ZRem(ctx, from, expID)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not run Tx: %w", err)
+		}
+
+		return nil
+	}
+
+	return r.runTx(ctx, fn, transactionMaxTries, ExpsIndexKey, ArchivedExpsKey)
+}
+
+// ArchivedExps returns the ids of archived experiments.
+func (r *RedisStore) ArchivedExps(ctx context.Context) ([]string, error) {
+	ids, err := r.zIndexAll(ctx, ArchivedExpsKey)
+	if err != nil {
+		return nil, types.NewInternalErr("could not fetch archived experiments")
+	}
+
+	return ids, nil
+}
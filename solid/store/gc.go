@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// ObjectRefs returns the object store keys referenced from the store, each
+// mapped to what references it: the keys of artifact hashes and blobs, the
+// refs of the files of directory artifacts (see ArtifactFileRef) and of
+// model entries (see ModelEntryRef and ModelEntryFileRef). The urls of model
+// entries are returned as they are, though they may point outside of the
+// object store.
+func (r *RedisStore) ObjectRefs(ctx context.Context) (map[string][]string, error) {
+	refs := make(map[string][]string)
+
+	add := func(key, ref string) {
+		if key != "" {
+			refs[key] = append(refs[key], ref)
+		}
+	}
+
+	artifacts, err := r.hashFields(ctx, fmt.Sprintf(ArtifactKeyPattern, "*", "*"), "S3Key")
+	if err != nil {
+		return nil, err
+	}
+
+	for ref, key := range artifacts {
+		add(key, ref)
+	}
+
+	blobs, err := r.hashFields(ctx, fmt.Sprintf(BlobKeyPattern, "*"), "Key")
+	if err != nil {
+		return nil, err
+	}
+
+	for ref, key := range blobs {
+		add(key, ref)
+	}
+
+	files, err := r.allArtifactFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for ref, fs := range files {
+		for _, f := range fs {
+			add(f.S3Key, ref+"/"+f.Path)
+		}
+	}
+
+	registries, err := r.ModelRegistries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, registry := range registries {
+		for _, m := range registry.Models {
+			ref := r.ModelEntryRef(registry.Name, m.Version)
+
+			add(m.URL, ref)
+
+			for _, f := range m.Files {
+				add(f.S3Key, r.ModelEntryFileRef(registry.Name, m.Version, f.Path))
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+// PendingObjects returns the object store keys of artifact uploads in
+// progress and of objects queued for deletion, which are neither orphaned
+// nor expected to exist yet.
+func (r *RedisStore) PendingObjects(ctx context.Context) (map[string]struct{}, error) {
+	uploads, err := r.hashFields(ctx, fmt.Sprintf(UploadSessionKeyPattern, "*"), "S3Key")
+	if err != nil {
+		return nil, err
+	}
+
+	queued, err := r.Client.ZRange(ctx, ArtifactDeletionQueueKey, 0, -1).Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not read artifact deletion queue")
+	}
+
+	pending := make(map[string]struct{}, len(uploads)+len(queued))
+
+	for _, key := range uploads {
+		pending[key] = struct{}{}
+	}
+
+	for _, key := range queued {
+		pending[key] = struct{}{}
+	}
+
+	return pending, nil
+}
+
+// hashFields returns the value of field of each hash whose key matches
+// pattern, mapped by key.
+func (r *RedisStore) hashFields(ctx context.Context, pattern, field string) (map[string]string, error) {
+	keys, err := r.scanKeys(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+
+	_, err = r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = p.HGet(ctx, key, field)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("%w: could not pull %s of %s: %w", types.ErrInternal, field, pattern, err)
+	}
+
+	values := make(map[string]string, len(keys))
+
+	for i, key := range keys {
+		values[key] = cmds[i].Val()
+	}
+
+	return values, nil
+}
+
+// allArtifactFiles pulls the files of every directory artifact, mapped by
+// the ref of their artifact (see ArtifactRef).
+func (r *RedisStore) allArtifactFiles(ctx context.Context) (map[string][]types.ArtifactFile, error) {
+	keys, err := r.scanKeys(ctx, fmt.Sprintf(ArtifactFilesKeyPattern, "*", "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+
+	_, err = r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = p.HGetAll(ctx, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not pull artifact files: %w", types.ErrInternal, err)
+	}
+
+	files := make(map[string][]types.ArtifactFile, len(keys))
+
+	for i, key := range keys {
+		// files:artifact:<name>:<run> is the files of artifact:<name>:<run>
+		ref := strings.TrimPrefix(key, "files:")
+
+		for _, raw := range cmds[i].Val() {
+			var f types.ArtifactFile
+
+			if err := json.Unmarshal([]byte(raw), &f); err != nil {
+				return nil, types.NewInternalErr("could not parse artifact file")
+			}
+
+			files[ref] = append(files[ref], f)
+		}
+	}
+
+	return files, nil
+}
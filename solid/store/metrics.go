@@ -1,34 +1,118 @@
 package store
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/zeddo123/mlsolid/solid/types"
 )
 
-// SetMetrics sets the metrics of a run by its id.
+// SetMetrics appends the values of the metrics ms to the metric streams of
+// a run. Values keep the kind of the stream they are appended to: int values
+// are appended to float streams as floats, any other mismatch being an
+// ErrBadRequest error (see types.CoerceMetric). The kinds of new streams are
+// checked and set in the same transaction as the values are appended, so
+// concurrent first writes of values of different kinds cannot both succeed.
 func (r *RedisStore) SetMetrics(ctx context.Context, runID string, ms map[string]types.Metric) error {
-	_, err := r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
-		r.setMetrics(ctx, p, runID, ms)
+	names := slices.Collect(maps.Keys(ms))
 
-		return nil
-	})
+	kinds, err := r.metricKinds(ctx, &r.Client, runID, names)
 	if err != nil {
-		return fmt.Errorf("pipeline failed: %w", err)
+		return err
 	}
 
-	return nil
+	// the kind of a stream never changes once set, so only the streams
+	// without one yet need watching
+	keys := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if _, ok := kinds[name]; !ok {
+			keys = append(keys, r.makeMetricKey(name, runID))
+		}
+	}
+
+	// kind mismatches, unsupported values and read failures abort the
+	// transaction, and are returned as is rather than as transaction failures
+	var fnErr error
+
+	fn := func(tx *redis.Tx) error {
+		var kinds map[string]types.ValueKind
+
+		kinds, fnErr = r.metricKinds(ctx, tx, runID, names)
+		if fnErr != nil {
+			return nil
+		}
+
+		coerced := make(map[string]types.Metric, len(ms))
+
+		for name, m := range ms {
+			kind, ok := kinds[name]
+			if !ok {
+				coerced[name] = m
+
+				continue
+			}
+
+			coerced[name], fnErr = types.CoerceMetric(m, kind)
+			if fnErr != nil {
+				return nil
+			}
+		}
+
+		_, err := tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			fnErr = r.setMetrics(ctx, p, runID, coerced)
+			if fnErr != nil {
+				return fnErr
+			}
+
+			heartbeatRun(ctx, p, runID)
+
+			return nil
+		})
+		if fnErr != nil {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("could not run Tx: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := r.runTx(ctx, fn, transactionMaxTries, keys...); err != nil {
+		return err
+	}
+
+	return fnErr
 }
 
 // SetMetric sets the metric for a run.
 func (r *RedisStore) SetMetric(ctx context.Context, runID string, m types.Metric) error {
+	var setErr error
+
 	_, err := r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
-		r.setMetric(ctx, p, runID, m)
+		setErr = r.setMetric(ctx, p, runID, m)
+		if setErr != nil {
+			return setErr
+		}
+
+		heartbeatRun(ctx, p, runID)
 
 		return nil
 	})
+	if setErr != nil {
+		return setErr
+	}
+
 	if err != nil {
 		return fmt.Errorf("pipeline failed: %w", err)
 	}
@@ -43,6 +127,90 @@ func (r *RedisStore) RunMetrics(ctx context.Context, runID string) ([]string, er
 	return r.scanKeys(ctx, pattern)
 }
 
+// RunMetricNames returns the names of all metrics of a run.
+func (r *RedisStore) RunMetricNames(ctx context.Context, runID string) ([]string, error) {
+	keys, err := r.RunMetrics(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		name, ok := strings.CutPrefix(key, "metric:")
+		if !ok {
+			continue
+		}
+
+		if name, ok = strings.CutSuffix(name, ":"+runID); ok {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// MetricEvents reads the values logged to the metrics names of a run past
+// cursor's position in each of their streams, in stream id order. When no
+// value is available yet it blocks for up to block before returning an
+// empty slice; a negative block never waits. The cursor is not advanced.
+func (r *RedisStore) MetricEvents(ctx context.Context, runID string, names []string,
+	cursor *types.MetricCursor, block time.Duration,
+) ([]types.MetricEvent, error) {
+	if len(names) == 0 {
+		return []types.MetricEvent{}, nil
+	}
+
+	streams := make([]string, 2*len(names)) //nolint: mnd
+	keyNames := make(map[string]string, len(names))
+
+	for i, name := range names {
+		streams[i] = r.makeMetricKey(name, runID)
+		streams[len(names)+i] = cursor.Position(name)
+		keyNames[streams[i]] = name
+	}
+
+	res, err := r.Client.XRead(ctx, &redis.XReadArgs{ //nolint: exhaustruct
+		Streams: streams,
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return []types.MetricEvent{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: could not read metric streams: %w", types.ErrInternal, err)
+	}
+
+	events := make([]types.MetricEvent, 0)
+
+	for _, stream := range res {
+		kind := r.streamKind(stream.Messages)
+
+		for _, msg := range stream.Messages {
+			val, err := r.parseMetricVal(msg, kind)
+			if err != nil {
+				r.Logger.Error().Err(err).Any("values", msg.Values).Msg("could not parse metric value")
+
+				continue
+			}
+
+			events = append(events, types.MetricEvent{
+				ID:     msg.ID,
+				Metric: keyNames[stream.Stream],
+				MetricPoint: types.MetricPoint{
+					Value:   val,
+					ValMeta: r.parseValMeta(msg),
+				},
+			})
+		}
+	}
+
+	slices.SortStableFunc(events, func(a, b types.MetricEvent) int {
+		return compareStreamIDs(a.ID, b.ID)
+	})
+
+	return events, nil
+}
+
 // Metrics returns all metrics of a run.
 func (r *RedisStore) Metrics(ctx context.Context, runID string) (map[string]types.Metric, error) {
 	keys, err := r.scanKeys(ctx, runID)
@@ -62,36 +230,195 @@ func (r *RedisStore) Metrics(ctx context.Context, runID string) (map[string]type
 	return r.parseMetrics(ctx, res)
 }
 
+// RunsMetric returns the values of a metric logged by each run of ids within
+// q's time window, keyed by run id. Runs without values in the window are
+// left out. Only the time window is applied here, as it maps onto stream
+// ids; see types.ApplyMetricQuery for the rest of q.
+func (r *RedisStore) RunsMetric(ctx context.Context, ids []string, name string,
+	q types.MetricQuery,
+) (map[string]types.Metric, error) {
+	start, end := "-", "+"
+
+	if q.Since != nil {
+		start = strconv.FormatInt(q.Since.UnixMilli(), 10)
+	}
+
+	if q.Until != nil {
+		end = strconv.FormatInt(q.Until.UnixMilli(), 10)
+	}
+
+	p := r.Client.Pipeline()
+
+	res := make(map[string]*redis.XMessageSliceCmd, len(ids))
+
+	for _, id := range ids {
+		key := r.makeMetricKey(name, id)
+
+		// without a step window the last value is the last stream entry
+		if q.LastOnly && !q.HasStepWindow() {
+			res[id] = p.XRevRangeN(ctx, key, end, start, 1)
+		} else {
+			res[id] = p.XRange(ctx, key, start, end)
+		}
+	}
+
+	_, err := p.Exec(ctx)
+	if err != nil {
+		return nil, types.NewInternalErr("could not pull metrics from redis")
+	}
+
+	metrics := make(map[string]types.Metric, len(ids))
+
+	for id, cmd := range res {
+		if len(cmd.Val()) == 0 {
+			continue
+		}
+
+		m, err := r.parseMetric(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics[id] = m
+	}
+
+	return metrics, nil
+}
+
+// setMetrics queues the uncommitted values of the metrics ms on p. It fails,
+// queuing nothing more, on the first metric that cannot be stored.
 func (r *RedisStore) setMetrics(ctx context.Context, p redis.Pipeliner,
 	runID string, ms map[string]types.Metric,
-) map[string][]*redis.StringCmd { //nolint: unparam
-	res := make(map[string][]*redis.StringCmd, 0)
-
-	for key, val := range ms {
-		res[key] = r.setMetric(ctx, p, runID, val)
+) error {
+	for _, m := range ms {
+		if err := r.setMetric(ctx, p, runID, m); err != nil {
+			return err
+		}
 	}
 
-	return res
+	return nil
 }
 
+// setMetric queues the uncommitted values of m on p. Values of Go number
+// types other than int64 and float64 are stored as such (see
+// types.CanonicalMetric); values of any other unsupported type are an
+// ErrBadRequest error.
 func (r *RedisStore) setMetric(ctx context.Context, p redis.Pipeliner,
 	runID string, m types.Metric,
-) []*redis.StringCmd {
+) error {
+	m, err := types.CanonicalMetric(m)
+	if err != nil {
+		return err
+	}
+
+	kind, ok := types.MetricKind(m)
+	if !ok {
+		return types.NewBadRequest(fmt.Sprintf("metric %q holds values of an unsupported type", m.Name()))
+	}
+
 	key := r.makeMetricKey(m.Name(), runID)
 	vals := m.ValsToCommit()
-	cmds := make([]*redis.StringCmd, len(vals))
+	metas := m.MetasToCommit()
+
+	// encoded up front so that a bad value queues none of the metric's values
+	encoded := make([]string, len(vals))
 
 	for i, val := range vals {
-		cmds[i] = p.XAdd(ctx, &redis.XAddArgs{ //nolint: exhaustruct
+		encoded[i], err = types.EncodeVal(val)
+		if err != nil {
+			return fmt.Errorf("%w: could not encode value of metric %q: %w", types.ErrBadRequest, m.Name(), err)
+		}
+	}
+
+	for i := range vals {
+		values := map[string]any{
+			"Name": m.Name(),
+			"Val":  encoded[i],
+			"Type": string(kind),
+		}
+
+		// Step and Time are only present on values logged with them
+		if metas[i].Step != nil {
+			values["Step"] = *metas[i].Step
+		}
+
+		if metas[i].Timestamp != nil {
+			values["Time"] = metas[i].Timestamp.Format(time.RFC3339Nano)
+		}
+
+		p.XAdd(ctx, &redis.XAddArgs{ //nolint: exhaustruct
 			Stream: key,
-			Values: map[string]any{
-				"Name": m.Name(),
-				"Val":  val,
-			},
+			Values: values,
 		})
 	}
 
-	return cmds
+	r.indexMetric(ctx, p, runID, m.Name(), vals)
+	r.summarizeMetric(ctx, p, runID, m.Name(), vals, metas)
+
+	return nil
+}
+
+// metricKinds returns the kind of the values of the metrics names of a run,
+// as recorded with its last value. Metrics not logged yet, or only logged
+// before kinds were recorded, are left out.
+func (r *RedisStore) metricKinds(ctx context.Context, c redis.Cmdable, runID string, names []string,
+) (map[string]types.ValueKind, error) {
+	p := c.Pipeline()
+
+	res := make(map[string]*redis.XMessageSliceCmd, len(names))
+	for _, name := range names {
+		res[name] = p.XRevRangeN(ctx, r.makeMetricKey(name, runID), "+", "-", 1)
+	}
+
+	_, err := p.Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read metric kinds: %w", types.ErrInternal, err)
+	}
+
+	kinds := make(map[string]types.ValueKind, len(names))
+
+	for name, cmd := range res {
+		for _, msg := range cmd.Val() {
+			if kind, ok := msg.Values["Type"].(string); ok {
+				kinds[name] = types.ValueKind(kind)
+			}
+		}
+	}
+
+	return kinds, nil
+}
+
+// indexMetric updates the last/min/max indexes of a metric (see
+// RunMetricIndexKeyPattern) with newly logged values. Non-numeric values are
+// not indexed.
+func (r *RedisStore) indexMetric(ctx context.Context, p redis.Pipeliner, runID, name string, vals []any) {
+	var (
+		last, low, high float64
+		found           bool
+	)
+
+	for _, val := range vals {
+		v, ok := types.NumericVal(val)
+		if !ok {
+			continue
+		}
+
+		if !found {
+			low, high = v, v
+			found = true
+		}
+
+		last, low, high = v, min(low, v), max(high, v)
+	}
+
+	if !found {
+		return
+	}
+
+	p.ZAdd(ctx, r.makeRunMetricIndexKey(name, types.MetricLast), redis.Z{Score: last, Member: runID})
+	// LT/GT only move an existing score down/up, still adding missing members
+	p.ZAddLT(ctx, r.makeRunMetricIndexKey(name, types.MetricMin), redis.Z{Score: low, Member: runID})
+	p.ZAddGT(ctx, r.makeRunMetricIndexKey(name, types.MetricMax), redis.Z{Score: high, Member: runID})
 }
 
 func (r *RedisStore) metrics(ctx context.Context, p redis.Pipeliner, keys []string) []*redis.XMessageSliceCmd {
@@ -112,40 +439,106 @@ func (r *RedisStore) parseMetric(_ context.Context, //nolint: ireturn
 		return nil, types.NewInternalErr("could not fetch metric")
 	}
 
-	vals := make([]any, len(msgs))
+	if len(msgs) == 0 {
+		return nil, types.NewInternalErr("metric has no readable values")
+	}
 
-	for i, m := range msgs {
-		val, ok := m.Values["Val"].(string)
-		if !ok {
-			r.Logger.Error().Any("values", m.Values).Msg("could not cast value to string")
+	name, ok := msgs[0].Values["Name"].(string)
+	if !ok {
+		r.Logger.Error().Any("name", msgs[0].Values["Name"]).Msg("could not cast name to string")
+
+		return nil, fmt.Errorf("could not cast metric name to string %v: %w", msgs[0].Values["Name"], types.ErrInternal)
+	}
+
+	kind := r.streamKind(msgs)
+
+	vals := make([]any, 0, len(msgs))
+	metas := make([]types.ValMeta, 0, len(msgs))
+
+	for _, m := range msgs {
+		val, err := r.parseMetricVal(m, kind)
+		if err != nil {
+			r.Logger.Error().Err(err).Any("values", m.Values).Msg("could not parse metric value")
 
 			continue
 		}
 
-		vals[i] = types.ParseVal(val)
+		vals = append(vals, val)
+		metas = append(metas, r.parseValMeta(m))
 	}
 
-	var g types.Metric
+	if len(vals) == 0 {
+		return nil, types.NewInternalErr("metric has no readable values")
+	}
 
-	name, ok := msgs[0].Values["Name"].(string)
+	g, err := types.NewMetricOfKind(name, kind, len(vals))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", types.ErrInternal, err)
+	}
+
+	g.SetVals(vals)
+	g.SetMetas(metas)
+
+	return g, nil
+}
+
+// streamKind returns the kind of the values of a metric stream: the one
+// recorded with its values, or the one inferred from them for streams
+// logged before kinds were recorded (see types.InferKind).
+func (r *RedisStore) streamKind(msgs []redis.XMessage) types.ValueKind {
+	raw := make([]string, 0, len(msgs))
+
+	for _, m := range msgs {
+		if kind, ok := m.Values["Type"].(string); ok {
+			return types.ValueKind(kind)
+		}
+
+		if val, ok := m.Values["Val"].(string); ok {
+			raw = append(raw, val)
+		}
+	}
+
+	return types.InferKind(raw)
+}
+
+// parseMetricVal decodes the value of a metric stream entry as a value of
+// kind, the kind of the stream.
+func (r *RedisStore) parseMetricVal(m redis.XMessage, kind types.ValueKind) (any, error) {
+	val, ok := m.Values["Val"].(string)
 	if !ok {
-		r.Logger.Error().Any("name", msgs[0].Values["Name"]).Msg("could not cast name to string")
+		return nil, types.NewInternalErr("could not cast value to string")
+	}
 
-		return g, fmt.Errorf("could not cast metric name to string %v: %w", msgs[0].Values["Name"], types.ErrInternal)
+	v, err := types.DecodeVal(val, kind)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", types.ErrInternal, err)
 	}
 
-	switch vals[0].(type) {
-	case int, int64, int32:
-		g = &types.GenericMetric[int64]{Key: name} //nolint: exhaustruct
-	case float32, float64:
-		g = &types.GenericMetric[float64]{Key: name} //nolint: exhaustruct
-	case string:
-		g = &types.GenericMetric[string]{Key: name} //nolint: exhaustruct
+	return v, nil
+}
+
+func (r *RedisStore) parseValMeta(m redis.XMessage) types.ValMeta {
+	meta := types.ValMeta{} //nolint: exhaustruct
+
+	if raw, ok := m.Values["Step"].(string); ok {
+		step, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			r.Logger.Error().Err(err).Str("step", raw).Msg("could not parse metric value step")
+		} else {
+			meta.Step = &step
+		}
 	}
 
-	g.SetVals(vals)
+	if raw, ok := m.Values["Time"].(string); ok {
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			r.Logger.Error().Err(err).Str("time", raw).Msg("could not parse metric value time")
+		} else {
+			meta.Timestamp = &t
+		}
+	}
 
-	return g, nil
+	return meta
 }
 
 func (r *RedisStore) parseMetrics(ctx context.Context, res []*redis.XMessageSliceCmd) (map[string]types.Metric, error) {
@@ -160,3 +553,19 @@ func (r *RedisStore) parseMetrics(ctx context.Context, res []*redis.XMessageSlic
 
 	return mapping, nil
 }
+
+// compareStreamIDs compares two redis stream entry ids (<ms>-<seq>).
+func compareStreamIDs(a, b string) int {
+	parse := func(id string) (uint64, uint64) {
+		ms, seq, _ := strings.Cut(id, "-")
+		msv, _ := strconv.ParseUint(ms, 10, 64)
+		seqv, _ := strconv.ParseUint(seq, 10, 64)
+
+		return msv, seqv
+	}
+
+	ams, aseq := parse(a)
+	bms, bseq := parse(b)
+
+	return cmp.Or(cmp.Compare(ams, bms), cmp.Compare(aseq, bseq))
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// reserveStorageSrc adds ARGV[1] bytes of ARGV[2] artifacts to the storage
+// usage of a run KEYS[1] and of its experiment KEYS[2], unless it takes the
+// run over ARGV[3] bytes or the experiment over ARGV[4] bytes (0 being
+// unlimited), in which case 1 or 2 is returned respectively, and 0
+// otherwise.
+const reserveStorageSrc = `
+local size = tonumber(ARGV[1])
+local run = tonumber(redis.call('HGET', KEYS[1], 'Bytes') or '0')
+local exp = tonumber(redis.call('HGET', KEYS[2], 'Bytes') or '0')
+
+if tonumber(ARGV[3]) > 0 and run + size > tonumber(ARGV[3]) then
+  return 1
+end
+
+if tonumber(ARGV[4]) > 0 and exp + size > tonumber(ARGV[4]) then
+  return 2
+end
+
+for _, key in ipairs(KEYS) do
+  redis.call('HINCRBY', key, 'Bytes', size)
+  redis.call('HINCRBY', key, 'Artifacts', ARGV[2])
+end
+
+return 0
+`
+
+// results of reserveStorageSrc.
+const (
+	runOverQuota = 1
+	expOverQuota = 2
+)
+
+var reserveStorageScript = redis.NewScript(reserveStorageSrc) //nolint: gochecknoglobals
+
+// ReserveStorage adds size bytes of count artifacts to the storage usage of
+// a run and of its experiment, unless it takes either over its quota, in
+// which case an ErrResourceExhausted error is returned.
+func (r *RedisStore) ReserveStorage(ctx context.Context, runID string, size int64, count int,
+	quota types.StorageQuota,
+) error {
+	expID, err := r.runExperiment(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{r.makeRunStorageUsageKey(runID), r.makeExpStorageUsageKey(expID)}
+
+	res, err := reserveStorageScript.Run(ctx, &r.Client, keys, size, count, quota.Run, quota.Experiment).Int()
+	if err != nil {
+		return fmt.Errorf("%w: could not reserve artifact storage: %w", types.ErrInternal, err)
+	}
+
+	switch res {
+	case runOverQuota:
+		return types.NewResourceExhaustedErr(fmt.Sprintf("%d bytes of artifacts exceed the storage quota of run <%s>",
+			size, runID))
+	case expOverQuota:
+		return types.NewResourceExhaustedErr(fmt.Sprintf(
+			"%d bytes of artifacts exceed the storage quota of experiment <%s>", size, expID))
+	default:
+		return nil
+	}
+}
+
+// ReleaseStorage removes size bytes of count artifacts from the storage
+// usage of a run and of its experiment, e.g. reserved for artifacts that
+// could not be saved.
+func (r *RedisStore) ReleaseStorage(ctx context.Context, runID string, size int64, count int) error {
+	expID, err := r.runExperiment(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, key := range []string{r.makeRunStorageUsageKey(runID), r.makeExpStorageUsageKey(expID)} {
+			p.HIncrBy(ctx, key, "Bytes", -size)
+			p.HIncrBy(ctx, key, "Artifacts", int64(-count))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not release artifact storage: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// releaseRunStorage deletes the storage usage of a run, usage, stored at
+// usageKey, and removes it from the storage usage of its experiment at
+// expUsageKey.
+func releaseRunStorage(ctx context.Context, p redis.Pipeliner, expUsageKey, usageKey string,
+	usage types.StorageUsage,
+) {
+	p.Del(ctx, usageKey)
+
+	if usage.Bytes != 0 || usage.Artifacts != 0 {
+		p.HIncrBy(ctx, expUsageKey, "Bytes", -usage.Bytes)
+		p.HIncrBy(ctx, expUsageKey, "Artifacts", -usage.Artifacts)
+	}
+}
+
+// RunStorageUsage pulls the storage used by the artifacts of a run.
+func (r *RedisStore) RunStorageUsage(ctx context.Context, runID string) (types.StorageUsage, error) {
+	return r.storageUsage(ctx, r.makeRunStorageUsageKey(runID))
+}
+
+// ExpStorageUsage pulls the storage used by the artifacts of the runs of an
+// experiment.
+func (r *RedisStore) ExpStorageUsage(ctx context.Context, expID string) (types.StorageUsage, error) {
+	return r.storageUsage(ctx, r.makeExpStorageUsageKey(expID))
+}
+
+func (r *RedisStore) storageUsage(ctx context.Context, key string) (types.StorageUsage, error) {
+	m, err := r.Client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return types.StorageUsage{}, fmt.Errorf("%w: could not pull storage usage: %w", types.ErrInternal, err)
+	}
+
+	return parseStorageUsage(m), nil
+}
+
+// parseStorageUsage parses a storage usage hash, whose quota is left unset.
+func parseStorageUsage(m map[string]string) types.StorageUsage {
+	size, _ := strconv.ParseInt(m["Bytes"], 10, 64)
+	count, _ := strconv.ParseInt(m["Artifacts"], 10, 64)
+
+	return types.StorageUsage{Bytes: size, Artifacts: count, Quota: 0}
+}
+
+// runExperiment returns the experiment id of a run.
+func (r *RedisStore) runExperiment(ctx context.Context, runID string) (string, error) {
+	expID, err := r.Client.HGet(ctx, r.makeRunKey(runID), "ExperimentID").Result()
+	if errors.Is(err, redis.Nil) {
+		return "", types.NewNotFoundErr(fmt.Sprintf("run <%s> not found", runID))
+	} else if err != nil {
+		return "", fmt.Errorf("%w: could not pull run experiment: %w", types.ErrInternal, err)
+	}
+
+	return expID, nil
+}
@@ -33,6 +33,19 @@ const (
 	// metric:mse:linear-regression
 	MetricKeyPattern = "metric:%s:%s"
 
+	// RunParamsKeyPattern pattern of the hash holding a run's params, each
+	// field being a param name and its value encoded with types.Param.Encode.
+	// Example
+	// params:linear-regression
+	RunParamsKeyPattern = "params:%s"
+
+	// RunMetricSummaryKeyPattern pattern of the hash holding the summary
+	// statistics of a run's numeric metrics (see types.MetricSummary), each
+	// field being "<stat>:<metric_name>".
+	// Example
+	// summary:run:linear-regression -> {Min:mse: 0.12, MinStep:mse: 40, ...}
+	RunMetricSummaryKeyPattern = "summary:run:%s"
+
 	// ArtifactKeyPattern pattern of a artifact's key
 	// Example
 	// artifact:logs:linear-regression
@@ -79,8 +92,9 @@ const (
 	// increasing scores to new entries in BenchmarksKey.
 	BenchmarksCounterKey = "counter:benchs"
 
-	// ExpsIndexKey is a Sorted Set index of all known experiment ids, scored by
-	// insertion order (see ExpsCounterKey). Populated the first time a run is
+	// ExpsIndexKey is a Sorted Set index of all known, unarchived experiment
+	// ids, scored by insertion order (see ExpsCounterKey). Populated when an
+	// experiment is created with CreateExp, or the first time a run is
 	// recorded under a given experiment id.
 	ExpsIndexKey = "index:exps"
 
@@ -88,6 +102,87 @@ const (
 	// increasing scores to new entries in ExpsIndexKey.
 	ExpsCounterKey = "counter:exps"
 
+	// RunningRunsKey is a Sorted Set of the ids of runs in the running status,
+	// scored by the unix time of their last heartbeat (creation, status
+	// update, or metrics being logged). Used to reap runs whose client
+	// stopped reporting.
+	RunningRunsKey = "index:runs:running"
+
+	// ArchivedRunsKey is a Set of the ids of archived runs, hidden from
+	// experiment listings and searches until restored.
+	ArchivedRunsKey = "index:runs:archived"
+
+	// ArchivedExpsKey is a Sorted Set of archived experiment ids, moved out of
+	// ExpsIndexKey with their score so restoring puts them back in place.
+	ArchivedExpsKey = "index:exps:archived"
+
+	// ArtifactDeletionQueueKey is a Sorted Set of the S3 keys of deleted
+	// artifacts waiting to be removed from the object store, scored by the
+	// unix time they were queued.
+	ArtifactDeletionQueueKey = "queue:artifacts:delete"
+
+	// ArtifactFilesKeyPattern pattern of the hash holding the files of a
+	// directory artifact, each field being the path of a file and its value
+	// the json encoded types.ArtifactFile.
+	// Example
+	// files:artifact:bert:linear-regression -> {config.json: {"path": ...}, ...}
+	ArtifactFilesKeyPattern = "files:artifact:%s:%s"
+
+	// BlobKeyPattern pattern of the hash holding the object store key and
+	// size of a blob (see types.Blob), keyed by the SHA-256 of its content.
+	// Example
+	// blob:9f86d081884c... -> {Key: artifacts/3f2a9c0e1b7d-model.pt, Size: 2147483648}
+	BlobKeyPattern = "blob:%s"
+
+	// BlobRefsKeyPattern is a Set of what references a blob: the keys of
+	// artifact hashes and of model entries ("registry:<name>:<version>"). The
+	// blob is deleted when its last reference goes.
+	// It follows this form: refs:blob:<sha256>.
+	BlobRefsKeyPattern = "refs:blob:%s"
+
+	// UploadSessionKeyPattern pattern of the hash holding a resumable
+	// artifact upload session (see types.UploadSession), the ETag of each
+	// uploaded part being in a "Part:<n>" field.
+	// Example
+	// upload:9f86d081884c -> {RunID: linear-regression, Offset: 16777216, Part:1: "etag", ...}
+	UploadSessionKeyPattern = "upload:%s"
+
+	// UploadSessionsKey is a Sorted Set of the ids of artifact upload
+	// sessions, scored by the unix time they expire at. Used to abort
+	// abandoned uploads.
+	UploadSessionsKey = "index:uploads"
+
+	// RunChildrenKeyPattern is a Set of the ids of the runs nested under a
+	// run (see types.Run.ParentID).
+	// It follows this form: index:run:<run-id>:children.
+	RunChildrenKeyPattern = "index:run:%s:children"
+
+	// RunsIndexKey is a Sorted Set of the ids of all runs, scored by the unix
+	// time of their creation. Used to search runs across experiments.
+	RunsIndexKey = "index:runs"
+
+	// RunMetricIndexKeyPattern is a Sorted Set of run ids scored by the last,
+	// min or max value of a numeric metric, used to filter and order runs by
+	// metric without reading the metric streams.
+	// It follows this form: index:run:metric:<metric-name>:<last|min|max>.
+	RunMetricIndexKeyPattern = "index:run:metric:%s:%s"
+
+	// RunTagIndexKeyPattern is a Set of the ids of runs tagged with a given
+	// key and value, used to filter runs by tag.
+	// It follows this form: index:run:tag:<key>:<value>.
+	RunTagIndexKeyPattern = "index:run:tag:%s:%s"
+
+	// RunStorageUsageKeyPattern pattern of the hash holding the storage used
+	// by the artifacts of a run (see types.StorageUsage).
+	// Example
+	// usage:run:linear-regression -> {Bytes: 2147483648, Artifacts: 3}
+	RunStorageUsageKeyPattern = "usage:run:%s"
+
+	// ExpStorageUsageKeyPattern pattern of the hash holding the storage used
+	// by the artifacts of the runs of an experiment.
+	// It follows this form: usage:exp:<exp-id>.
+	ExpStorageUsageKeyPattern = "usage:exp:%s"
+
 	// BenchmarkKeyPattern represents the key for a benchmark.
 	BenchmarkKeyPattern = "bench:%s"
 
@@ -105,6 +200,17 @@ const (
 	// It follows this order: bench:<bench-id>:run:<registry-name>:<version>.
 	BenchmarkRunKeyPattern = "bench:%s:run:%s:%d"
 
+	// BenchmarkActiveRunsKeyPattern key pattern of the hash holding the runs
+	// currently in flight for a benchmark, one field per registry/version,
+	// each with its own expiry.
+	// It follows this form: bench:<bench-id>:active.
+	BenchmarkActiveRunsKeyPattern = "bench:%s:active"
+
+	// BenchmarkRunLogsKeyPattern key pattern of the stream holding the container
+	// output (logs and progress lines) of a benchmark run.
+	// It follows this order: bench:<bench-id>:run:<registry-name>:<version>:logs.
+	BenchmarkRunLogsKeyPattern = "bench:%s:run:%s:%d:logs"
+
 	transactionMaxTries = 10
 )
 
@@ -138,10 +244,54 @@ func (r *RedisStore) makeMetricKey(name string, runID string) string {
 	return fmt.Sprintf(MetricKeyPattern, name, runID)
 }
 
+func (r *RedisStore) makeRunTagIndexKey(key, value string) string {
+	return fmt.Sprintf(RunTagIndexKeyPattern, key, value)
+}
+
+func (r *RedisStore) makeRunChildrenKey(runID string) string {
+	return fmt.Sprintf(RunChildrenKeyPattern, runID)
+}
+
+func (r *RedisStore) makeRunMetricIndexKey(name string, agg types.MetricAgg) string {
+	return fmt.Sprintf(RunMetricIndexKeyPattern, name, agg)
+}
+
+func (r *RedisStore) makeRunMetricSummaryKey(runID string) string {
+	return fmt.Sprintf(RunMetricSummaryKeyPattern, runID)
+}
+
+func (r *RedisStore) makeRunParamsKey(runID string) string {
+	return fmt.Sprintf(RunParamsKeyPattern, runID)
+}
+
+func (r *RedisStore) makeRunStorageUsageKey(runID string) string {
+	return fmt.Sprintf(RunStorageUsageKeyPattern, runID)
+}
+
+func (r *RedisStore) makeExpStorageUsageKey(expID string) string {
+	return fmt.Sprintf(ExpStorageUsageKeyPattern, expID)
+}
+
+func (r *RedisStore) makeBlobKey(sha256 string) string {
+	return fmt.Sprintf(BlobKeyPattern, sha256)
+}
+
+func (r *RedisStore) makeBlobRefsKey(sha256 string) string {
+	return fmt.Sprintf(BlobRefsKeyPattern, sha256)
+}
+
+func (r *RedisStore) makeUploadSessionKey(id string) string {
+	return fmt.Sprintf(UploadSessionKeyPattern, id)
+}
+
 func (r *RedisStore) makeArtifactKey(name string, runID string) string {
 	return fmt.Sprintf(ArtifactKeyPattern, name, runID)
 }
 
+func (r *RedisStore) makeArtifactFilesKey(name string, runID string) string {
+	return fmt.Sprintf(ArtifactFilesKeyPattern, name, runID)
+}
+
 func (r *RedisStore) makeModelRegistryKey(name string) string {
 	return fmt.Sprintf(ModelRegistryKeyPattern, name)
 }
@@ -170,6 +320,18 @@ func (r *RedisStore) makeBenchmarkRunKey(benchID, registryName string, version i
 	return fmt.Sprintf(BenchmarkRunKeyPattern, benchID, registryName, version)
 }
 
+func (r *RedisStore) makeBenchmarkActiveRunsKey(benchID string) string {
+	return fmt.Sprintf(BenchmarkActiveRunsKeyPattern, benchID)
+}
+
+func (r *RedisStore) makeActiveBenchRunField(registryName string, version int64) string {
+	return fmt.Sprintf("%s:%d", registryName, version)
+}
+
+func (r *RedisStore) makeBenchmarkRunLogsKey(benchID, registryName string, version int64) string {
+	return fmt.Sprintf(BenchmarkRunLogsKeyPattern, benchID, registryName, version)
+}
+
 func (r *RedisStore) makeRegistryBenchmarksKey(registry string) string {
 	return fmt.Sprintf(ModelRegistryBenchmarksIndexPattern, registry)
 }
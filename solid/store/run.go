@@ -2,7 +2,11 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -13,6 +17,7 @@ import (
 // 1 - runKey (run:runId) is where the run data is stored
 // 2 - expKey (exp:expId) key index for all runs of an experiment
 // 3 - metrics (metric:metricName:runId) where each metric of the run is stored.
+// The experiment of the run is added to ExpsIndexKey, unless it is archived.
 func (r *RedisStore) SetRun(ctx context.Context, run types.Run) error {
 	key := r.makeRunKey(run.Name)
 
@@ -23,15 +28,45 @@ func (r *RedisStore) SetRun(ctx context.Context, run types.Run) error {
 		return fmt.Errorf("could not allocate experiment index score: %w", err)
 	}
 
+	// metrics that cannot be stored abort the transaction, and are returned
+	// as is rather than as a transaction failure
+	var metricsErr error
+
 	fn := func(tx *redis.Tx) error {
-		_, err := tx.Pipelined(ctx, func(p redis.Pipeliner) error {
+		_, err := tx.ZScore(ctx, ArchivedExpsKey, run.ExperimentID).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("could not read archived experiments: %w", err)
+		}
+
+		archived := err == nil
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
 			setRunHash(ctx, p, key, run)
 			addRunToExperimentIndex(ctx, p, r.makeExpKey(run.ExperimentID), run.Name)
-			p.ZAddNX(ctx, ExpsIndexKey, redis.Z{Score: float64(score), Member: run.ExperimentID})
-			r.setMetrics(ctx, p, run.Name, run.Metrics)
+			setParams(ctx, p, r.makeRunParamsKey(run.Name), run.Params)
+			r.setRunTags(ctx, p, run.Name, nil, run.Tags)
 
-			return nil
+			if run.ParentID != "" {
+				p.SAdd(ctx, r.makeRunChildrenKey(run.ParentID), run.Name)
+			}
+
+			if run.Status == types.RunRunning {
+				p.ZAdd(ctx, RunningRunsKey, redis.Z{Score: float64(time.Now().Unix()), Member: run.Name})
+			}
+
+			if !archived {
+				p.ZAddNX(ctx, ExpsIndexKey, redis.Z{Score: float64(score), Member: run.ExperimentID})
+			}
+
+			p.ZAddNX(ctx, RunsIndexKey, redis.Z{Score: float64(run.Timestamp.Unix()), Member: run.Name})
+			metricsErr = r.setMetrics(ctx, p, run.Name, run.Metrics)
+
+			return metricsErr
 		})
+		if metricsErr != nil {
+			return nil
+		}
+
 		if err != nil {
 			return fmt.Errorf("could not run Tx: %w", err)
 		}
@@ -39,7 +74,11 @@ func (r *RedisStore) SetRun(ctx context.Context, run types.Run) error {
 		return nil
 	}
 
-	return r.runTx(ctx, fn, transactionMaxTries, key)
+	if err := r.runTx(ctx, fn, transactionMaxTries, key, ArchivedExpsKey); err != nil {
+		return err
+	}
+
+	return metricsErr
 }
 
 // RunExists checks if a run exists in the redis store.
@@ -66,6 +105,9 @@ func (r *RedisStore) Run(ctx context.Context, id string) (*types.Run, error) {
 	p := r.Client.Pipeline()
 
 	hashRes := readRunHash(ctx, p, key)
+	paramsRes := p.HGetAll(ctx, r.makeRunParamsKey(id))
+	childrenRes := p.SMembers(ctx, r.makeRunChildrenKey(id))
+	summariesRes := p.HGetAll(ctx, r.makeRunMetricSummaryKey(id))
 	metricsRes := r.metrics(ctx, p, metricKeys)
 
 	_, err = p.Exec(ctx)
@@ -73,33 +115,63 @@ func (r *RedisStore) Run(ctx context.Context, id string) (*types.Run, error) {
 		return nil, fmt.Errorf("%w: could not fetch run <%s>", types.ErrInternal, id)
 	}
 
-	return r.parseRun(ctx, hashRes, metricsRes)
+	return r.parseRun(ctx, hashRes, paramsRes, summariesRes, childrenRes, metricsRes)
 }
 
-func (r *RedisStore) parseRun(ctx context.Context, hashRes *redis.MapStringStringCmd,
-	metricsRes []*redis.XMessageSliceCmd,
+func (r *RedisStore) parseRun(ctx context.Context, hashRes, paramsRes, summariesRes *redis.MapStringStringCmd,
+	childrenRes *redis.StringSliceCmd, metricsRes []*redis.XMessageSliceCmd,
 ) (*types.Run, error) {
 	metrics, err := r.parseMetrics(ctx, metricsRes)
 	if err != nil {
 		return nil, types.NewInternalErr("could not parse metrics")
 	}
 
+	params, err := r.parseParams(paramsRes)
+	if err != nil {
+		return nil, err
+	}
+
 	mapping, err := hashRes.Result()
 	if err != nil {
 		return nil, types.NewInternalErr("could not fetch run")
 	}
 
+	children, err := childrenRes.Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not fetch run children")
+	}
+
+	slices.Sort(children)
+
 	timestamp, err := time.Parse(time.RFC3339, mapping["Timestamp"])
 	if err != nil {
 		return nil, types.NewInternalErr("malformed run data")
 	}
 
+	var endTime time.Time
+
+	if end := mapping["EndTime"]; end != "" {
+		endTime, err = time.Parse(time.RFC3339, end)
+		if err != nil {
+			return nil, types.NewInternalErr("malformed run end time")
+		}
+	}
+
 	return &types.Run{ //nolint: exhaustruct
 		Name:         mapping["Name"],
 		Timestamp:    timestamp,
 		ExperimentID: mapping["ExperimentID"],
 		Color:        mapping["Color"],
+		Status:       types.RunStatus(mapping["Status"]),
+		EndTime:      endTime,
+		Params:       params,
+		Tags:         parseRunTags(mapping),
+		Note:         mapping["Note"],
+		ParentID:     mapping["ParentID"],
+		Children:     children,
+		Archived:     mapping["Archived"] == "true",
 		Metrics:      metrics,
+		Summaries:    types.ParseMetricSummaries(summariesRes.Val()),
 	}, nil
 }
 
@@ -113,17 +185,26 @@ func (r *RedisStore) Runs(ctx context.Context, ids []string) ([]*types.Run, erro
 	p := r.Client.Pipeline()
 
 	res := make(map[string]struct {
-		Hash    *redis.MapStringStringCmd
-		Metrics []*redis.XMessageSliceCmd
+		Hash      *redis.MapStringStringCmd
+		Params    *redis.MapStringStringCmd
+		Summaries *redis.MapStringStringCmd
+		Children  *redis.StringSliceCmd
+		Metrics   []*redis.XMessageSliceCmd
 	})
 
 	for _, id := range ids {
 		res[id] = struct {
-			Hash    *redis.MapStringStringCmd
-			Metrics []*redis.XMessageSliceCmd
+			Hash      *redis.MapStringStringCmd
+			Params    *redis.MapStringStringCmd
+			Summaries *redis.MapStringStringCmd
+			Children  *redis.StringSliceCmd
+			Metrics   []*redis.XMessageSliceCmd
 		}{
-			Hash:    readRunHash(ctx, p, r.makeRunKey(id)),
-			Metrics: r.metrics(ctx, p, keys[id]),
+			Hash:      readRunHash(ctx, p, r.makeRunKey(id)),
+			Params:    p.HGetAll(ctx, r.makeRunParamsKey(id)),
+			Summaries: p.HGetAll(ctx, r.makeRunMetricSummaryKey(id)),
+			Children:  p.SMembers(ctx, r.makeRunChildrenKey(id)),
+			Metrics:   r.metrics(ctx, p, keys[id]),
 		}
 	}
 
@@ -135,7 +216,7 @@ func (r *RedisStore) Runs(ctx context.Context, ids []string) ([]*types.Run, erro
 	runs := make([]*types.Run, 0)
 
 	for _, v := range res {
-		run, err := r.parseRun(ctx, v.Hash, v.Metrics)
+		run, err := r.parseRun(ctx, v.Hash, v.Params, v.Summaries, v.Children, v.Metrics)
 		if err == nil {
 			runs = append(runs, run)
 		}
@@ -160,15 +241,412 @@ func (r *RedisStore) pullRunMetricKeys(ctx context.Context, ids []string) (map[s
 	return keys, nil
 }
 
+// RunStatus returns the status of a run.
+func (r *RedisStore) RunStatus(ctx context.Context, runID string) (types.RunStatus, error) {
+	status, err := r.Client.HGet(ctx, r.makeRunKey(runID), "Status").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("%w: could not read run status: %w", types.ErrInternal, err)
+	}
+
+	return types.RunStatus(status), nil
+}
+
+// SetRunStatus moves a run to status, recording end as its end time when the
+// status is terminal. The transition is checked against the run's current
+// status (see types.CanTransition) inside an optimistic transaction, so
+// concurrent updates (e.g. a client finishing a run while the reaper fails
+// it) cannot both win. Moving to running refreshes the run's heartbeat.
+func (r *RedisStore) SetRunStatus(ctx context.Context, runID string, status types.RunStatus, end time.Time) error {
+	key := r.makeRunKey(runID)
+
+	fn := func(tx *redis.Tx) error {
+		current, err := tx.HGet(ctx, key, "Status").Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("could not read run status: %w", err)
+		}
+
+		if !types.CanTransition(types.RunStatus(current), status) {
+			return types.NewInvalidInputErr(fmt.Sprintf("run <%s> cannot go from %q to %q", runID, current, status))
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			setRunStatus(ctx, p, key, runID, status, end)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not run Tx: %w", err)
+		}
+
+		return nil
+	}
+
+	return r.runTx(ctx, fn, transactionMaxTries, key)
+}
+
+// HeartbeatRun records that the client of a running run is still
+// reporting. It is a no-op for runs that are not running.
+func (r *RedisStore) HeartbeatRun(ctx context.Context, runID string) error {
+	_, err := r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		heartbeatRun(ctx, p, runID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not heartbeat run: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// StaleRuns returns the ids of running runs whose last heartbeat is older
+// than before.
+func (r *RedisStore) StaleRuns(ctx context.Context, before time.Time) ([]string, error) {
+	ids, err := r.Client.ZRangeByScore(ctx, RunningRunsKey, &redis.ZRangeBy{ //nolint: exhaustruct
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not pull stale runs: %w", types.ErrInternal, err)
+	}
+
+	return ids, nil
+}
+
+// reapRunSrc marks the run of KEYS[1] (ARGV[1]) failed, ending it at
+// ARGV[3], if its heartbeat in the running index KEYS[2] is still ARGV[2].
+// Index entries of runs that already ended are dropped. It returns 1 when
+// the run is reaped.
+const reapRunSrc = `
+local score = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if not score or tonumber(score) ~= tonumber(ARGV[2]) then
+  return 0
+end
+if redis.call('HGET', KEYS[1], 'Status') ~= ARGV[4] then
+  redis.call('ZREM', KEYS[2], ARGV[1])
+  return 0
+end
+redis.call('HSET', KEYS[1], 'Status', ARGV[5], 'EndTime', ARGV[3])
+redis.call('ZREM', KEYS[2], ARGV[1])
+return 1
+`
+
+var reapRunScript = redis.NewScript(reapRunSrc) //nolint: gochecknoglobals
+
+// ReapRun marks a running run as failed if its last heartbeat is still
+// older than before, ending it at its last heartbeat. It reports whether the
+// run was reaped; a run that heartbeated or changed status in the meantime
+// is left untouched.
+func (r *RedisStore) ReapRun(ctx context.Context, runID string, before time.Time) (bool, error) {
+	score, err := r.Client.ZScore(ctx, RunningRunsKey, runID).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("%w: could not read run heartbeat: %w", types.ErrInternal, err)
+	}
+
+	last := time.Unix(int64(score), 0)
+	if !last.Before(before) {
+		return false, nil
+	}
+
+	// the heartbeat is compared again by the script, so that a run
+	// heartbeating after it was read is not reaped
+	reaped, err := reapRunScript.Run(ctx, &r.Client, []string{r.makeRunKey(runID), RunningRunsKey},
+		runID, strconv.FormatInt(int64(score), 10), formatEndTime(last),
+		string(types.RunRunning), string(types.RunFailed)).Int()
+	if err != nil {
+		return false, fmt.Errorf("%w: could not reap run %s: %w", types.ErrInternal, runID, err)
+	}
+
+	return reaped == 1, nil
+}
+
+// SetParams logs params on a run. Params are immutable: logging a param
+// again with the same value is a no-op, while logging it with a different
+// value fails with an ErrAlreadyInUse error and none of params is saved.
+func (r *RedisStore) SetParams(ctx context.Context, runID string, params map[string]types.Param) error {
+	key := r.makeRunParamsKey(runID)
+
+	// a conflicting param aborts the transaction, and is returned as is
+	// rather than as a transaction failure
+	var conflict error
+
+	fn := func(tx *redis.Tx) error {
+		conflict = nil
+
+		existing, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("could not read run params: %w", err)
+		}
+
+		for name, p := range params {
+			if current, ok := existing[name]; ok && current != p.Encode() {
+				conflict = types.NewAlreadyInUseErr(fmt.Sprintf("param %q is already set to a different value", name))
+
+				return nil
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			setParams(ctx, p, key, params)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not run Tx: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := r.runTx(ctx, fn, transactionMaxTries, key); err != nil {
+		return err
+	}
+
+	return conflict
+}
+
+// RunsParams returns the params of each run in ids.
+func (r *RedisStore) RunsParams(ctx context.Context, ids []string) (map[string]map[string]types.Param, error) {
+	p := r.Client.Pipeline()
+
+	res := make(map[string]*redis.MapStringStringCmd, len(ids))
+	for _, id := range ids {
+		res[id] = p.HGetAll(ctx, r.makeRunParamsKey(id))
+	}
+
+	_, err := p.Exec(ctx)
+	if err != nil {
+		return nil, types.NewInternalErr("could not fetch runs params")
+	}
+
+	params := make(map[string]map[string]types.Param, len(ids))
+
+	for id, cmd := range res {
+		ps, err := r.parseParams(cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		params[id] = ps
+	}
+
+	return params, nil
+}
+
+func (r *RedisStore) parseParams(res *redis.MapStringStringCmd) (map[string]types.Param, error) {
+	mapping, err := res.Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not fetch run params")
+	}
+
+	params := make(map[string]types.Param, len(mapping))
+
+	for name, encoded := range mapping {
+		p, err := types.DecodeParam(encoded)
+		if err != nil {
+			r.Logger.Error().Err(err).Str("param", name).Msg("could not decode run param")
+
+			continue
+		}
+
+		params[name] = p
+	}
+
+	return params, nil
+}
+
+func setParams(ctx context.Context, p redis.Pipeliner, key string, params map[string]types.Param) {
+	if len(params) == 0 {
+		return
+	}
+
+	fields := make(map[string]string, len(params))
+	for name, param := range params {
+		fields[name] = param.Encode()
+	}
+
+	p.HSet(ctx, key, fields)
+}
+
+// runTagFieldPrefix prefix of the run hash fields holding its tags, e.g.
+// "Tag:baseline".
+const runTagFieldPrefix = "Tag:"
+
+// SetRunTags sets tags on a run, overwriting the value of tags already set,
+// and removes the tags listed in remove. The tag index used by
+// ExpRunIDsWithTags is kept in sync.
+func (r *RedisStore) SetRunTags(ctx context.Context, runID string, tags map[string]string, remove []string) error {
+	key := r.makeRunKey(runID)
+
+	fn := func(tx *redis.Tx) error {
+		mapping, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("could not read run tags: %w", err)
+		}
+
+		current := parseRunTags(mapping)
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			for _, k := range remove {
+				if v, ok := current[k]; ok {
+					p.HDel(ctx, key, runTagFieldPrefix+k)
+					p.SRem(ctx, r.makeRunTagIndexKey(k, v), runID)
+				}
+			}
+
+			r.setRunTags(ctx, p, runID, current, tags)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not run Tx: %w", err)
+		}
+
+		return nil
+	}
+
+	return r.runTx(ctx, fn, transactionMaxTries, key)
+}
+
+// SetRunNote sets the free-form (markdown) note of a run.
+func (r *RedisStore) SetRunNote(ctx context.Context, runID string, note string) error {
+	_, err := r.Client.HSet(ctx, r.makeRunKey(runID), "Note", note).Result()
+	if err != nil {
+		return fmt.Errorf("%w: could not set run note: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// RunsExperiment returns the experiment id of each run of ids, keyed by run
+// id. Runs that do not exist are left out.
+func (r *RedisStore) RunsExperiment(ctx context.Context, ids []string) (map[string]string, error) {
+	p := r.Client.Pipeline()
+
+	res := make(map[string]*redis.StringCmd, len(ids))
+	for _, id := range ids {
+		res[id] = p.HGet(ctx, r.makeRunKey(id), "ExperimentID")
+	}
+
+	_, err := p.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("%w: could not pull run experiments: %w", types.ErrInternal, err)
+	}
+
+	exps := make(map[string]string, len(ids))
+
+	for id, cmd := range res {
+		if exp, err := cmd.Result(); err == nil {
+			exps[id] = exp
+		}
+	}
+
+	return exps, nil
+}
+
+// ChildRunIDs returns the ids of the runs nested under a run.
+func (r *RedisStore) ChildRunIDs(ctx context.Context, runID string) ([]string, error) {
+	ids, err := r.Client.SMembers(ctx, r.makeRunChildrenKey(runID)).Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not fetch child runs")
+	}
+
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+// ExpRunIDsWithTags returns the ids of the runs of an experiment tagged with
+// every key/value of tags.
+func (r *RedisStore) ExpRunIDsWithTags(ctx context.Context, expID string, tags map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, r.makeExpKey(expID))
+
+	for k, v := range tags {
+		keys = append(keys, r.makeRunTagIndexKey(k, v))
+	}
+
+	ids, err := r.Client.SInter(ctx, keys...).Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not fetch tagged experiment runs")
+	}
+
+	return r.withoutArchived(ctx, ids)
+}
+
+// setRunTags queues writing tags on a run whose tags are currently current,
+// moving the run between tag index sets when a tag's value changes.
+func (r *RedisStore) setRunTags(ctx context.Context, p redis.Pipeliner, runID string,
+	current, tags map[string]string,
+) {
+	if len(tags) == 0 {
+		return
+	}
+
+	fields := make(map[string]string, len(tags))
+
+	for k, v := range tags {
+		if old, ok := current[k]; ok && old != v {
+			p.SRem(ctx, r.makeRunTagIndexKey(k, old), runID)
+		}
+
+		fields[runTagFieldPrefix+k] = v
+		p.SAdd(ctx, r.makeRunTagIndexKey(k, v), runID)
+	}
+
+	p.HSet(ctx, r.makeRunKey(runID), fields)
+}
+
+func parseRunTags(mapping map[string]string) map[string]string {
+	tags := make(map[string]string)
+
+	for field, v := range mapping {
+		if k, ok := strings.CutPrefix(field, runTagFieldPrefix); ok {
+			tags[k] = v
+		}
+	}
+
+	return tags
+}
+
 func setRunHash(ctx context.Context, p redis.Pipeliner, key string, run types.Run) *redis.IntCmd {
 	return p.HSet(ctx, key, map[string]string{
 		"Name":         run.Name,
 		"Timestamp":    run.Timestamp.Format(time.RFC3339),
 		"ExperimentID": run.ExperimentID,
 		"Color":        run.Color,
+		"Status":       string(run.Status),
+		"EndTime":      formatEndTime(run.EndTime),
+		"ParentID":     run.ParentID,
 	})
 }
 
+func setRunStatus(ctx context.Context, p redis.Pipeliner, key, runID string, status types.RunStatus, end time.Time) {
+	if status.Terminal() {
+		p.HSet(ctx, key, "Status", string(status), "EndTime", formatEndTime(end))
+		p.ZRem(ctx, RunningRunsKey, runID)
+
+		return
+	}
+
+	p.HSet(ctx, key, "Status", string(status))
+	p.ZAdd(ctx, RunningRunsKey, redis.Z{Score: float64(time.Now().Unix()), Member: runID})
+}
+
+func heartbeatRun(ctx context.Context, p redis.Pipeliner, runID string) {
+	p.ZAddXX(ctx, RunningRunsKey, redis.Z{Score: float64(time.Now().Unix()), Member: runID})
+}
+
+func formatEndTime(end time.Time) string {
+	if end.IsZero() {
+		return ""
+	}
+
+	return end.Format(time.RFC3339)
+}
+
 func readRunHash(ctx context.Context, p redis.Pipeliner, key string) *redis.MapStringStringCmd {
 	return p.HGetAll(ctx, key)
 }
@@ -176,3 +654,173 @@ func readRunHash(ctx context.Context, p redis.Pipeliner, key string) *redis.MapS
 func addRunToExperimentIndex(ctx context.Context, p redis.Pipeliner, expKey, id string) *redis.IntCmd {
 	return p.SAdd(ctx, expKey, id)
 }
+
+// DeleteRun removes a run and everything recorded under it: its hash,
+// params, metric streams and artifact hashes, and its entries in the
+// experiment, parent, tag, metric and lifecycle indexes. Its artifacts
+// release their blobs, the S3 keys of objects nothing references anymore
+// being queued for deletion (see ArtifactDeletionQueueKey) rather than
+// deleted inline, and its storage usage is removed from its experiment's.
+// Runs nested under it are left untouched.
+func (r *RedisStore) DeleteRun(ctx context.Context, runID string) error {
+	key := r.makeRunKey(runID)
+	usageKey := r.makeRunStorageUsageKey(runID)
+
+	fn := func(tx *redis.Tx) error {
+		// the metrics and artifacts of the run are read once it is watched,
+		// and watched in turn, so that values logged and artifacts added
+		// while it is deleted abort the transaction instead of being left
+		// behind
+		metricKeys, err := r.RunMetrics(ctx, runID)
+		if err != nil {
+			return err
+		}
+
+		artifacts, files, err := r.runArtifacts(ctx, tx, runID, metricKeys)
+		if err != nil {
+			return err
+		}
+
+		mapping, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("could not read run: %w", err)
+		}
+
+		usage, err := tx.HGetAll(ctx, usageKey).Result()
+		if err != nil {
+			return fmt.Errorf("could not read run storage usage: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.Del(ctx, key, r.makeRunParamsKey(runID), r.makeRunChildrenKey(runID), r.makeRunMetricSummaryKey(runID))
+			p.SRem(ctx, r.makeExpKey(mapping["ExperimentID"]), runID)
+			releaseRunStorage(ctx, p, r.makeExpStorageUsageKey(mapping["ExperimentID"]), usageKey,
+				parseStorageUsage(usage))
+
+			if parent := mapping["ParentID"]; parent != "" {
+				p.SRem(ctx, r.makeRunChildrenKey(parent), runID)
+			}
+
+			for k, v := range parseRunTags(mapping) {
+				p.SRem(ctx, r.makeRunTagIndexKey(k, v), runID)
+			}
+
+			for _, metricKey := range metricKeys {
+				name := strings.TrimSuffix(strings.TrimPrefix(metricKey, "metric:"), ":"+runID)
+
+				for _, agg := range []types.MetricAgg{types.MetricLast, types.MetricMin, types.MetricMax} {
+					p.ZRem(ctx, r.makeRunMetricIndexKey(name, agg), runID)
+				}
+
+				p.Del(ctx, metricKey)
+			}
+
+			for _, a := range artifacts {
+				p.Del(ctx, r.makeArtifactKey(a.Name, runID), r.makeArtifactFilesKey(a.Name, runID))
+				r.releaseArtifact(ctx, p, runID, a, files[a.Name])
+			}
+
+			p.ZRem(ctx, RunsIndexKey, runID)
+			p.ZRem(ctx, RunningRunsKey, runID)
+			p.SRem(ctx, ArchivedRunsKey, runID)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not run Tx: %w", err)
+		}
+
+		return nil
+	}
+
+	return r.runTx(ctx, fn, transactionMaxTries, key, usageKey)
+}
+
+// runArtifacts watches the artifact keys of a run, along with keys, before
+// reading its artifacts and the files of its directory artifacts.
+func (r *RedisStore) runArtifacts(ctx context.Context, tx *redis.Tx, runID string, keys []string,
+) (map[string]types.SavedArtifact, map[string][]types.ArtifactFile, error) {
+	artifactKeys, err := r.scanKeys(ctx, fmt.Sprintf(ArtifactKeyPattern, "*", runID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Watch(ctx, append(slices.Clone(keys), artifactKeys...)...).Err(); err != nil {
+		return nil, nil, fmt.Errorf("could not watch run keys: %w", err)
+	}
+
+	artifacts, err := r.Artifacts(ctx, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make(map[string][]types.ArtifactFile)
+
+	for _, a := range artifacts {
+		if a.ContentType != types.DirectoryContentType {
+			continue
+		}
+
+		files[a.Name], err = r.ArtifactFiles(ctx, runID, a.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return artifacts, files, nil
+}
+
+// SetRunArchived archives a run, hiding it from experiment listings and
+// searches, or restores it.
+func (r *RedisStore) SetRunArchived(ctx context.Context, runID string, archived bool) error {
+	key := r.makeRunKey(runID)
+
+	_, err := r.Client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		if archived {
+			p.HSet(ctx, key, "Archived", "true")
+			p.SAdd(ctx, ArchivedRunsKey, runID)
+		} else {
+			p.HDel(ctx, key, "Archived")
+			p.SRem(ctx, ArchivedRunsKey, runID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not archive run: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// withoutArchived returns ids without the ids of archived runs.
+func (r *RedisStore) withoutArchived(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	archived, err := r.Client.SMIsMember(ctx, ArchivedRunsKey, toAny(ids)...).Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not check archived runs")
+	}
+
+	active := make([]string, 0, len(ids))
+
+	for i, id := range ids {
+		if !archived[i] {
+			active = append(active, id)
+		}
+	}
+
+	return active, nil
+}
+
+func toAny(ss []string) []any {
+	res := make([]any, len(ss))
+
+	for i, s := range ss {
+		res[i] = s
+	}
+
+	return res
+}
@@ -0,0 +1,346 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// SearchRuns returns a page of the ids of the runs matching q, and the
+// cursor of the next page (0 when there are no more pages).
+//
+// Candidates are narrowed with the secondary indexes first: metric
+// conditions through the metric indexes (RunMetricIndexKeyPattern) and tag
+// equality through the tag index. The remaining conditions and the ordering
+// are evaluated against the run hashes and params of the candidates left.
+func (r *RedisStore) SearchRuns(ctx context.Context, q types.RunQuery) ([]string, uint64, error) {
+	ids, err := r.searchCandidates(ctx, q.Exps)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	remaining := make([]types.RunCondition, 0, len(q.Conditions))
+
+	for _, c := range q.Conditions {
+		var matching []string
+
+		switch {
+		case c.Field.Kind == types.MetricField:
+			matching, err = r.metricIndexMatches(ctx, c)
+		case c.Field.Kind == types.TagField && c.Op == types.OpEq:
+			matching, err = r.Client.SMembers(ctx, r.makeRunTagIndexKey(c.Field.Name, c.Value)).Result()
+		default:
+			remaining = append(remaining, c)
+
+			continue
+		}
+
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: could not read search index: %w", types.ErrInternal, err)
+		}
+
+		ids = intersect(ids, matching)
+		if len(ids) == 0 {
+			return []string{}, 0, nil
+		}
+	}
+
+	rows, err := r.searchRows(ctx, ids, q.Order.Field)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows = slices.DeleteFunc(rows, func(row searchRow) bool {
+		for _, c := range remaining {
+			if !c.Match(row.value(c.Field)) {
+				return true
+			}
+		}
+
+		return false
+	})
+
+	sortSearchRows(rows, q.Order)
+
+	return paginateSearchRows(rows, q.Cursor, q.Limit)
+}
+
+// searchCandidates returns the ids of the runs of exps, or of all runs when
+// exps is empty. Archived runs are left out.
+func (r *RedisStore) searchCandidates(ctx context.Context, exps []string) ([]string, error) {
+	if len(exps) == 0 {
+		ids, err := r.zIndexAll(ctx, RunsIndexKey)
+		if err != nil {
+			return nil, types.NewInternalErr("could not fetch runs")
+		}
+
+		return r.withoutArchived(ctx, ids)
+	}
+
+	keys := make([]string, len(exps))
+
+	for i, exp := range exps {
+		keys[i] = r.makeExpKey(exp)
+	}
+
+	ids, err := r.Client.SUnion(ctx, keys...).Result()
+	if err != nil {
+		return nil, types.NewInternalErr("could not fetch experiment runs")
+	}
+
+	return r.withoutArchived(ctx, ids)
+}
+
+// metricIndexMatches returns the ids of the runs whose indexed metric value
+// satisfies c.
+func (r *RedisStore) metricIndexMatches(ctx context.Context, c types.RunCondition) ([]string, error) {
+	bound := &redis.ZRangeBy{Min: "-inf", Max: "+inf", Offset: 0, Count: 0}
+
+	switch c.Op {
+	case types.OpEq:
+		bound.Min, bound.Max = c.Value, c.Value
+	case types.OpLt:
+		bound.Max = "(" + c.Value
+	case types.OpLte:
+		bound.Max = c.Value
+	case types.OpGt:
+		bound.Min = "(" + c.Value
+	case types.OpGte:
+		bound.Min = c.Value
+	case types.OpNeq:
+	}
+
+	res, err := r.Client.ZRangeByScoreWithScores(ctx, r.makeRunMetricIndexKey(c.Field.Name, c.Field.Agg), bound).Result()
+	if err != nil {
+		return nil, fmt.Errorf("could not read metric index: %w", err)
+	}
+
+	ids := make([]string, 0, len(res))
+
+	for _, z := range res {
+		id, _ := z.Member.(string)
+
+		if c.Match(formatScore(z.Score), true) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// searchRow the values of a candidate run that conditions and orderings
+// are evaluated on.
+type searchRow struct {
+	id     string
+	hash   map[string]string
+	params map[string]string
+	// metric value of the ordering field, if it is a metric
+	metric *float64
+}
+
+func (row searchRow) value(f types.RunField) (string, bool) {
+	switch f.Kind {
+	case types.StatusField:
+		v, ok := row.hash["Status"]
+
+		return v, ok
+	case types.CreatedField:
+		t, err := time.Parse(time.RFC3339, row.hash["Timestamp"])
+		if err != nil {
+			return "", false
+		}
+
+		return strconv.FormatInt(t.Unix(), 10), true
+	case types.TagField:
+		v, ok := row.hash[runTagFieldPrefix+f.Name]
+
+		return v, ok
+	case types.ParamField:
+		raw, ok := row.params[f.Name]
+		if !ok {
+			return "", false
+		}
+
+		p, err := types.DecodeParam(raw)
+		if err != nil {
+			return "", false
+		}
+
+		return p.String(), true
+	case types.MetricField:
+		if row.metric == nil {
+			return "", false
+		}
+
+		return formatScore(*row.metric), true
+	default:
+		return "", false
+	}
+}
+
+// searchRows reads the hash and params of every run of ids, and its indexed
+// value of order when ordering on a metric. Ids of runs that no longer exist
+// are dropped.
+func (r *RedisStore) searchRows(ctx context.Context, ids []string, order types.RunField) ([]searchRow, error) {
+	p := r.Client.Pipeline()
+
+	hashes := make([]*redis.MapStringStringCmd, len(ids))
+	params := make([]*redis.MapStringStringCmd, len(ids))
+	scores := make([]*redis.FloatCmd, len(ids))
+
+	for i, id := range ids {
+		hashes[i] = readRunHash(ctx, p, r.makeRunKey(id))
+		params[i] = p.HGetAll(ctx, r.makeRunParamsKey(id))
+
+		if order.Kind == types.MetricField {
+			scores[i] = p.ZScore(ctx, r.makeRunMetricIndexKey(order.Name, order.Agg), id)
+		}
+	}
+
+	_, err := p.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, types.NewInternalErr("could not fetch runs")
+	}
+
+	rows := make([]searchRow, 0, len(ids))
+
+	for i, id := range ids {
+		hash := hashes[i].Val()
+		if len(hash) == 0 {
+			continue
+		}
+
+		row := searchRow{id: id, hash: hash, params: params[i].Val(), metric: nil}
+
+		if scores[i] != nil {
+			if score, err := scores[i].Result(); err == nil {
+				row.metric = &score
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// sortSearchRows orders rows by order, runs without a value for the
+// ordering field coming last in either direction. Ties are broken by id so
+// that pages are stable.
+func sortSearchRows(rows []searchRow, order types.RunOrder) {
+	slices.SortStableFunc(rows, func(a, b searchRow) int {
+		va, okA := a.value(order.Field)
+		vb, okB := b.value(order.Field)
+
+		switch {
+		case okA && !okB:
+			return -1
+		case !okA && okB:
+			return 1
+		}
+
+		c := 0
+		if okA {
+			c = types.CompareValues(va, vb)
+		}
+
+		if order.Desc {
+			c = -c
+		}
+
+		return cmp.Or(c, strings.Compare(a.id, b.id))
+	})
+}
+
+func paginateSearchRows(rows []searchRow, cursor uint64, limit int64) ([]string, uint64, error) {
+	if limit <= 0 {
+		return nil, 0, types.NewBadRequest("limit must be positive")
+	}
+
+	start := min(cursor, uint64(len(rows)))
+	end := min(start+uint64(limit), uint64(len(rows)))
+
+	ids := make([]string, 0, end-start)
+
+	for _, row := range rows[start:end] {
+		ids = append(ids, row.id)
+	}
+
+	var next uint64
+
+	if end < uint64(len(rows)) {
+		next = end
+	}
+
+	return ids, next, nil
+}
+
+// BackfillRunsIndex adds runs created before RunsIndexKey and the metric
+// indexes existed to both. Runs already in RunsIndexKey are skipped, so it is
+// idempotent and cheap to run on every startup once the backfill is done.
+func (r *RedisStore) BackfillRunsIndex(ctx context.Context) error {
+	keys, err := r.scanKeys(ctx, "run:*")
+	if err != nil {
+		return types.NewInternalErr("could not scan run keys")
+	}
+
+	for _, key := range keys {
+		id, _ := strings.CutPrefix(key, "run:")
+
+		_, err := r.Client.ZScore(ctx, RunsIndexKey, id).Result()
+		if err == nil {
+			continue
+		} else if !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("could not read runs index: %w", err)
+		}
+
+		run, err := r.Run(ctx, id)
+		if err != nil {
+			r.Logger.Warn().Err(err).Str("run", id).Msg("skipping unreadable run in runs index backfill")
+
+			continue
+		}
+
+		_, err = r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+			for _, m := range run.Metrics {
+				r.indexMetric(ctx, p, id, m.Name(), m.Vals())
+			}
+
+			p.ZAddNX(ctx, RunsIndexKey, redis.Z{Score: float64(run.Timestamp.Unix()), Member: id})
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not backfill runs index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func formatScore(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// intersect returns the members of ids also in other, keeping ids' order.
+func intersect(ids, other []string) []string {
+	set := make(map[string]struct{}, len(other))
+
+	for _, id := range other {
+		set[id] = struct{}{}
+	}
+
+	return slices.DeleteFunc(ids, func(id string) bool {
+		_, ok := set[id]
+
+		return !ok
+	})
+}
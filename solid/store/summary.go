@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// metricSummarySrc folds newly logged values into the summary hash of a run
+// (KEYS[1]) for the metric ARGV[1]. The remaining args are value/step pairs,
+// the step being empty for values logged without one. Values are stored as
+// they were sent, so no precision is lost to Lua's formatting.
+const metricSummarySrc = `
+local key, name = KEYS[1], ARGV[1]
+local low = tonumber(redis.call('HGET', key, 'Min:' .. name) or '')
+local high = tonumber(redis.call('HGET', key, 'Max:' .. name) or '')
+
+for i = 2, #ARGV, 2 do
+  local v = tonumber(ARGV[i])
+
+  redis.call('HINCRBYFLOAT', key, 'Sum:' .. name, ARGV[i])
+
+  if low == nil or v < low then
+    low = v
+    redis.call('HSET', key, 'Min:' .. name, ARGV[i], 'MinStep:' .. name, ARGV[i + 1])
+  end
+
+  if high == nil or v > high then
+    high = v
+    redis.call('HSET', key, 'Max:' .. name, ARGV[i], 'MaxStep:' .. name, ARGV[i + 1])
+  end
+end
+
+redis.call('HINCRBY', key, 'Count:' .. name, (#ARGV - 1) / 2)
+redis.call('HSET', key, 'Last:' .. name, ARGV[#ARGV - 1], 'LastStep:' .. name, ARGV[#ARGV])
+
+return 0
+`
+
+var metricSummaryScript = redis.NewScript(metricSummarySrc) //nolint: gochecknoglobals
+
+// summarizeMetric updates the summary of a metric (see
+// RunMetricSummaryKeyPattern) with newly logged values. Non-numeric and
+// non-finite values are not summarized.
+func (r *RedisStore) summarizeMetric(ctx context.Context, p redis.Pipeliner, runID, name string,
+	vals []any, metas []types.ValMeta,
+) {
+	args := make([]any, 0, 1+2*len(vals)) //nolint: mnd
+	args = append(args, name)
+
+	for i, val := range vals {
+		v, ok := types.NumericVal(val)
+		if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+
+		step := ""
+		if i < len(metas) && metas[i].Step != nil {
+			step = strconv.FormatInt(*metas[i].Step, 10)
+		}
+
+		args = append(args, strconv.FormatFloat(v, 'g', -1, 64), step)
+	}
+
+	if len(args) == 1 {
+		return
+	}
+
+	// EVAL rather than EVALSHA, as a missing script cannot be retried
+	// from within a pipeline
+	metricSummaryScript.Eval(ctx, p, []string{r.makeRunMetricSummaryKey(runID)}, args...)
+}
+
+// RunsMetricSummaries returns the metric summaries of each run of ids, keyed
+// by run id then metric name.
+func (r *RedisStore) RunsMetricSummaries(ctx context.Context,
+	ids []string,
+) (map[string]map[string]types.MetricSummary, error) {
+	p := r.Client.Pipeline()
+
+	res := make(map[string]*redis.MapStringStringCmd, len(ids))
+
+	for _, id := range ids {
+		res[id] = p.HGetAll(ctx, r.makeRunMetricSummaryKey(id))
+	}
+
+	_, err := p.Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not pull metric summaries: %w", types.ErrInternal, err)
+	}
+
+	summaries := make(map[string]map[string]types.MetricSummary, len(ids))
+
+	for id, cmd := range res {
+		summaries[id] = types.ParseMetricSummaries(cmd.Val())
+	}
+
+	return summaries, nil
+}
+
+// BackfillMetricSummaries summarizes the metrics of runs logged before
+// metric summaries were maintained. Runs that already have summaries are
+// skipped, so it is idempotent; it should run before clients log metrics,
+// as values logged while a run is backfilled would be counted twice.
+func (r *RedisStore) BackfillMetricSummaries(ctx context.Context) error {
+	keys, err := r.scanKeys(ctx, "run:*")
+	if err != nil {
+		return types.NewInternalErr("could not scan run keys")
+	}
+
+	for _, key := range keys {
+		id, _ := strings.CutPrefix(key, "run:")
+
+		n, err := r.Client.Exists(ctx, r.makeRunMetricSummaryKey(id)).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("could not read metric summaries: %w", err)
+		}
+
+		if n == 1 {
+			continue
+		}
+
+		run, err := r.Run(ctx, id)
+		if err != nil {
+			r.Logger.Warn().Err(err).Str("run", id).Msg("skipping unreadable run in metric summaries backfill")
+
+			continue
+		}
+
+		_, err = r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+			for _, m := range run.Metrics {
+				r.summarizeMetric(ctx, p, id, m.Name(), m.Vals(), m.Metas())
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not backfill metric summaries: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+// CreateUploadSession saves a new artifact upload session.
+func (r *RedisStore) CreateUploadSession(ctx context.Context, s types.UploadSession) error {
+	_, err := r.Client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		setUploadSession(ctx, p, r.makeUploadSessionKey(s.ID), s)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not save upload session: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// UploadSession pulls an artifact upload session from the store.
+func (r *RedisStore) UploadSession(ctx context.Context, id string) (types.UploadSession, error) {
+	m, err := r.Client.HGetAll(ctx, r.makeUploadSessionKey(id)).Result()
+	if err != nil {
+		return types.UploadSession{}, fmt.Errorf("%w: could not pull upload session: %w", types.ErrInternal, err)
+	}
+
+	if len(m) == 0 {
+		return types.UploadSession{}, types.NewNotFoundErr(fmt.Sprintf("upload <%s> not found", id))
+	}
+
+	return types.NewUploadSession(m), nil
+}
+
+// CommitUploadPart saves s once a part was uploaded, from being the offset
+// the session was at before the part. It fails with ErrInvalidInput if the
+// session moved past from in the meantime, i.e another upload of the same
+// content committed first.
+func (r *RedisStore) CommitUploadPart(ctx context.Context, s types.UploadSession, from int64) error {
+	key := r.makeUploadSessionKey(s.ID)
+
+	// a missing or moved session aborts the transaction, and is returned as
+	// is rather than as a transaction failure
+	var invalid error
+
+	fn := func(tx *redis.Tx) error {
+		raw, err := tx.HGet(ctx, key, "Offset").Result()
+		if errors.Is(err, redis.Nil) {
+			invalid = types.NewNotFoundErr(fmt.Sprintf("upload <%s> not found", s.ID))
+
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("could not read upload offset: %w", err)
+		}
+
+		if raw != strconv.FormatInt(from, 10) {
+			invalid = types.NewInvalidInputErr(fmt.Sprintf("upload <%s> moved to offset %s while uploading", s.ID, raw))
+
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			setUploadSession(ctx, p, key, s)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not run Tx: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := r.runTx(ctx, fn, transactionMaxTries, key); err != nil {
+		return err
+	}
+
+	return invalid
+}
+
+// DeleteUploadSession deletes an artifact upload session.
+func (r *RedisStore) DeleteUploadSession(ctx context.Context, id string) error {
+	_, err := r.Client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, r.makeUploadSessionKey(id))
+		p.ZRem(ctx, UploadSessionsKey, id)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not delete upload session: %w", types.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// ExpiredUploadSessions returns the ids of the upload sessions that expired
+// before t.
+func (r *RedisStore) ExpiredUploadSessions(ctx context.Context, t time.Time) ([]string, error) {
+	ids, err := r.Client.ZRangeByScore(ctx, UploadSessionsKey, &redis.ZRangeBy{ //nolint: exhaustruct
+		Min: "-inf",
+		Max: strconv.FormatInt(t.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not pull expired upload sessions: %w", types.ErrInternal, err)
+	}
+
+	return ids, nil
+}
+
+// UploadSessions returns all artifact upload sessions, expired ones
+// included.
+func (r *RedisStore) UploadSessions(ctx context.Context) ([]types.UploadSession, error) {
+	ids, err := r.Client.ZRange(ctx, UploadSessionsKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not pull upload sessions: %w", types.ErrInternal, err)
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+
+	_, err = r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = p.HGetAll(ctx, r.makeUploadSessionKey(id))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not pull upload sessions: %w", types.ErrInternal, err)
+	}
+
+	sessions := make([]types.UploadSession, 0, len(ids))
+
+	for _, cmd := range cmds {
+		if m := cmd.Val(); len(m) > 0 {
+			sessions = append(sessions, types.NewUploadSession(m))
+		}
+	}
+
+	return sessions, nil
+}
+
+func setUploadSession(ctx context.Context, p redis.Pipeliner, key string, s types.UploadSession) {
+	p.HSet(ctx, key, s.Hash())
+	p.ZAdd(ctx, UploadSessionsKey, redis.Z{Score: float64(s.ExpiresAt.Unix()), Member: s.ID})
+}
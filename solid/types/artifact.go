@@ -1,6 +1,15 @@
 package types //nolint: var-naming
 
-import "io"
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+)
 
 type ContentType string
 
@@ -13,25 +22,164 @@ type Artifact interface {
 	Name() string
 	Content() io.Reader
 	ContentType() ContentType
+	// Digest the size and SHA-256 of the content, zero when it was not
+	// computed as the artifact was received.
+	Digest() ArtifactDigest
+	// MIMEType the MIME type of the content, empty when it was not sniffed
+	// as the artifact was received.
+	MIMEType() string
 }
 
 type PlainTextArtifact struct {
 	FileName    string
 	FileContent io.Reader
+	FileDigest  ArtifactDigest
+	FileMIME    string
 }
 
 type CheckpointArtifact struct {
-	Model      string
-	Checkpoint io.Reader
+	Model            string
+	Checkpoint       io.Reader
+	CheckpointDigest ArtifactDigest
+	CheckpointMIME   string
+}
+
+// FileArtifact an artifact of any other registered kind, see ArtifactKind.
+type FileArtifact struct {
+	FileName        string
+	FileContentType ContentType
+	FileContent     io.Reader
+	FileDigest      ArtifactDigest
+	FileMIME        string
 }
 
 type SavedArtifact struct {
 	Name        string
 	ContentType ContentType
 	S3Key       string
+	Size        int64
+	// SHA256 the hex encoded SHA-256 of the content, empty for artifacts
+	// saved before digests were recorded.
+	SHA256 string
+	// MIMEType the MIME type sniffed from the content, empty for artifacts
+	// saved before it was recorded.
+	MIMEType string
+	// CreatedAt the time the artifact was saved to its run, zero for
+	// artifacts saved before it was recorded.
+	CreatedAt time.Time
+	// Encoding the compression the content is stored with, Size being the
+	// size of the content once decompressed.
+	Encoding ContentEncoding
+}
+
+// ServedMIMEType returns the MIME type the content of the artifact is
+// served with, falling back to the one of its kind.
+func (a SavedArtifact) ServedMIMEType() string {
+	if a.MIMEType != "" {
+		return a.MIMEType
+	}
+
+	if k, ok := LookupArtifactKind(a.ContentType); ok {
+		return k.MIMEType
+	}
+
+	return DefaultMIMEType
+}
+
+// Blob an object of the object store holding content shared by all the
+// artifacts (and model entries) with its SHA-256, so identical content is
+// stored once. It is deleted once nothing references it anymore.
+type Blob struct {
+	SHA256 string
+	Key    string
+	Size   int64
+	// Encoding the compression of the object, see ContentEncoding.
+	Encoding ContentEncoding
+}
+
+// ArtifactDigest the size and SHA-256 of the content of an artifact.
+type ArtifactDigest struct {
+	Size   int64
+	SHA256 string
+}
+
+// Verify checks the digest matches the hex encoded SHA-256 a client sent
+// along with the artifact. An empty sha256 is not checked.
+func (d ArtifactDigest) Verify(sha256 string) error {
+	if sha256 == "" || strings.EqualFold(sha256, d.SHA256) {
+		return nil
+	}
+
+	return NewBadRequest(fmt.Sprintf("artifact sha256 mismatch: got %s, expected %s", d.SHA256, sha256))
+}
+
+// ValidateSHA256 checks s is a hex encoded SHA-256.
+func ValidateSHA256(s string) error {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != sha256.Size {
+		return NewBadRequest(fmt.Sprintf("malformed sha256 %q", s))
+	}
+
+	return nil
+}
+
+// Digester computes the digest of the content written to it, e.g. as an
+// artifact is streamed in.
+type Digester struct {
+	size int64
+	hash hash.Hash
+}
+
+func NewDigester() *Digester {
+	return &Digester{size: 0, hash: sha256.New()}
+}
+
+func (d *Digester) Write(p []byte) (int, error) {
+	n, err := d.hash.Write(p)
+	d.size += int64(n)
+
+	return n, err //nolint: wrapcheck
+}
+
+// Digest returns the digest of the content written so far.
+func (d *Digester) Digest() ArtifactDigest {
+	return ArtifactDigest{
+		Size:   d.size,
+		SHA256: hex.EncodeToString(d.hash.Sum(nil)),
+	}
+}
+
+// State returns the state of the digest, to resume it once more content is
+// received, see UploadSession.Digester.
+func (d *Digester) State() ([]byte, error) {
+	m, ok := d.hash.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, NewInternalErr("digest state cannot be saved")
+	}
+
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not save digest state: %w", ErrInternal, err)
+	}
+
+	return state, nil
 }
 
 func NewArtifact(name string, contentType string, content io.Reader) (Artifact, error) {
+	return NewDigestedArtifact(name, contentType, content, ArtifactDigest{}) //nolint: exhaustruct
+}
+
+// NewDigestedArtifact creates an artifact whose content digest was computed
+// as it was received.
+func NewDigestedArtifact(name string, contentType string, content io.Reader, digest ArtifactDigest) (Artifact, error) {
+	return NewSniffedArtifact(name, contentType, content, digest, "")
+}
+
+// NewSniffedArtifact creates an artifact whose content digest and MIME type
+// were computed as it was received, see MIMESniffer.
+func NewSniffedArtifact(name string, contentType string, content io.Reader, digest ArtifactDigest,
+	mimeType string,
+) (Artifact, error) {
 	if !IsValidContentType(contentType) {
 		return nil, NewInvalidInputErr("unknown content type for artifact")
 	}
@@ -41,16 +189,27 @@ func NewArtifact(name string, contentType string, content io.Reader) (Artifact,
 		return PlainTextArtifact{
 			FileName:    name,
 			FileContent: content,
+			FileDigest:  digest,
+			FileMIME:    mimeType,
 		}, nil
 
 	case ModelContentType:
 		return CheckpointArtifact{
-			Model:      name,
-			Checkpoint: content,
+			Model:            name,
+			Checkpoint:       content,
+			CheckpointDigest: digest,
+			CheckpointMIME:   mimeType,
 		}, nil
-	}
 
-	return nil, NewInternalErr("could not create artifact")
+	default:
+		return FileArtifact{
+			FileName:        name,
+			FileContentType: ContentType(contentType),
+			FileContent:     content,
+			FileDigest:      digest,
+			FileMIME:        mimeType,
+		}, nil
+	}
 }
 
 func (p PlainTextArtifact) Name() string {
@@ -65,6 +224,14 @@ func (p PlainTextArtifact) ContentType() ContentType {
 	return TextContentType
 }
 
+func (p PlainTextArtifact) Digest() ArtifactDigest {
+	return p.FileDigest
+}
+
+func (p PlainTextArtifact) MIMEType() string {
+	return p.FileMIME
+}
+
 func (c CheckpointArtifact) Name() string {
 	return c.Model
 }
@@ -77,6 +244,34 @@ func (c CheckpointArtifact) ContentType() ContentType {
 	return ModelContentType
 }
 
+func (c CheckpointArtifact) Digest() ArtifactDigest {
+	return c.CheckpointDigest
+}
+
+func (c CheckpointArtifact) MIMEType() string {
+	return c.CheckpointMIME
+}
+
+func (f FileArtifact) Name() string {
+	return f.FileName
+}
+
+func (f FileArtifact) Content() io.Reader {
+	return f.FileContent
+}
+
+func (f FileArtifact) ContentType() ContentType {
+	return f.FileContentType
+}
+
+func (f FileArtifact) Digest() ArtifactDigest {
+	return f.FileDigest
+}
+
+func (f FileArtifact) MIMEType() string {
+	return f.FileMIME
+}
+
 func ArtifactIDs(artifacts []Artifact) []string {
 	ids := make([]string, len(artifacts))
 
@@ -97,6 +292,10 @@ func ArtifactIDMap(artifacts []Artifact) map[string]Artifact {
 	return mapping
 }
 
+// IsValidContentType reports whether contentType is the content type of a
+// registered artifact kind, see RegisterArtifactKind.
 func IsValidContentType(contentType string) bool {
-	return contentType == string(TextContentType) || contentType == string(ModelContentType)
+	_, ok := LookupArtifactKind(ContentType(contentType))
+
+	return ok
 }
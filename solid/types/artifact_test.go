@@ -0,0 +1,75 @@
+package types_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestDigester(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		content  string
+		expected types.ArtifactDigest
+	}{
+		{
+			name:     "empty_content",
+			content:  "",
+			expected: types.ArtifactDigest{Size: 0, SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		},
+		{
+			name:     "content",
+			content:  "hello world",
+			expected: types.ArtifactDigest{Size: 11, SHA256: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := types.NewDigester()
+
+			_, err := io.Copy(d, strings.NewReader(tc.content))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, d.Digest())
+		})
+	}
+}
+
+func TestArtifactDigestVerify(t *testing.T) {
+	t.Parallel()
+
+	digest := types.ArtifactDigest{Size: 11, SHA256: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"}
+
+	testcases := []struct {
+		name   string
+		sha256 string
+		err    error
+	}{
+		{"no_client_digest", "", nil},
+		{"matching_digest", "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", nil},
+		{"uppercase_digest", "B94D27B9934D3E08A52E52D7DA7DABFAC484EFE37A5380EE9088F7ACE2EFCDE9", nil},
+		{"mismatching_digest", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", types.ErrBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := digest.Verify(tc.sha256)
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,12 +25,13 @@ type Bench struct {
 	DatasetURL     string        `json:"datasetUrl"     validate:"required,url"`
 	FromS3         bool          `json:"fromS3"`
 	Timestamp      time.Time     `json:"timestamp"      validate:"required"`
-	// ActiveBenchRun is the run currently in flight for this benchmark, if
-	// any. It is distinct from the runs returned by BenchmarkRuns: it tracks
-	// a run that has started but not yet been recorded, and is the zero
-	// value (BenchRun{}) once no run is active. See
-	// RedisStore.SetActiveBenchRun and RedisStore.RemActiveBenchRun.
-	ActiveBenchRun BenchRun `json:"activeBenchRun"`
+	// ActiveBenchRuns are the runs currently in flight for this benchmark,
+	// oldest first, one per registry/version being benchmarked. They are
+	// distinct from the runs returned by BenchmarkRuns: they track runs that
+	// have started but not yet been recorded, and the list is empty once no
+	// run is active. See RedisStore.SetActiveBenchRun and
+	// RedisStore.RemActiveBenchRun.
+	ActiveBenchRuns []BenchRun `json:"activeBenchRuns"`
 }
 
 // BenchMetric represents a benchmark metric.
@@ -56,6 +58,27 @@ type BenchRun struct {
 	End       time.Time          `json:"end"`
 }
 
+// BenchProgressPrefix marks a benchmark container log line as a progress
+// report rather than plain output, e.g. "MLSOLID_PROGRESS 42/100".
+const BenchProgressPrefix = "MLSOLID_PROGRESS"
+
+// BenchProgress is how far along a benchmark container reports to be.
+type BenchProgress struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total"`
+}
+
+// BenchRunLog is a single line of output captured from a benchmark
+// container while it runs. End is set on the last entry of a run, once the
+// container has exited, so watchers know no more lines will follow.
+type BenchRunLog struct {
+	ID        string         `json:"id"`
+	Line      string         `json:"line"`
+	Progress  *BenchProgress `json:"progress,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+	End       bool           `json:"end"`
+}
+
 // BenchEvent represents a benchmarking event.
 type BenchEvent struct {
 	BenchID     string
@@ -64,11 +87,13 @@ type BenchEvent struct {
 	Version     int64
 	DockerImage string
 	ModelURL    string
-	DatasetName string
-	DatasetURL  string
-	FromS3      bool
-	AutoTag     bool
-	Tag         string
+	// ModelEncoding the compression the model at ModelURL is stored with.
+	ModelEncoding ContentEncoding
+	DatasetName   string
+	DatasetURL    string
+	FromS3        bool
+	AutoTag       bool
+	Tag           string
 }
 
 // NewBenchMetric creates a new bench metric and sanitizes its name.
@@ -79,6 +104,48 @@ func NewBenchMetric(name string, descSort bool) BenchMetric {
 	}
 }
 
+// NewBenchRunLog creates a log entry from a raw container output line,
+// parsing it as a progress report when it carries BenchProgressPrefix.
+func NewBenchRunLog(line string) BenchRunLog {
+	l := BenchRunLog{ //nolint: exhaustruct
+		Line:      line,
+		Timestamp: time.Now(),
+	}
+
+	if progress, ok := ParseBenchProgress(line); ok {
+		l.Progress = &progress
+	}
+
+	return l
+}
+
+// ParseBenchProgress parses a progress line of the form
+// "MLSOLID_PROGRESS <done>/<total>". It returns false if line is not a
+// well-formed progress report.
+func ParseBenchProgress(line string) (BenchProgress, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), BenchProgressPrefix)
+	if !ok {
+		return BenchProgress{}, false
+	}
+
+	doneStr, totalStr, ok := strings.Cut(strings.TrimSpace(rest), "/")
+	if !ok {
+		return BenchProgress{}, false
+	}
+
+	done, err := strconv.ParseInt(strings.TrimSpace(doneStr), 10, 64)
+	if err != nil || done < 0 {
+		return BenchProgress{}, false
+	}
+
+	total, err := strconv.ParseInt(strings.TrimSpace(totalStr), 10, 64)
+	if err != nil || total <= 0 {
+		return BenchProgress{}, false
+	}
+
+	return BenchProgress{Done: min(done, total), Total: total}, true
+}
+
 // GenerateID generates a new ID.
 func (b *Bench) GenerateID() {
 	b.ID = uuid.NewString()
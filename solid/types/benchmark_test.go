@@ -136,3 +136,75 @@ func TestBestRuns(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBenchProgress(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Line     string
+		Progress types.BenchProgress
+		OK       bool
+	}{
+		{
+			Line:     "MLSOLID_PROGRESS 42/100",
+			Progress: types.BenchProgress{Done: 42, Total: 100},
+			OK:       true,
+		},
+		{
+			Line:     "  MLSOLID_PROGRESS  7 / 9  ",
+			Progress: types.BenchProgress{Done: 7, Total: 9},
+			OK:       true,
+		},
+		{
+			Line:     "MLSOLID_PROGRESS 120/100",
+			Progress: types.BenchProgress{Done: 100, Total: 100},
+			OK:       true,
+		},
+		{
+			Line: "MLSOLID_PROGRESS 1/0",
+		},
+		{
+			Line: "MLSOLID_PROGRESS -1/10",
+		},
+		{
+			Line: "MLSOLID_PROGRESS 42",
+		},
+		{
+			Line: "epoch 42/100",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Line, func(t *testing.T) {
+			t.Parallel()
+
+			progress, ok := types.ParseBenchProgress(tc.Line)
+
+			assert.Equal(t, tc.OK, ok)
+			assert.Equal(t, tc.Progress, progress)
+		})
+	}
+}
+
+func TestNewBenchRunLog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("plain_lines_carry_no_progress", func(t *testing.T) {
+		t.Parallel()
+
+		l := types.NewBenchRunLog("loading dataset")
+
+		assert.Equal(t, "loading dataset", l.Line)
+		assert.Nil(t, l.Progress)
+		assert.False(t, l.End)
+	})
+
+	t.Run("progress_lines_are_parsed", func(t *testing.T) {
+		t.Parallel()
+
+		l := types.NewBenchRunLog("MLSOLID_PROGRESS 3/4")
+
+		require.NotNil(t, l.Progress)
+		assert.Equal(t, types.BenchProgress{Done: 3, Total: 4}, *l.Progress)
+	})
+}
@@ -0,0 +1,125 @@
+package types
+
+import (
+	"maps"
+	"slices"
+)
+
+// RunComparison a set of runs, possibly from different experiments, laid
+// side by side: their params, metric summaries and metric curves. Maps keyed
+// by run id leave out runs without the param, summary or curve.
+type RunComparison struct {
+	// RunIDs the compared runs, in the order they were asked for.
+	RunIDs []string
+	// Experiments the experiment id of each run.
+	Experiments map[string]string
+	// Params compares the value each run logged for a param, keyed by param
+	// name.
+	Params map[string]ParamComparison
+	// Summaries the metric summaries of each run, keyed by run id then
+	// metric name.
+	Summaries map[string]map[string]MetricSummary
+	// Curves the compared metrics aligned on their steps, keyed by metric
+	// name.
+	Curves map[string]AlignedCurve
+}
+
+// ParamComparison the values runs logged for a param, keyed by run id.
+// Differs is set when they do not all hold the same value, including when
+// some runs did not log the param.
+type ParamComparison struct {
+	Values  map[string]Param
+	Differs bool
+}
+
+// AlignedCurve the numeric values of a metric logged by several runs,
+// aligned on a shared step axis. Values holds the value of each run at each
+// of Steps, nil where the run did not log one.
+type AlignedCurve struct {
+	Steps  []int64
+	Values map[string][]*float64
+}
+
+// CompareParams compares the params logged by the runs of runIDs, params
+// being keyed by run id then param name.
+func CompareParams(runIDs []string, params map[string]map[string]Param) map[string]ParamComparison {
+	res := make(map[string]ParamComparison)
+
+	for _, id := range runIDs {
+		for name, p := range params[id] {
+			c, ok := res[name]
+			if !ok {
+				c = ParamComparison{Values: make(map[string]Param), Differs: false}
+			}
+
+			c.Values[id] = p
+			res[name] = c
+		}
+	}
+
+	for name, c := range res {
+		encoded := make(map[string]struct{}, len(c.Values))
+		for _, p := range c.Values {
+			encoded[p.Encode()] = struct{}{}
+		}
+
+		c.Differs = len(c.Values) != len(runIDs) || len(encoded) > 1
+		res[name] = c
+	}
+
+	return res
+}
+
+// AlignCurves aligns the numeric values of a metric logged by several runs,
+// keyed by run id, on the steps they were logged at. Values logged without
+// a step are placed at their index; when a run logged several values at the
+// same step the last one is kept. Non-numeric metrics are left out.
+func AlignCurves(ms map[string]Metric) AlignedCurve {
+	byRun := make(map[string]map[int64]float64, len(ms))
+	steps := make(map[int64]struct{})
+
+	for id, m := range ms {
+		metas := m.Metas()
+		values := make(map[int64]float64)
+
+		for i, val := range m.Vals() {
+			v, ok := NumericVal(val)
+			if !ok {
+				continue
+			}
+
+			step := int64(i)
+			if metas[i].Step != nil {
+				step = *metas[i].Step
+			}
+
+			values[step] = v
+			steps[step] = struct{}{}
+		}
+
+		if len(values) > 0 {
+			byRun[id] = values
+		}
+	}
+
+	curve := AlignedCurve{
+		Steps:  slices.AppendSeq(make([]int64, 0, len(steps)), maps.Keys(steps)),
+		Values: make(map[string][]*float64, len(byRun)),
+	}
+
+	slices.Sort(curve.Steps)
+
+	for id, values := range byRun {
+		aligned := make([]*float64, len(curve.Steps))
+
+		for i, step := range curve.Steps {
+			if v, ok := values[step]; ok {
+				aligned[i] = &v
+			}
+		}
+
+		curve.Values[id] = aligned
+	}
+
+	return curve
+}
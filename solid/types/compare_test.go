@@ -0,0 +1,141 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestCompareParams(t *testing.T) {
+	t.Parallel()
+
+	lr := func(v float64) types.Param { return types.Param{Type: types.FloatParam, Value: v} }
+	optim := types.Param{Type: types.StringParam, Value: "adam"}
+
+	testcases := []struct {
+		name     string
+		params   map[string]map[string]types.Param
+		expected map[string]bool
+	}{
+		{
+			name: "same_values",
+			params: map[string]map[string]types.Param{
+				"a": {"lr": lr(0.01), "optimizer": optim},
+				"b": {"lr": lr(0.01), "optimizer": optim},
+			},
+			expected: map[string]bool{"lr": false, "optimizer": false},
+		},
+		{
+			name: "different_values",
+			params: map[string]map[string]types.Param{
+				"a": {"lr": lr(0.01)},
+				"b": {"lr": lr(0.001)},
+			},
+			expected: map[string]bool{"lr": true},
+		},
+		{
+			name: "param_missing_from_a_run",
+			params: map[string]map[string]types.Param{
+				"a": {"lr": lr(0.01), "optimizer": optim},
+				"b": {"lr": lr(0.01)},
+			},
+			expected: map[string]bool{"lr": false, "optimizer": true},
+		},
+		{
+			name: "same_value_different_types",
+			params: map[string]map[string]types.Param{
+				"a": {"epochs": {Type: types.IntParam, Value: int64(10)}},
+				"b": {"epochs": {Type: types.StringParam, Value: "10"}},
+			},
+			expected: map[string]bool{"epochs": true},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			res := types.CompareParams([]string{"a", "b"}, tc.params)
+
+			assert.Len(t, res, len(tc.expected))
+
+			for name, differs := range tc.expected {
+				assert.Equal(t, differs, res[name].Differs, name)
+			}
+		})
+	}
+}
+
+func TestAlignCurves(t *testing.T) {
+	t.Parallel()
+
+	withSteps := func(steps []int64, vals ...float64) types.Metric {
+		m := types.NewGenericMetric[float64]("loss", len(vals))
+
+		for i, v := range vals {
+			m.AddWithMeta(v, types.ValMeta{Step: &steps[i]})
+		}
+
+		m.Commit()
+
+		return m
+	}
+
+	f := func(v float64) *float64 { return &v }
+
+	testcases := []struct {
+		name          string
+		metrics       map[string]types.Metric
+		expectedSteps []int64
+		expected      map[string][]*float64
+	}{
+		{
+			name: "steps_are_merged",
+			metrics: map[string]types.Metric{
+				"a": withSteps([]int64{0, 10, 20}, 0.9, 0.5, 0.3),
+				"b": withSteps([]int64{0, 5, 10}, 0.8, 0.6, 0.4),
+			},
+			expectedSteps: []int64{0, 5, 10, 20},
+			expected: map[string][]*float64{
+				"a": {f(0.9), nil, f(0.5), f(0.3)},
+				"b": {f(0.8), f(0.6), f(0.4), nil},
+			},
+		},
+		{
+			name: "last_value_of_a_step_is_kept",
+			metrics: map[string]types.Metric{
+				"a": withSteps([]int64{1, 1}, 0.9, 0.7),
+			},
+			expectedSteps: []int64{1},
+			expected:      map[string][]*float64{"a": {f(0.7)}},
+		},
+		{
+			name: "values_without_steps_use_their_index",
+			metrics: map[string]types.Metric{
+				"a": &types.GenericMetric[int64]{Key: "epoch", Values: []int64{3, 4}},
+			},
+			expectedSteps: []int64{0, 1},
+			expected:      map[string][]*float64{"a": {f(3), f(4)}},
+		},
+		{
+			name: "non_numeric_metrics_are_left_out",
+			metrics: map[string]types.Metric{
+				"a": &types.GenericMetric[string]{Key: "notes", Values: []string{"ok"}},
+			},
+			expectedSteps: []int64{},
+			expected:      map[string][]*float64{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			curve := types.AlignCurves(tc.metrics)
+
+			assert.Equal(t, tc.expectedSteps, curve.Steps)
+			assert.Equal(t, tc.expected, curve.Values)
+		})
+	}
+}
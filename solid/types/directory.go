@@ -0,0 +1,62 @@
+package types //nolint: var-naming
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DirectoryContentType the content type of directory artifacts, e.g. a
+// HuggingFace model with its configs, tokenizers and shards. Their content
+// is a tree of files (see ArtifactFile) rather than a single object.
+const DirectoryContentType ContentType = "content-type/directory"
+
+// ArtifactFile a file of a directory artifact. Its content is stored as the
+// blob of its SHA-256, see Blob.
+type ArtifactFile struct {
+	// Path the slash separated path of the file in the directory.
+	Path   string `json:"path"`
+	S3Key  string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// MIMEType the MIME type sniffed from the content of the file.
+	MIMEType string `json:"mimeType,omitempty"`
+	// Encoding the compression the content is stored with.
+	Encoding ContentEncoding `json:"encoding,omitempty"`
+}
+
+// ArchiveFormat the format directory artifacts are downloaded as.
+type ArchiveFormat string
+
+const (
+	TarArchive ArchiveFormat = "tar"
+	ZipArchive ArchiveFormat = "zip"
+)
+
+// ParseArchiveFormat parses an archive format, tar being the default.
+func ParseArchiveFormat(s string) (ArchiveFormat, error) {
+	switch ArchiveFormat(strings.ToLower(s)) {
+	case "", TarArchive:
+		return TarArchive, nil
+	case ZipArchive:
+		return ZipArchive, nil
+	default:
+		return "", NewBadRequest(fmt.Sprintf("unknown archive format <%s>", s))
+	}
+}
+
+// CleanArtifactPath returns the clean form of the path of a file of a
+// directory artifact. Paths must be relative and stay within the directory.
+func CleanArtifactPath(p string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(strings.ReplaceAll(p, `\`, "/"), "./"))
+
+	if clean == "." || clean == "" {
+		return "", NewBadRequest("file path is required")
+	}
+
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", NewBadRequest(fmt.Sprintf("file path <%s> leaves the directory", p))
+	}
+
+	return clean, nil
+}
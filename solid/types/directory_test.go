@@ -0,0 +1,79 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestCleanArtifactPath(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		path     string
+		expected string
+		err      error
+	}{
+		{"file", "config.json", "config.json", nil},
+		{"nested_file", "tokenizer/vocab.txt", "tokenizer/vocab.txt", nil},
+		{"dot_prefix", "./model/shard-1.bin", "model/shard-1.bin", nil},
+		{"redundant_elements", "model//a/../shard-1.bin", "model/shard-1.bin", nil},
+		{"windows_separators", `tokenizer\vocab.txt`, "tokenizer/vocab.txt", nil},
+		{"empty", "", "", types.ErrBadRequest},
+		{"directory_itself", "./", "", types.ErrBadRequest},
+		{"absolute", "/etc/passwd", "", types.ErrBadRequest},
+		{"parent", "../secret", "", types.ErrBadRequest},
+		{"nested_parent", "model/../../secret", "", types.ErrBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := types.CleanArtifactPath(tc.path)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestParseArchiveFormat(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		format   string
+		expected types.ArchiveFormat
+		err      error
+	}{
+		{"default", "", types.TarArchive, nil},
+		{"tar", "tar", types.TarArchive, nil},
+		{"zip", "ZIP", types.ZipArchive, nil},
+		{"unknown", "rar", "", types.ErrBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := types.ParseArchiveFormat(tc.format)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, format)
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DownsampleMethod how the values of a metric are reduced to a target
+// number of points.
+type DownsampleMethod string
+
+const (
+	// DownsampleLTTB Largest-Triangle-Three-Buckets, keeps the points that
+	// preserve the visual shape of the curve.
+	DownsampleLTTB DownsampleMethod = "lttb"
+	// DownsampleMinMax keeps the lowest and highest point of each bucket, so
+	// spikes are never dropped.
+	DownsampleMinMax DownsampleMethod = "minmax"
+)
+
+// ParseDownsampleMethod parses a downsampling method, LTTB being the
+// default.
+func ParseDownsampleMethod(s string) (DownsampleMethod, error) {
+	switch m := DownsampleMethod(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return DownsampleLTTB, nil
+	case DownsampleLTTB, DownsampleMinMax:
+		return m, nil
+	default:
+		return "", NewBadRequest(fmt.Sprintf("unknown downsampling method %q", s))
+	}
+}
+
+// MetricQuery selects the values of a metric to read. Windows are
+// inclusive and unbounded when nil.
+type MetricQuery struct {
+	// StartStep and EndStep bound the steps of the values. Values logged
+	// without a step are left out when either is set.
+	StartStep *int64
+	EndStep   *int64
+	// Since and Until bound the server time the values were logged at.
+	Since *time.Time
+	Until *time.Time
+	// MaxPoints downsamples the values to at most that many points, 0
+	// meaning no downsampling.
+	MaxPoints  int
+	Downsample DownsampleMethod
+	// LastOnly only returns the last value in the windows.
+	LastOnly bool
+}
+
+// minLTTBPoints LTTB always keeps the first and last points plus at least
+// one point in between.
+const minLTTBPoints = 3
+
+// Validate checks the windows are well-formed and the downsampling target
+// reachable.
+func (q MetricQuery) Validate() error {
+	if q.StartStep != nil && q.EndStep != nil && *q.StartStep > *q.EndStep {
+		return NewBadRequest("start step cannot be after end step")
+	}
+
+	if q.Since != nil && q.Until != nil && q.Since.After(*q.Until) {
+		return NewBadRequest("since cannot be after until")
+	}
+
+	if q.MaxPoints < 0 {
+		return NewBadRequest("max points cannot be negative")
+	}
+
+	if q.MaxPoints > 0 && q.Downsample == DownsampleLTTB && q.MaxPoints < minLTTBPoints {
+		return NewBadRequest(fmt.Sprintf("lttb downsampling needs at least %d points", minLTTBPoints))
+	}
+
+	if q.MaxPoints > 0 && q.Downsample == DownsampleMinMax && q.MaxPoints < 2 { //nolint: mnd
+		return NewBadRequest("minmax downsampling needs at least 2 points")
+	}
+
+	return nil
+}
+
+// HasStepWindow reports whether the query bounds the steps of the values.
+func (q MetricQuery) HasStepWindow() bool {
+	return q.StartStep != nil || q.EndStep != nil
+}
+
+func (q MetricQuery) inStepWindow(meta ValMeta) bool {
+	if !q.HasStepWindow() {
+		return true
+	}
+
+	if meta.Step == nil {
+		return false
+	}
+
+	return (q.StartStep == nil || *meta.Step >= *q.StartStep) && (q.EndStep == nil || *meta.Step <= *q.EndStep)
+}
+
+// ApplyMetricQuery returns the values of m within q's step window, reduced
+// to the last one or downsampled as q asks. Time windows are applied when
+// reading the metric, see store.RedisStore.RunsMetric. Non-numeric metrics
+// are never downsampled.
+func ApplyMetricQuery(m Metric, q MetricQuery) Metric { //nolint: ireturn
+	switch g := m.(type) {
+	case *GenericMetric[float64]:
+		return applyMetricQuery(g, q, true)
+	case *GenericMetric[int64]:
+		return applyMetricQuery(g, q, true)
+	case *GenericMetric[string]:
+		return applyMetricQuery(g, q, false)
+	case *GenericMetric[bool]:
+		return applyMetricQuery(g, q, false)
+	case *GenericMetric[Histogram]:
+		return applyMetricQuery(g, q, false)
+	case *GenericMetric[Vector]:
+		return applyMetricQuery(g, q, false)
+	default:
+		return m
+	}
+}
+
+func applyMetricQuery[T any](g *GenericMetric[T], q MetricQuery, numeric bool) *GenericMetric[T] {
+	vals := g.Values
+	metas := g.Metas()
+
+	idx := make([]int, 0, len(vals))
+
+	for i := range vals {
+		if q.inStepWindow(metas[i]) {
+			idx = append(idx, i)
+		}
+	}
+
+	switch {
+	case q.LastOnly && len(idx) > 0:
+		idx = idx[len(idx)-1:]
+	case numeric && q.MaxPoints > 0 && len(idx) > q.MaxPoints:
+		xs := make([]float64, len(idx))
+		ys := make([]float64, len(idx))
+
+		for j, i := range idx {
+			xs[j] = float64(i)
+			if metas[i].Step != nil {
+				xs[j] = float64(*metas[i].Step)
+			}
+
+			ys[j], _ = NumericVal(vals[i])
+		}
+
+		kept := DownsampleIndices(xs, ys, q.MaxPoints, q.Downsample)
+		selected := make([]int, len(kept))
+
+		for j, k := range kept {
+			selected[j] = idx[k]
+		}
+
+		idx = selected
+	}
+
+	res := &GenericMetric[T]{Key: g.Key, Values: make([]T, 0, len(idx)), Meta: make([]ValMeta, 0, len(idx))} //nolint: exhaustruct
+
+	for _, i := range idx {
+		res.Values = append(res.Values, vals[i])
+		res.Meta = append(res.Meta, metas[i])
+	}
+
+	return res
+}
+
+// DownsampleIndices returns the indices of the points (xs[i], ys[i]) kept
+// when reducing them to n points with method, in increasing order. All
+// indices are returned when there are n points or fewer.
+func DownsampleIndices(xs, ys []float64, n int, method DownsampleMethod) []int {
+	if n <= 0 || len(ys) <= n {
+		idx := make([]int, len(ys))
+		for i := range idx {
+			idx[i] = i
+		}
+
+		return idx
+	}
+
+	if method == DownsampleMinMax {
+		return minMaxIndices(ys, n)
+	}
+
+	return lttbIndices(xs, ys, n)
+}
+
+// lttbIndices implements Largest-Triangle-Three-Buckets: the first and last
+// points are kept, and the others split in n-2 buckets each contributing the
+// point forming the largest triangle with the previously kept point and the
+// average of the next bucket.
+func lttbIndices(xs, ys []float64, n int) []int {
+	if n < minLTTBPoints {
+		n = minLTTBPoints
+	}
+
+	size := float64(len(ys)-2) / float64(n-2)
+	idx := make([]int, 0, n)
+	idx = append(idx, 0)
+
+	a := 0
+
+	for b := range n - 2 {
+		start := int(float64(b)*size) + 1
+		end := int(float64(b+1)*size) + 1
+
+		nextStart := end
+		nextEnd := min(int(float64(b+2)*size)+1, len(ys))
+
+		var avgX, avgY float64
+
+		for i := nextStart; i < nextEnd; i++ {
+			avgX += xs[i]
+			avgY += ys[i]
+		}
+
+		if count := float64(nextEnd - nextStart); count > 0 {
+			avgX, avgY = avgX/count, avgY/count
+		}
+
+		picked, maxArea := start, -1.0
+
+		for i := start; i < end; i++ {
+			area := math.Abs((xs[a]-avgX)*(ys[i]-ys[a]) - (xs[a]-xs[i])*(avgY-ys[a]))
+			if area > maxArea {
+				picked, maxArea = i, area
+			}
+		}
+
+		idx = append(idx, picked)
+		a = picked
+	}
+
+	return append(idx, len(ys)-1)
+}
+
+// minMaxIndices splits the points in n/2 buckets and keeps the lowest and
+// highest point of each.
+func minMaxIndices(ys []float64, n int) []int {
+	buckets := max(n/2, 1) //nolint: mnd
+	size := float64(len(ys)) / float64(buckets)
+	idx := make([]int, 0, n)
+
+	for b := range buckets {
+		start := int(float64(b) * size)
+		end := min(int(float64(b+1)*size), len(ys))
+		if b == buckets-1 {
+			end = len(ys)
+		}
+
+		if start >= end {
+			continue
+		}
+
+		low, high := start, start
+
+		for i := start; i < end; i++ {
+			if ys[i] < ys[low] {
+				low = i
+			}
+
+			if ys[i] > ys[high] {
+				high = i
+			}
+		}
+
+		switch {
+		case low == high:
+			idx = append(idx, low)
+		case low < high:
+			idx = append(idx, low, high)
+		default:
+			idx = append(idx, high, low)
+		}
+	}
+
+	return idx
+}
@@ -0,0 +1,110 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestDownsampleIndices(t *testing.T) {
+	t.Parallel()
+
+	xs := []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	ys := []float64{0, 0, 0, 10, 0, 0, 0, -10, 0, 0}
+
+	testcases := []struct {
+		name     string
+		n        int
+		method   types.DownsampleMethod
+		expected []int
+	}{
+		{"fewer_points_than_target", 20, types.DownsampleLTTB, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"no_target", 0, types.DownsampleLTTB, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"lttb_keeps_spikes_and_ends", 4, types.DownsampleLTTB, []int{0, 3, 7, 9}},
+		{"minmax_keeps_extremes_per_bucket", 4, types.DownsampleMinMax, []int{0, 3, 5, 7}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, types.DownsampleIndices(xs, ys, tc.n, tc.method))
+		})
+	}
+}
+
+func TestApplyMetricQuery(t *testing.T) {
+	t.Parallel()
+
+	m := types.NewGenericMetric[float64]("loss", 6)
+	for i, v := range []float64{1, 0.8, 0.5, 0.6, 0.3, 0.2} {
+		step := int64(i * 10)
+		m.AddWithMeta(v, types.ValMeta{Step: &step}) //nolint: exhaustruct
+	}
+
+	m.Commit()
+
+	start, end := int64(10), int64(40)
+
+	testcases := []struct {
+		name     string
+		q        types.MetricQuery
+		expected []any
+	}{
+		{"no_query", types.MetricQuery{}, []any{1.0, 0.8, 0.5, 0.6, 0.3, 0.2}},                                   //nolint: exhaustruct
+		{"step_window", types.MetricQuery{StartStep: &start, EndStep: &end}, []any{0.8, 0.5, 0.6, 0.3}},          //nolint: exhaustruct
+		{"last_in_window", types.MetricQuery{EndStep: &end, LastOnly: true}, []any{0.3}},                         //nolint: exhaustruct
+		{"downsampled", types.MetricQuery{MaxPoints: 3, Downsample: types.DownsampleLTTB}, []any{1.0, 0.5, 0.2}}, //nolint: exhaustruct
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			res := types.ApplyMetricQuery(m, tc.q)
+			assert.Equal(t, tc.expected, res.Vals())
+			assert.Len(t, res.Metas(), len(tc.expected))
+		})
+	}
+
+	t.Run("strings_are_not_downsampled", func(t *testing.T) {
+		t.Parallel()
+
+		s := types.NewGenericMetric[string]("phase", 3)
+		s.Add("warmup")
+		s.Add("train")
+		s.Add("eval")
+		s.Commit()
+
+		res := types.ApplyMetricQuery(s, types.MetricQuery{MaxPoints: 2, Downsample: types.DownsampleMinMax}) //nolint: exhaustruct
+		assert.Equal(t, []any{"warmup", "train", "eval"}, res.Vals())
+	})
+}
+
+func TestMetricQueryValidate(t *testing.T) {
+	t.Parallel()
+
+	start, end := int64(10), int64(5)
+
+	testcases := []struct {
+		name string
+		q    types.MetricQuery
+	}{
+		{"inverted_step_window", types.MetricQuery{StartStep: &start, EndStep: &end}},                  //nolint: exhaustruct
+		{"negative_max_points", types.MetricQuery{MaxPoints: -1}},                                      //nolint: exhaustruct
+		{"lttb_needs_three_points", types.MetricQuery{MaxPoints: 2, Downsample: types.DownsampleLTTB}}, //nolint: exhaustruct
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, tc.q.Validate(), types.ErrBadRequest)
+		})
+	}
+
+	_, err := types.ParseDownsampleMethod("average")
+	require.ErrorIs(t, err, types.ErrBadRequest)
+}
@@ -0,0 +1,158 @@
+package types //nolint: var-naming
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ContentEncoding the compression the content of an artifact is stored
+// with in the object store.
+type ContentEncoding string
+
+const (
+	// IdentityEncoding content stored as it was uploaded.
+	IdentityEncoding ContentEncoding = ""
+	GzipEncoding     ContentEncoding = "gzip"
+	ZstdEncoding     ContentEncoding = "zstd"
+)
+
+// ParseContentEncoding parses a content encoding, "", "identity" and "none"
+// being the identity encoding.
+func ParseContentEncoding(s string) (ContentEncoding, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "identity", "none":
+		return IdentityEncoding, nil
+	case string(GzipEncoding):
+		return GzipEncoding, nil
+	case string(ZstdEncoding):
+		return ZstdEncoding, nil
+	default:
+		return IdentityEncoding, NewBadRequest(fmt.Sprintf("unknown content encoding <%s>", s))
+	}
+}
+
+// ParseCompression parses the encoding artifacts of each content type are
+// compressed with, e.g. {"content-type/text": "zstd"}. Content types of no
+// registered artifact kind are rejected.
+func ParseCompression(m map[string]string) (map[ContentType]ContentEncoding, error) {
+	compression := make(map[ContentType]ContentEncoding, len(m))
+
+	for ct, s := range m {
+		if !IsValidContentType(ct) {
+			return nil, NewBadRequest(fmt.Sprintf("unknown content type <%s> to compress", ct))
+		}
+
+		e, err := ParseContentEncoding(s)
+		if err != nil {
+			return nil, err
+		}
+
+		compression[ContentType(ct)] = e
+	}
+
+	return compression, nil
+}
+
+// AcceptedBy reports whether the Accept-Encoding header accept lists e,
+// i.e. whether a client can be sent content encoded with e as is.
+func (e ContentEncoding) AcceptedBy(accept string) bool {
+	if e == IdentityEncoding {
+		return true
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), string(e)) {
+			continue
+		}
+
+		// "gzip;q=0" refuses gzip
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+
+	return false
+}
+
+// NewEncoder returns a writer compressing what is written to it into w.
+// Closing it flushes the compressed content, but does not close w.
+func (e ContentEncoding) NewEncoder(w io.Writer) (io.WriteCloser, error) {
+	switch e {
+	case IdentityEncoding:
+		return nopWriteCloser{w}, nil
+	case GzipEncoding:
+		return gzip.NewWriter(w), nil
+	case ZstdEncoding:
+		enc, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not create zstd encoder: %w", ErrInternal, err)
+		}
+
+		return enc, nil
+	default:
+		return nil, NewInternalErr(fmt.Sprintf("unknown content encoding <%s>", e))
+	}
+}
+
+// NewDecoder returns a reader decompressing the content of r. Closing it
+// closes r.
+func (e ContentEncoding) NewDecoder(r io.ReadCloser) (io.ReadCloser, error) {
+	switch e {
+	case IdentityEncoding:
+		return r, nil
+	case GzipEncoding:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not read gzip content: %w", ErrInternal, err)
+		}
+
+		return decoder{Reader: gz, close: func() { _ = gz.Close() }, body: r}, nil
+	case ZstdEncoding:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not read zstd content: %w", ErrInternal, err)
+		}
+
+		return decoder{Reader: zr, close: zr.Close, body: r}, nil
+	default:
+		return nil, NewInternalErr(fmt.Sprintf("unknown content encoding <%s>", e))
+	}
+}
+
+// EncodedArtifact an artifact whose content is compressed with Encoding
+// before being stored.
+type EncodedArtifact struct {
+	Artifact
+
+	EncodedContent io.Reader
+	Encoding       ContentEncoding
+}
+
+// Content the compressed content of the artifact.
+func (e EncodedArtifact) Content() io.Reader {
+	return e.EncodedContent
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+type decoder struct {
+	io.Reader
+
+	close func()
+	body  io.Closer
+}
+
+func (d decoder) Close() error {
+	d.close()
+
+	return d.body.Close() //nolint: wrapcheck
+}
@@ -0,0 +1,127 @@
+package types_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestContentEncodingRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(strings.Repeat("epoch=1 loss=0.25 accuracy=0.91\n", 100))
+
+	for _, encoding := range []types.ContentEncoding{types.IdentityEncoding, types.GzipEncoding, types.ZstdEncoding} {
+		t.Run(string(encoding), func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			enc, err := encoding.NewEncoder(&buf)
+			require.NoError(t, err)
+
+			_, err = enc.Write(content)
+			require.NoError(t, err)
+			require.NoError(t, enc.Close())
+
+			if encoding != types.IdentityEncoding {
+				assert.Less(t, buf.Len(), len(content))
+			}
+
+			dec, err := encoding.NewDecoder(io.NopCloser(&buf))
+			require.NoError(t, err)
+
+			decoded, err := io.ReadAll(dec)
+			require.NoError(t, err)
+			require.NoError(t, dec.Close())
+			assert.Equal(t, content, decoded)
+		})
+	}
+}
+
+func TestParseContentEncoding(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		s        string
+		expected types.ContentEncoding
+		err      error
+	}{
+		{"empty", "", types.IdentityEncoding, nil},
+		{"identity", "identity", types.IdentityEncoding, nil},
+		{"none", "none", types.IdentityEncoding, nil},
+		{"gzip", "gzip", types.GzipEncoding, nil},
+		{"zstd_upper_case", " ZSTD ", types.ZstdEncoding, nil},
+		{"unknown", "brotli", types.IdentityEncoding, types.ErrBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := types.ParseContentEncoding(tc.s)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, e)
+		})
+	}
+}
+
+func TestContentEncodingAcceptedBy(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		encoding types.ContentEncoding
+		accept   string
+		expected bool
+	}{
+		{"identity", types.IdentityEncoding, "", true},
+		{"not_accepted", types.ZstdEncoding, "", false},
+		{"accepted", types.ZstdEncoding, "gzip, zstd", true},
+		{"accepted_with_quality", types.GzipEncoding, "gzip;q=0.8, br", true},
+		{"refused", types.GzipEncoding, "gzip;q=0, zstd", false},
+		{"other_encoding", types.GzipEncoding, "br, zstd", false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.encoding.AcceptedBy(tc.accept))
+		})
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	t.Parallel()
+
+	compression, err := types.ParseCompression(map[string]string{
+		string(types.TextContentType):  "zstd",
+		string(types.TableContentType): "gzip",
+		string(types.ImageContentType): "none",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[types.ContentType]types.ContentEncoding{
+		types.TextContentType:  types.ZstdEncoding,
+		types.TableContentType: types.GzipEncoding,
+		types.ImageContentType: types.IdentityEncoding,
+	}, compression)
+
+	_, err = types.ParseCompression(map[string]string{"content-type/unknown": "zstd"})
+	require.ErrorIs(t, err, types.ErrBadRequest)
+
+	_, err = types.ParseCompression(map[string]string{string(types.TextContentType): "brotli"})
+	require.ErrorIs(t, err, types.ErrBadRequest)
+}
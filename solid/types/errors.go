@@ -6,12 +6,13 @@ import (
 )
 
 var (
-	ErrAlreadyInUse   = errors.New("already in use") //nolint: revive
-	ErrInvalidInput   = errors.New("invalid input")
-	ErrInternal       = errors.New("internal error")
-	ErrBadRequest     = errors.New("bad request")
-	ErrNotFound       = errors.New("not found")
-	ErrNotInitialized = errors.New("not initialized")
+	ErrAlreadyInUse      = errors.New("already in use") //nolint: revive
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrInternal          = errors.New("internal error")
+	ErrBadRequest        = errors.New("bad request")
+	ErrNotFound          = errors.New("not found")
+	ErrNotInitialized    = errors.New("not initialized")
+	ErrResourceExhausted = errors.New("resource exhausted")
 )
 
 // NewAlreadyInUseErr returns new ErrAlreadyInUse error.
@@ -39,6 +40,11 @@ func NewNotFoundErr(s string) error {
 	return newErr(ErrNotFound, s)
 }
 
+// NewResourceExhaustedErr returns new ErrResourceExhausted error.
+func NewResourceExhaustedErr(s string) error {
+	return newErr(ErrResourceExhausted, s)
+}
+
 func newErr(wrapper error, msg string) error {
 	return fmt.Errorf("%w: %s", wrapper, msg)
 }
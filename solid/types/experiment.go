@@ -1,19 +1,113 @@
 package types //nolint: var-naming
 
+import (
+	"strings"
+	"time"
+)
+
 type Experiment struct {
 	Name string
 	Runs []*Run
 }
 
+// expTagFieldPrefix prefix of the experiment info hash fields holding its
+// tags, e.g. "Tag:team".
+const expTagFieldPrefix = "Tag:"
+
 // ExperimentInfo is struct that represent
 // additional (user-set) information on an experiment.
 type ExperimentInfo struct {
-	Description string `redis:"Description"`
+	// Name display name of the experiment, its id when empty.
+	Name        string
+	Description string
+	Owner       string
+	Tags        map[string]string
+	// CreatedAt set when the experiment was created with CreateExperiment,
+	// zero for experiments created by their first run.
+	CreatedAt time.Time
 }
 
 // NewExperimentInfo creates an ExperimentInfo struct from the provided hasmap.
 func NewExperimentInfo(m map[string]string) ExperimentInfo {
-	return ExperimentInfo{
+	info := ExperimentInfo{
+		Name:        m["Name"],
 		Description: m["Description"],
+		Owner:       m["Owner"],
+		Tags:        nil,
+		CreatedAt:   time.Time{},
+	}
+
+	for field, v := range m {
+		if k, ok := strings.CutPrefix(field, expTagFieldPrefix); ok {
+			if info.Tags == nil {
+				info.Tags = make(map[string]string)
+			}
+
+			info.Tags[k] = v
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339, m["CreatedAt"]); err == nil {
+		info.CreatedAt = t
 	}
+
+	return info
+}
+
+// Hash returns the fields of the info as stored in a redis hash, the
+// reverse of NewExperimentInfo. Empty fields are left out.
+func (i ExperimentInfo) Hash() map[string]any {
+	fields := make(map[string]any, 4+len(i.Tags)) //nolint: mnd
+
+	for field, v := range map[string]string{"Name": i.Name, "Description": i.Description, "Owner": i.Owner} {
+		if v != "" {
+			fields[field] = v
+		}
+	}
+
+	for k, v := range i.Tags {
+		fields[ExpTagField(k)] = v
+	}
+
+	if !i.CreatedAt.IsZero() {
+		fields["CreatedAt"] = i.CreatedAt.Format(time.RFC3339)
+	}
+
+	return fields
+}
+
+// ExpTagField returns the experiment info hash field holding the tag k.
+func ExpTagField(k string) string {
+	return expTagFieldPrefix + k
+}
+
+// UpdateExperiment struct used to update an experiment's info. Nil fields
+// are left unchanged, Tags are set (or overwritten) and RemoveTags deleted.
+type UpdateExperiment struct {
+	Name        *string
+	Description *string
+	Owner       *string
+	Tags        map[string]string
+	RemoveTags  []string
+}
+
+// Normalize normalizes the tag keys of the update, see NormalizeTags.
+func (u UpdateExperiment) Normalize() (UpdateExperiment, error) {
+	tags, err := NormalizeTags(u.Tags)
+	if err != nil {
+		return u, err
+	}
+
+	removed := make([]string, len(u.RemoveTags))
+
+	for i, k := range u.RemoveTags {
+		removed[i], err = NormalizeTagKey(k)
+		if err != nil {
+			return u, err
+		}
+	}
+
+	u.Tags, u.RemoveTags = tags, removed
+
+	return u, nil
 }
@@ -0,0 +1,58 @@
+package types_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestExperimentInfoHash(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		info types.ExperimentInfo
+	}{
+		{"empty", types.ExperimentInfo{}},                                           //nolint: exhaustruct
+		{"description_only", types.ExperimentInfo{Description: "resnet baselines"}}, //nolint: exhaustruct
+		{"full", types.ExperimentInfo{
+			Name:        "ResNet baselines",
+			Description: "resnet baselines",
+			Owner:       "vision-team",
+			Tags:        map[string]string{"dataset": "imagenet", "stage": "dev"},
+			CreatedAt:   time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			hash := make(map[string]string)
+			for k, v := range tc.info.Hash() {
+				hash[k] = fmt.Sprint(v)
+			}
+
+			assert.Equal(t, tc.info, types.NewExperimentInfo(hash))
+		})
+	}
+}
+
+func TestUpdateExperimentNormalize(t *testing.T) {
+	t.Parallel()
+
+	update, err := types.UpdateExperiment{ //nolint: exhaustruct
+		Tags:       map[string]string{" Stage ": "prod"},
+		RemoveTags: []string{"Dataset"},
+	}.Normalize()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"stage": "prod"}, update.Tags)
+	assert.Equal(t, []string{"dataset"}, update.RemoveTags)
+
+	_, err = types.UpdateExperiment{RemoveTags: []string{"a:b"}}.Normalize() //nolint: exhaustruct
+	require.ErrorIs(t, err, types.ErrBadRequest)
+}
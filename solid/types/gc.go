@@ -0,0 +1,31 @@
+package types //nolint: var-naming
+
+import "time"
+
+// StoredObject an object of the object store.
+type StoredObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// DanglingRef a reference to an object missing from the object store, e.g.
+// the artifact hash "artifact:model.pt:linear-regression".
+type DanglingRef struct {
+	Ref string `json:"ref"`
+	Key string `json:"key"`
+}
+
+// GCReport the outcome of an artifact garbage collection, which reconciles
+// the objects of the object store with what references them.
+type GCReport struct {
+	// Scanned the number of objects in the object store.
+	Scanned int `json:"scanned"`
+	// Orphans the keys of the objects nothing references.
+	Orphans []string `json:"orphans"`
+	// Deleted the keys of the orphans deleted, the ones older than the
+	// grace period.
+	Deleted []string `json:"deleted"`
+	// Dangling the references to objects missing from the object store.
+	Dangling []DanglingRef `json:"dangling"`
+}
@@ -0,0 +1,189 @@
+package types //nolint: var-naming
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	ImageContentType       ContentType = "content-type/image"
+	TableContentType       ContentType = "content-type/table"
+	PlotContentType        ContentType = "content-type/plot"
+	AudioContentType       ContentType = "content-type/audio"
+	VideoContentType       ContentType = "content-type/video"
+	ONNXContentType        ContentType = "content-type/onnx"
+	SafetensorsContentType ContentType = "content-type/safetensors"
+	BinaryContentType      ContentType = "content-type/binary"
+)
+
+// DefaultMIMEType the MIME type of content that cannot be told apart.
+const DefaultMIMEType = "application/octet-stream"
+
+// sniffLen the number of bytes of content MIME sniffing looks at.
+const sniffLen = 512
+
+// ArtifactKind a kind of artifact content, identified by its content type.
+// Artifacts are uploaded with the content type of their kind, and served
+// with the MIME type sniffed from their content.
+type ArtifactKind struct {
+	ContentType ContentType
+	// MIMEType the MIME type of content of the kind that sniffing cannot
+	// tell apart, e.g. model weights.
+	MIMEType string
+	// Extensions the MIME types of the file extensions of the kind, for
+	// content sniffing mistakes for plain text or binary, e.g. ".csv".
+	Extensions map[string]string
+}
+
+var (
+	artifactKindsMu sync.RWMutex                     //nolint: gochecknoglobals
+	artifactKinds   = map[ContentType]ArtifactKind{} //nolint: gochecknoglobals
+)
+
+func init() { //nolint: gochecknoinits
+	for _, k := range []ArtifactKind{
+		{TextContentType, "text/plain; charset=utf-8", map[string]string{
+			".txt": "text/plain; charset=utf-8", ".md": "text/markdown; charset=utf-8", ".log": "text/plain; charset=utf-8",
+			".json": "application/json", ".yaml": "application/yaml", ".yml": "application/yaml",
+		}},
+		{ModelContentType, DefaultMIMEType, nil},
+		{ImageContentType, DefaultMIMEType, map[string]string{
+			".svg": "image/svg+xml", ".tif": "image/tiff", ".tiff": "image/tiff",
+		}},
+		{TableContentType, "text/csv; charset=utf-8", map[string]string{
+			".csv": "text/csv; charset=utf-8", ".tsv": "text/tab-separated-values; charset=utf-8",
+			".parquet": "application/vnd.apache.parquet", ".json": "application/json",
+			".jsonl": "application/jsonl",
+		}},
+		{PlotContentType, DefaultMIMEType, map[string]string{
+			".svg": "image/svg+xml", ".json": "application/json", ".html": "text/html; charset=utf-8",
+		}},
+		{AudioContentType, DefaultMIMEType, map[string]string{".flac": "audio/flac", ".mp3": "audio/mpeg"}},
+		{VideoContentType, DefaultMIMEType, map[string]string{".mov": "video/quicktime", ".mkv": "video/x-matroska"}},
+		{ONNXContentType, DefaultMIMEType, nil},
+		{SafetensorsContentType, DefaultMIMEType, nil},
+		{BinaryContentType, DefaultMIMEType, nil},
+	} {
+		if err := RegisterArtifactKind(k); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterArtifactKind registers a kind of artifact, whose content type is
+// then accepted on upload.
+func RegisterArtifactKind(k ArtifactKind) error {
+	if !strings.HasPrefix(string(k.ContentType), "content-type/") || k.ContentType == DirectoryContentType {
+		return NewBadRequest(fmt.Sprintf("invalid artifact content type <%s>", k.ContentType))
+	}
+
+	if k.MIMEType == "" {
+		k.MIMEType = DefaultMIMEType
+	}
+
+	artifactKindsMu.Lock()
+	defer artifactKindsMu.Unlock()
+
+	if _, ok := artifactKinds[k.ContentType]; ok {
+		return NewAlreadyInUseErr(fmt.Sprintf("artifact kind <%s> already registered", k.ContentType))
+	}
+
+	artifactKinds[k.ContentType] = k
+
+	return nil
+}
+
+// LookupArtifactKind returns the registered kind of a content type.
+func LookupArtifactKind(ct ContentType) (ArtifactKind, bool) {
+	artifactKindsMu.RLock()
+	defer artifactKindsMu.RUnlock()
+
+	k, ok := artifactKinds[ct]
+
+	return k, ok
+}
+
+// ArtifactKinds returns the registered kinds, sorted by content type.
+func ArtifactKinds() []ArtifactKind {
+	artifactKindsMu.RLock()
+	defer artifactKindsMu.RUnlock()
+
+	kinds := make([]ArtifactKind, 0, len(artifactKinds))
+
+	for _, k := range artifactKinds {
+		kinds = append(kinds, k)
+	}
+
+	slices.SortFunc(kinds, func(a, b ArtifactKind) int { return strings.Compare(string(a.ContentType), string(b.ContentType)) })
+
+	return kinds
+}
+
+// DetectMIMEType returns the MIME type of the content of an artifact of
+// kind ct named name, from head, the first bytes of its content. The MIME
+// type sniffed from head wins, unless it is too generic to be useful (plain
+// text, xml or binary), in which case the extension of name is looked up in
+// the extensions of the kind, then of every kind.
+func DetectMIMEType(ct ContentType, name string, head []byte) string {
+	sniffed := ""
+	if len(head) > 0 {
+		sniffed = http.DetectContentType(head[:min(len(head), sniffLen)])
+	}
+
+	switch sniffed {
+	case "", DefaultMIMEType, "text/plain; charset=utf-8", "text/xml; charset=utf-8":
+	default:
+		return sniffed
+	}
+
+	kind, ok := LookupArtifactKind(ct)
+	ext := strings.ToLower(path.Ext(name))
+
+	if m, found := kind.Extensions[ext]; found {
+		return m
+	}
+
+	for _, k := range ArtifactKinds() {
+		if m, found := k.Extensions[ext]; found {
+			return m
+		}
+	}
+
+	if sniffed != "" && sniffed != DefaultMIMEType {
+		return sniffed
+	}
+
+	if ok {
+		return kind.MIMEType
+	}
+
+	return DefaultMIMEType
+}
+
+// MIMESniffer keeps the first bytes of the content written to it, e.g. as
+// an artifact is streamed in, to detect its MIME type.
+type MIMESniffer struct {
+	head []byte
+}
+
+func NewMIMESniffer() *MIMESniffer {
+	return &MIMESniffer{head: make([]byte, 0, sniffLen)}
+}
+
+func (s *MIMESniffer) Write(p []byte) (int, error) {
+	if n := sniffLen - len(s.head); n > 0 {
+		s.head = append(s.head, p[:min(n, len(p))]...)
+	}
+
+	return len(p), nil
+}
+
+// MIMEType returns the MIME type of the content written so far, see
+// DetectMIMEType.
+func (s *MIMESniffer) MIMEType(ct ContentType, name string) string {
+	return DetectMIMEType(ct, name, s.head)
+}
@@ -0,0 +1,134 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestIsValidContentType(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name        string
+		contentType string
+		expected    bool
+	}{
+		{"text", "content-type/text", true},
+		{"model", "content-type/model", true},
+		{"image", "content-type/image", true},
+		{"table", "content-type/table", true},
+		{"onnx", "content-type/onnx", true},
+		{"binary", "content-type/binary", true},
+		{"directory", "content-type/directory", false},
+		{"unknown", "content-type/unknown", false},
+		{"empty", "", false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, types.IsValidContentType(tc.contentType))
+		})
+	}
+}
+
+func TestRegisterArtifactKind(t *testing.T) {
+	t.Parallel()
+
+	kind := types.ArtifactKind{
+		ContentType: "content-type/point-cloud",
+		MIMEType:    "",
+		Extensions:  map[string]string{".ply": "application/ply"},
+	}
+
+	require.NoError(t, types.RegisterArtifactKind(kind))
+	assert.True(t, types.IsValidContentType("content-type/point-cloud"))
+
+	k, ok := types.LookupArtifactKind("content-type/point-cloud")
+	require.True(t, ok)
+	assert.Equal(t, types.DefaultMIMEType, k.MIMEType)
+	assert.Equal(t, "application/ply", types.DetectMIMEType("content-type/point-cloud", "scan.ply", nil))
+
+	require.ErrorIs(t, types.RegisterArtifactKind(kind), types.ErrAlreadyInUse)
+
+	kind.ContentType = types.DirectoryContentType
+	require.ErrorIs(t, types.RegisterArtifactKind(kind), types.ErrBadRequest)
+
+	kind.ContentType = "image"
+	require.ErrorIs(t, types.RegisterArtifactKind(kind), types.ErrBadRequest)
+}
+
+func TestDetectMIMEType(t *testing.T) {
+	t.Parallel()
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	testcases := []struct {
+		name        string
+		contentType types.ContentType
+		file        string
+		head        []byte
+		expected    string
+	}{
+		{"sniffed_image", types.ImageContentType, "loss.png", png, "image/png"},
+		{"sniffed_over_extension", types.PlotContentType, "loss.svg", png, "image/png"},
+		{"csv_table", types.TableContentType, "metrics.csv", []byte("epoch,loss\n1,0.5\n"), "text/csv; charset=utf-8"},
+		{"svg_plot", types.PlotContentType, "loss.svg", []byte(`<?xml version="1.0"?><svg></svg>`), "image/svg+xml"},
+		{"extension_of_other_kind", types.BinaryContentType, "config.json", []byte(`{"a": 1}`), "application/json"},
+		{"plain_text", types.TextContentType, "notes", []byte("hello world"), "text/plain; charset=utf-8"},
+		{"binary_model", types.ONNXContentType, "model.onnx", []byte{0x08, 0x07, 0x12, 0x00}, types.DefaultMIMEType},
+		{"empty_text", types.TextContentType, "notes", nil, "text/plain; charset=utf-8"},
+		{"empty_table", types.TableContentType, "metrics", nil, "text/csv; charset=utf-8"},
+		{"unknown_kind", "content-type/unknown", "blob", nil, types.DefaultMIMEType},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, types.DetectMIMEType(tc.contentType, tc.file, tc.head))
+		})
+	}
+}
+
+func TestMIMESniffer(t *testing.T) {
+	t.Parallel()
+
+	s := types.NewMIMESniffer()
+
+	// the content is written in chunks, as it is streamed in
+	for _, chunk := range []string{"\x89PNG", "\r\n\x1a\n", string(make([]byte, 1024))} {
+		n, err := s.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	assert.Equal(t, "image/png", s.MIMEType(types.ImageContentType, "loss.png"))
+}
+
+func TestSavedArtifactServedMIMEType(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		artifact types.SavedArtifact
+		expected string
+	}{
+		{"sniffed", types.SavedArtifact{ContentType: types.ImageContentType, MIMEType: "image/png"}, "image/png"}, //nolint: exhaustruct
+		{"legacy_text", types.SavedArtifact{ContentType: types.TextContentType}, "text/plain; charset=utf-8"},     //nolint: exhaustruct
+		{"legacy_model", types.SavedArtifact{ContentType: types.ModelContentType}, types.DefaultMIMEType},         //nolint: exhaustruct
+		{"directory", types.SavedArtifact{ContentType: types.DirectoryContentType}, types.DefaultMIMEType},        //nolint: exhaustruct
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.artifact.ServedMIMEType())
+		})
+	}
+}
@@ -1,8 +1,8 @@
 package types
 
 import (
-	"cmp"
 	"reflect"
+	"time"
 )
 
 type MetricType string
@@ -24,21 +24,51 @@ type Metric interface {
 	LastVal() any
 	AddVal(v any)
 	Type() MetricType
-}
-
-type GenericMetric[T cmp.Ordered] struct {
-	Key        string
-	Values     []T
-	unCommited []T
-}
-
-func NewGenericMetric[T cmp.Ordered](key string, sizeAlloc int) *GenericMetric[T] {
+	// Metas returns the ValMeta of each value, aligned with Vals.
+	Metas() []ValMeta
+	// SetMetas sets the ValMeta of the last len(ms) values, i.e. of the
+	// values just set with SetVals.
+	SetMetas(ms []ValMeta)
+	// MetasToCommit returns the ValMeta of each value, aligned with ValsToCommit.
+	MetasToCommit() []ValMeta
+	AddValWithMeta(v any, meta ValMeta)
+}
+
+// ValMeta optionally locates a metric value: the step (epoch, iteration...)
+// it was logged at and the client's wall-clock time when it was logged.
+// Both are nil when the client did not report them.
+type ValMeta struct {
+	Step      *int64     `json:"step,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// MetricPoint a metric value along with its ValMeta.
+type MetricPoint struct {
+	Value any `json:"value"`
+	ValMeta
+}
+
+// GenericMetric a metric holding values of type T. Values carry an optional
+// ValMeta, kept in Meta alongside Values; Meta may be shorter than Values
+// (e.g. for metrics built from bare values), missing entries being zero.
+// Metrics are stored for the types of ValueKind only.
+type GenericMetric[T any] struct {
+	Key             string
+	Values          []T
+	Meta            []ValMeta
+	unCommited      []T
+	unCommitedMetas []ValMeta
+}
+
+func NewGenericMetric[T any](key string, sizeAlloc int) *GenericMetric[T] {
 	m := GenericMetric[T]{
 		Key: normalizeName(key),
 	}
 
 	m.Values = make([]T, 0, sizeAlloc)
+	m.Meta = make([]ValMeta, 0, sizeAlloc)
 	m.unCommited = make([]T, 0, sizeAlloc)
+	m.unCommitedMetas = make([]ValMeta, 0, sizeAlloc)
 
 	return &m
 }
@@ -75,7 +105,25 @@ func (s GenericMetric[T]) ValsToCommit() []any {
 	return vals
 }
 
+func (s GenericMetric[T]) Metas() []ValMeta {
+	return alignMetas(s.Meta, len(s.Values))
+}
+
+func (s *GenericMetric[T]) SetMetas(ms []ValMeta) {
+	s.Meta = append(alignMetas(s.Meta, len(s.Values)-len(ms)), ms...)
+}
+
+func (s GenericMetric[T]) MetasToCommit() []ValMeta {
+	return alignMetas(s.unCommitedMetas, len(s.unCommited))
+}
+
 func (s *GenericMetric[T]) Add(v T) {
+	s.AddWithMeta(v, ValMeta{}) //nolint: exhaustruct
+}
+
+// AddWithMeta adds a value logged at the step and time described by meta.
+func (s *GenericMetric[T]) AddWithMeta(v T, meta ValMeta) {
+	s.unCommitedMetas = append(alignMetas(s.unCommitedMetas, len(s.unCommited)), meta)
 	s.unCommited = append(s.unCommited, v)
 }
 
@@ -83,13 +131,19 @@ func (s *GenericMetric[T]) AddVal(v any) {
 	s.Add(v.(T))
 }
 
+func (s *GenericMetric[T]) AddValWithMeta(v any, meta ValMeta) {
+	s.AddWithMeta(v.(T), meta)
+}
+
 func (s *GenericMetric[T]) UnCommited() []T {
 	return s.unCommited
 }
 
 func (s *GenericMetric[T]) Commit() {
+	s.Meta = append(alignMetas(s.Meta, len(s.Values)), s.MetasToCommit()...)
 	s.Values = append(s.Values, s.unCommited...)
 	s.unCommited = make([]T, 0)
+	s.unCommitedMetas = make([]ValMeta, 0)
 }
 
 func (s *GenericMetric[T]) LastVal() any {
@@ -160,3 +214,18 @@ func isNumericMetricType(m MetricType) bool {
 func isNaNMetricType(m MetricType) bool {
 	return m == SingleMetric || m == MultiValueMetric
 }
+
+// alignMetas returns metas resized to n entries, padding with zero ValMeta or
+// truncating as needed.
+func alignMetas(metas []ValMeta, n int) []ValMeta {
+	n = max(n, 0)
+
+	if len(metas) >= n {
+		return metas[:n:n]
+	}
+
+	out := make([]ValMeta, n)
+	copy(out, metas)
+
+	return out
+}
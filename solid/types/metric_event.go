@@ -0,0 +1,47 @@
+package types
+
+// MetricEvent a metric value as read from the metric stream of a run, ID
+// being the id of its stream entry.
+type MetricEvent struct {
+	ID     string `json:"id"`
+	Metric string `json:"metric"`
+	MetricPoint
+}
+
+// MetricCursor tracks how far the metric streams of a run were read by a
+// watcher. Streams maps a metric name to the id of the last entry read from
+// it; streams not read yet are read from after After ("" or "0" being the
+// start of the stream).
+type MetricCursor struct {
+	After   string
+	Streams map[string]string
+}
+
+// NewMetricCursor creates a cursor reading all metric streams from after
+// the entry with id after.
+func NewMetricCursor(after string) *MetricCursor {
+	return &MetricCursor{
+		After:   after,
+		Streams: make(map[string]string),
+	}
+}
+
+// Position returns the id of the last entry read from the stream of metric.
+func (c *MetricCursor) Position(metric string) string {
+	if id, ok := c.Streams[metric]; ok {
+		return id
+	}
+
+	if c.After == "" {
+		return "0"
+	}
+
+	return c.After
+}
+
+// Advance moves the cursor past events, which are expected in stream order.
+func (c *MetricCursor) Advance(events []MetricEvent) {
+	for _, e := range events {
+		c.Streams[e.Metric] = e.ID
+	}
+}
@@ -2,8 +2,10 @@ package types_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/zeddo123/mlsolid/solid/types"
 )
 
@@ -136,3 +138,103 @@ func TestGenericMetricTypeReflection(t *testing.T) {
 		assert.Equal(t, types.SingleMetric, m.Type())
 	})
 }
+
+func TestGenericMetricMetas(t *testing.T) {
+	t.Parallel()
+
+	step := int64(3)
+	at := time.Date(2025, 5, 8, 14, 30, 0, 0, time.UTC)
+
+	t.Run("metas_follow_values_through_commit", func(t *testing.T) {
+		t.Parallel()
+
+		m := types.NewGenericMetric[float64]("loss", 2)
+
+		m.Add(0.9)
+		m.AddWithMeta(0.5, types.ValMeta{Step: &step, Timestamp: &at})
+
+		require.Len(t, m.MetasToCommit(), 2)
+		assert.Nil(t, m.MetasToCommit()[0].Step)
+		assert.Equal(t, &step, m.MetasToCommit()[1].Step)
+
+		m.Commit()
+
+		require.Len(t, m.Metas(), 2)
+		assert.Empty(t, m.MetasToCommit())
+		assert.Equal(t, &at, m.Metas()[1].Timestamp)
+	})
+
+	t.Run("metrics_without_metas_are_padded", func(t *testing.T) {
+		t.Parallel()
+
+		m := types.GenericMetric[string]{
+			Key:    "paths",
+			Values: []string{"path1", "path2"},
+		}
+
+		assert.Equal(t, []types.ValMeta{{}, {}}, m.Metas())
+
+		m.AddWithMeta("path3", types.ValMeta{Step: &step})
+		m.Commit()
+
+		require.Len(t, m.Metas(), 3)
+		assert.Equal(t, &step, m.Metas()[2].Step)
+	})
+
+	t.Run("set_metas_applies_to_the_last_values", func(t *testing.T) {
+		t.Parallel()
+
+		m := &types.GenericMetric[int64]{Key: "epoch"}
+
+		m.SetVals([]any{int64(1), int64(2)})
+		m.SetMetas([]types.ValMeta{{Step: &step}})
+
+		require.Len(t, m.Metas(), 2)
+		assert.Nil(t, m.Metas()[0].Step)
+		assert.Equal(t, &step, m.Metas()[1].Step)
+	})
+}
+
+func TestMetricCursor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		after  string
+		events []types.MetricEvent
+		want   map[string]string
+	}{
+		{
+			name:  "unread_streams_start_at_the_beginning",
+			after: "",
+			want:  map[string]string{"loss": "0", "acc": "0"},
+		},
+		{
+			name:  "unread_streams_start_after_the_cursor",
+			after: "1700000000000-0",
+			want:  map[string]string{"loss": "1700000000000-0", "acc": "1700000000000-0"},
+		},
+		{
+			name:  "read_streams_resume_after_their_last_entry",
+			after: "1700000000000-0",
+			events: []types.MetricEvent{
+				{ID: "1700000000001-0", Metric: "loss"},
+				{ID: "1700000000002-0", Metric: "loss"},
+			},
+			want: map[string]string{"loss": "1700000000002-0", "acc": "1700000000000-0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := types.NewMetricCursor(tt.after)
+			c.Advance(tt.events)
+
+			for metric, want := range tt.want {
+				assert.Equal(t, want, c.Position(metric), metric)
+			}
+		})
+	}
+}
@@ -14,6 +14,18 @@ type ModelEntry struct {
 	Timestamp time.Time `json:"timestamp"`
 	Version   int       `json:"version"`
 	Run       string    `json:"run"`
+	// Files the files of entries registered from a directory artifact,
+	// whose URL is empty.
+	Files []ArtifactFile `json:"files,omitempty"`
+	// Encoding the compression the content at URL is stored with.
+	Encoding ContentEncoding `json:"encoding,omitempty"`
+}
+
+// References reports whether the entry was registered from the artifact a
+// of run runID. Entries merely sharing its content, e.g. registered from
+// another run's artifact stored as the same blob, do not reference it.
+func (e ModelEntry) References(runID string, a SavedArtifact) bool {
+	return e.Run == runID && e.Name == a.Name
 }
 
 // ModelRegistry holds data related to a registry.
@@ -148,6 +160,20 @@ func (m *ModelRegistry) AddArtifact(run, artifactName string, url string, tags .
 	m.pushEntry(e)
 }
 
+// AddEncodedArtifact adds a new model entry from an artifact of a run whose
+// content is stored at url compressed with encoding.
+func (m *ModelRegistry) AddEncodedArtifact(run, artifactName, url string, encoding ContentEncoding, tags ...string) {
+	m.AddArtifact(run, artifactName, url, tags...)
+	m.Models[len(m.Models)-1].Encoding = encoding
+}
+
+// AddDirectoryArtifact adds a new model entry from a directory artifact of
+// a run and assigns it a new version number.
+func (m *ModelRegistry) AddDirectoryArtifact(run, artifactName string, files []ArtifactFile, tags ...string) {
+	m.AddArtifact(run, artifactName, "", tags...)
+	m.Models[len(m.Models)-1].Files = files
+}
+
 // MarshalEntries marshals all model entries to json.
 func (m *ModelRegistry) MarshalEntries() ([][]byte, error) {
 	res := make([][]byte, len(m.Models))
@@ -47,3 +47,30 @@ func TestModelRegistry(t *testing.T) {
 		assert.Contains(t, model.Tags, "prod")
 	})
 }
+
+func TestModelEntryReferences(t *testing.T) {
+	t.Parallel()
+
+	artifact := types.SavedArtifact{Name: "model.pt", S3Key: "exp-run-model.pt"} //nolint: exhaustruct
+
+	testcases := []struct {
+		name     string
+		entry    types.ModelEntry
+		runID    string
+		expected bool
+	}{
+		{"run_artifact", types.ModelEntry{Run: "run", Name: "model.pt"}, "run", true},                                      //nolint: exhaustruct
+		{"same_name_other_run", types.ModelEntry{Run: "other", Name: "model.pt"}, "run", false},                            //nolint: exhaustruct
+		{"same_name_other_artifact", types.ModelEntry{Run: "run", Name: "other.pt"}, "run", false},                         //nolint: exhaustruct
+		{"shared_blob_other_run", types.ModelEntry{Run: "other", Name: "model.pt", URL: "exp-run-model.pt"}, "run", false}, //nolint: exhaustruct
+		{"s3_key_url", types.ModelEntry{URL: "exp-run-model.pt"}, "run", false},                                            //nolint: exhaustruct
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.entry.References(tc.runID, artifact))
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParamType type of a run parameter value.
+type ParamType string
+
+const (
+	StringParam ParamType = "string"
+	IntParam    ParamType = "int"
+	FloatParam  ParamType = "float"
+	BoolParam   ParamType = "bool"
+)
+
+// Param a typed hyperparameter of a run. Unlike metrics, a param holds a
+// single value which cannot change once logged.
+type Param struct {
+	Type  ParamType `json:"type"`
+	Value any       `json:"value"`
+}
+
+// NewParam builds a param from a string, integer, float or bool value.
+// Returns an ErrBadRequest error for any other type.
+func NewParam(v any) (Param, error) {
+	switch val := v.(type) {
+	case string:
+		return Param{Type: StringParam, Value: val}, nil
+	case int:
+		return Param{Type: IntParam, Value: int64(val)}, nil
+	case int32:
+		return Param{Type: IntParam, Value: int64(val)}, nil
+	case int64:
+		return Param{Type: IntParam, Value: val}, nil
+	case float32:
+		return Param{Type: FloatParam, Value: float64(val)}, nil
+	case float64:
+		return Param{Type: FloatParam, Value: val}, nil
+	case bool:
+		return Param{Type: BoolParam, Value: val}, nil
+	default:
+		return Param{}, NewBadRequest(fmt.Sprintf("unsupported param value type %T", v)) //nolint: exhaustruct
+	}
+}
+
+// String formats the param's value, e.g. for comparing it against a filter.
+func (p Param) String() string {
+	switch val := p.Value.(type) {
+	case string:
+		return val
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// Encode serializes the param as "<type>:<value>".
+func (p Param) Encode() string {
+	return string(p.Type) + ":" + p.String()
+}
+
+// DecodeParam parses a param serialized with Param.Encode.
+func DecodeParam(s string) (Param, error) {
+	kind, raw, ok := strings.Cut(s, ":")
+	if !ok {
+		return Param{}, NewInvalidInputErr(fmt.Sprintf("malformed param %q", s)) //nolint: exhaustruct
+	}
+
+	var (
+		v   any
+		err error
+	)
+
+	switch ParamType(kind) {
+	case StringParam:
+		v = raw
+	case IntParam:
+		v, err = strconv.ParseInt(raw, 10, 64)
+	case FloatParam:
+		v, err = strconv.ParseFloat(raw, 64)
+	case BoolParam:
+		v, err = strconv.ParseBool(raw)
+	default:
+		return Param{}, NewInvalidInputErr(fmt.Sprintf("unknown param type %q", kind)) //nolint: exhaustruct
+	}
+
+	if err != nil {
+		return Param{}, NewInvalidInputErr(fmt.Sprintf("malformed %s param %q", kind, raw)) //nolint: exhaustruct
+	}
+
+	return Param{Type: ParamType(kind), Value: v}, nil
+}
+
+// MatchParams reports whether params holds every param in filters, values
+// being compared in their String form (so "0.1" matches a float 0.1 and
+// "true" a bool true).
+func MatchParams(params map[string]Param, filters map[string]string) bool {
+	for name, want := range filters {
+		p, ok := params[name]
+		if !ok || p.String() != want {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,69 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestParamEncoding(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		value   any
+		kind    types.ParamType
+		encoded string
+	}{
+		{"string", "adam", types.StringParam, "string:adam"},
+		{"string_with_colon", "a:b", types.StringParam, "string:a:b"},
+		{"int", 32, types.IntParam, "int:32"},
+		{"int64", int64(-4), types.IntParam, "int:-4"},
+		{"float", 0.001, types.FloatParam, "float:0.001"},
+		{"whole_float", 2.0, types.FloatParam, "float:2"},
+		{"bool", true, types.BoolParam, "bool:true"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := types.NewParam(tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.kind, p.Type)
+			assert.Equal(t, tc.encoded, p.Encode())
+
+			decoded, err := types.DecodeParam(p.Encode())
+			require.NoError(t, err)
+			assert.Equal(t, p, decoded)
+		})
+	}
+}
+
+func TestParamErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := types.NewParam([]int{1})
+	require.ErrorIs(t, err, types.ErrBadRequest)
+
+	for _, encoded := range []string{"adam", "complex:1i", "int:abc", "bool:maybe"} {
+		_, err := types.DecodeParam(encoded)
+		require.ErrorIs(t, err, types.ErrInvalidInput, encoded)
+	}
+}
+
+func TestMatchParams(t *testing.T) {
+	t.Parallel()
+
+	lr, _ := types.NewParam(0.01)
+	opt, _ := types.NewParam("adam")
+	params := map[string]types.Param{"lr": lr, "optimizer": opt}
+
+	assert.True(t, types.MatchParams(params, nil))
+	assert.True(t, types.MatchParams(params, map[string]string{"lr": "0.01"}))
+	assert.True(t, types.MatchParams(params, map[string]string{"lr": "0.01", "optimizer": "adam"}))
+	assert.False(t, types.MatchParams(params, map[string]string{"optimizer": "sgd"}))
+	assert.False(t, types.MatchParams(params, map[string]string{"batch-size": "32"}))
+}
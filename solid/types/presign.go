@@ -0,0 +1,13 @@
+package types //nolint: var-naming
+
+import "time"
+
+// PresignedRequest a short-lived request to the object store signed by
+// mlsolid, letting clients transfer artifact content without holding S3
+// credentials. Headers must be sent along with the request.
+type PresignedRequest struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
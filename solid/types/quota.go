@@ -0,0 +1,64 @@
+package types //nolint: var-naming
+
+import "fmt"
+
+// StorageQuota the limits on the artifact storage of runs and experiments,
+// zero limits being unlimited.
+type StorageQuota struct {
+	// MaxArtifactSize the size in bytes of the largest artifact accepted.
+	MaxArtifactSize int64
+	// Run the bytes of artifacts a run may hold.
+	Run int64
+	// Experiment the bytes of artifacts the runs of an experiment may hold.
+	Experiment int64
+}
+
+// StorageUsage the storage used by the artifacts of a run or of the runs of
+// an experiment. Artifacts count with their whole size, even when their
+// content is shared with other artifacts or stored compressed.
+type StorageUsage struct {
+	Bytes     int64 `json:"bytes"`
+	Artifacts int64 `json:"artifacts"`
+	// Quota the bytes of artifacts that may be held, zero when unlimited.
+	Quota int64 `json:"quota"`
+}
+
+// Remaining the bytes of artifacts that may still be saved, -1 when
+// unlimited.
+func (u StorageUsage) Remaining() int64 {
+	if u.Quota <= 0 {
+		return -1
+	}
+
+	return max(u.Quota-u.Bytes, 0)
+}
+
+// CheckArtifactSize rejects artifacts larger than MaxArtifactSize with an
+// ErrResourceExhausted error.
+func (q StorageQuota) CheckArtifactSize(size int64) error {
+	if q.MaxArtifactSize > 0 && size > q.MaxArtifactSize {
+		return NewResourceExhaustedErr(fmt.Sprintf("artifact of %d bytes exceeds the maximum artifact size of %d bytes",
+			size, q.MaxArtifactSize))
+	}
+
+	return nil
+}
+
+// ArtifactAllowance returns the size in bytes of the largest artifact that
+// may be saved to a run, given the storage used by the run and by its
+// experiment, -1 when unlimited.
+func (q StorageQuota) ArtifactAllowance(run, exp StorageUsage) int64 {
+	allowance := int64(-1)
+
+	if q.MaxArtifactSize > 0 {
+		allowance = q.MaxArtifactSize
+	}
+
+	for _, remaining := range []int64{run.Remaining(), exp.Remaining()} {
+		if remaining >= 0 && (allowance < 0 || remaining < allowance) {
+			allowance = remaining
+		}
+	}
+
+	return allowance
+}
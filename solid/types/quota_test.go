@@ -0,0 +1,60 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestStorageQuotaArtifactAllowance(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		quota    types.StorageQuota
+		run      types.StorageUsage
+		exp      types.StorageUsage
+		expected int64
+	}{
+		{"unlimited", types.StorageQuota{}, types.StorageUsage{Bytes: 100}, types.StorageUsage{Bytes: 100}, -1},
+		{"max_artifact_size", types.StorageQuota{MaxArtifactSize: 50}, types.StorageUsage{}, types.StorageUsage{}, 50},
+		{
+			"run_quota", types.StorageQuota{MaxArtifactSize: 50},
+			types.StorageUsage{Bytes: 80, Quota: 100}, types.StorageUsage{}, 20,
+		},
+		{
+			"experiment_quota", types.StorageQuota{},
+			types.StorageUsage{Bytes: 10, Quota: 100}, types.StorageUsage{Bytes: 995, Quota: 1000}, 5,
+		},
+		{
+			"over_quota", types.StorageQuota{},
+			types.StorageUsage{Bytes: 120, Quota: 100}, types.StorageUsage{}, 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.quota.ArtifactAllowance(tc.run, tc.exp))
+		})
+	}
+}
+
+func TestStorageQuotaCheckArtifactSize(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, types.StorageQuota{}.CheckArtifactSize(1<<40))
+	require.NoError(t, types.StorageQuota{MaxArtifactSize: 100}.CheckArtifactSize(100))
+	require.ErrorIs(t, types.StorageQuota{MaxArtifactSize: 100}.CheckArtifactSize(101), types.ErrResourceExhausted)
+}
+
+func TestStorageUsageRemaining(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, int64(-1), types.StorageUsage{Bytes: 10}.Remaining())
+	assert.Equal(t, int64(90), types.StorageUsage{Bytes: 10, Quota: 100}.Remaining())
+	assert.Equal(t, int64(0), types.StorageUsage{Bytes: 110, Quota: 100}.Remaining())
+}
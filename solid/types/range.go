@@ -0,0 +1,85 @@
+package types //nolint: var-naming
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable a byte range outside of the content requested.
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ByteRange a range of bytes of content, both ends included.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Length the number of bytes in the range.
+func (r ByteRange) Length() int64 {
+	return r.End - r.Start + 1
+}
+
+// ContentRange returns the value of the Content-Range header of the range
+// of content of size bytes.
+func (r ByteRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.End, size)
+}
+
+// ParseByteRange parses the value of a Range header, e.g. "bytes=0-1023",
+// against content of size bytes. A nil range is returned for an empty
+// header or one the whole content is served for, i.e. a header in another
+// unit, holding several ranges, or malformed, as servers may ignore them.
+// A range starting past the content is an ErrRangeNotSatisfiable error.
+func ParseByteRange(header string, size int64) (*ByteRange, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, nil //nolint: nilnil
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, nil //nolint: nilnil
+	}
+
+	var r ByteRange
+
+	if first == "" {
+		// suffix range, the last bytes of the content
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return nil, nil //nolint: nilnil
+		}
+
+		if n == 0 || size == 0 {
+			return nil, fmt.Errorf("%w: empty suffix range %q", ErrRangeNotSatisfiable, header)
+		}
+
+		r = ByteRange{Start: max(size-n, 0), End: size - 1}
+
+		return &r, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil //nolint: nilnil
+	}
+
+	end := size - 1
+
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return nil, nil //nolint: nilnil
+		}
+	}
+
+	if start >= size {
+		return nil, fmt.Errorf("%w: range %q starts past %d bytes", ErrRangeNotSatisfiable, header, size)
+	}
+
+	r = ByteRange{Start: start, End: min(end, size-1)}
+
+	return &r, nil
+}
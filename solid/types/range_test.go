@@ -0,0 +1,60 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestParseByteRange(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		header   string
+		size     int64
+		expected *types.ByteRange
+		err      error
+	}{
+		{"no_range", "", 100, nil, nil},
+		{"range", "bytes=0-9", 100, &types.ByteRange{Start: 0, End: 9}, nil},
+		{"open_ended", "bytes=90-", 100, &types.ByteRange{Start: 90, End: 99}, nil},
+		{"past_the_end", "bytes=90-200", 100, &types.ByteRange{Start: 90, End: 99}, nil},
+		{"suffix", "bytes=-10", 100, &types.ByteRange{Start: 90, End: 99}, nil},
+		{"suffix_longer_than_content", "bytes=-200", 100, &types.ByteRange{Start: 0, End: 99}, nil},
+		{"other_unit", "items=0-9", 100, nil, nil},
+		{"several_ranges", "bytes=0-9,20-29", 100, nil, nil},
+		{"malformed", "bytes=a-b", 100, nil, nil},
+		{"reversed", "bytes=9-0", 100, nil, nil},
+		{"starts_past_the_end", "bytes=100-", 100, nil, types.ErrRangeNotSatisfiable},
+		{"empty_suffix", "bytes=-0", 100, nil, types.ErrRangeNotSatisfiable},
+		{"empty_content", "bytes=0-9", 0, nil, types.ErrRangeNotSatisfiable},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := types.ParseByteRange(tc.header, tc.size)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, r)
+		})
+	}
+}
+
+func TestByteRange(t *testing.T) {
+	t.Parallel()
+
+	r := types.ByteRange{Start: 10, End: 19}
+
+	assert.Equal(t, int64(10), r.Length())
+	assert.Equal(t, "bytes 10-19/100", r.ContentRange(100))
+}
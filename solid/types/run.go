@@ -7,6 +7,7 @@ import (
 	"maps"
 	"math/rand/v2"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -16,17 +17,64 @@ var (
 	initRandSource sync.Once     //nolint: gochecknoglobals
 )
 
+// RunStatus lifecycle state of a run.
+type RunStatus string
+
+const (
+	// RunRunning the run is in progress and its client is still reporting.
+	RunRunning RunStatus = "running"
+	// RunFinished the run completed successfully.
+	RunFinished RunStatus = "finished"
+	// RunFailed the run crashed, or its client stopped reporting.
+	RunFailed RunStatus = "failed"
+	// RunKilled the run was stopped on purpose before completing.
+	RunKilled RunStatus = "killed"
+)
+
+// ParseRunStatus parses a run status, returning an ErrBadRequest error for
+// unknown statuses.
+func ParseRunStatus(s string) (RunStatus, error) {
+	status := RunStatus(s)
+
+	switch status {
+	case RunRunning, RunFinished, RunFailed, RunKilled:
+		return status, nil
+	default:
+		return "", NewBadRequest(fmt.Sprintf("unknown run status %q", s))
+	}
+}
+
+// Terminal reports whether no further transition is allowed from s.
+func (s RunStatus) Terminal() bool {
+	return s == RunFinished || s == RunFailed || s == RunKilled
+}
+
 // Run struct holds all data (information, metrics, artifacts) related to a run.
+// Runs created before statuses were tracked have an empty Status.
+// Runs can be nested (e.g. the trials of a sweep): ParentID is the id of the
+// run a run is nested under, empty for top-level runs, and Children the ids
+// of the runs nested under it. Archived runs are hidden from experiment
+// listings and searches until restored. Summaries holds the MetricSummary of
+// each numeric metric, readable without loading Metrics.
 type Run struct {
 	Name         string
 	Timestamp    time.Time
 	ExperimentID string
 	Color        string
+	Status       RunStatus
+	EndTime      time.Time
+	Params       map[string]Param
+	Tags         map[string]string
+	Note         string
+	ParentID     string
+	Children     []string
+	Archived     bool
 	Metrics      map[string]Metric
+	Summaries    map[string]MetricSummary
 	Artifacts    map[string]Artifact
 }
 
-// NewRun initializes a new run with a name and an experiment id.
+// NewRun initializes a new running run with a name and an experiment id.
 // The supplied name is normalized to avoid whitespace.
 func NewRun(name string, expID string) Run {
 	return Run{
@@ -34,11 +82,77 @@ func NewRun(name string, expID string) Run {
 		ExperimentID: expID,
 		Timestamp:    time.Now(),
 		Color:        generateColor(),
+		Status:       RunRunning,
+		EndTime:      time.Time{},
+		Params:       make(map[string]Param),
+		Tags:         make(map[string]string),
+		Note:         "",
+		ParentID:     "",
+		Children:     []string{},
+		Archived:     false,
 		Metrics:      make(map[string]Metric),
+		Summaries:    make(map[string]MetricSummary),
 		Artifacts:    make(map[string]Artifact),
 	}
 }
 
+// NormalizeTags normalizes tag keys like run ids and validates them. Keys
+// cannot be empty nor contain ':'.
+func NormalizeTags(tags map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(tags))
+
+	for k, v := range tags {
+		key, err := NormalizeTagKey(k)
+		if err != nil {
+			return nil, err
+		}
+
+		normalized[key] = v
+	}
+
+	return normalized, nil
+}
+
+// NormalizeTagKey normalizes and validates a single tag key, see NormalizeTags.
+func NormalizeTagKey(k string) (string, error) {
+	key := normalizeName(k)
+
+	if key == "" {
+		return "", NewBadRequest("tag key cannot be empty")
+	}
+
+	if strings.Contains(key, ":") {
+		return "", NewBadRequest(fmt.Sprintf("tag key %q cannot contain ':'", k))
+	}
+
+	return key, nil
+}
+
+// RunFilter selects runs by their params and tags. A run matches when it
+// has every listed param and tag with the given value; an empty filter
+// matches all runs.
+type RunFilter struct {
+	Params map[string]string
+	Tags   map[string]string
+}
+
+// Empty reports whether the filter matches all runs.
+func (f RunFilter) Empty() bool {
+	return len(f.Params) == 0 && len(f.Tags) == 0
+}
+
+// CanTransition reports whether a run in status from may move to status to.
+// Runs only ever move forward: once terminal, a run's status is final.
+// Runs without a status (created before statuses were tracked) may be
+// given any status.
+func CanTransition(from, to RunStatus) bool {
+	if from.Terminal() {
+		return false
+	}
+
+	return to == RunRunning || to.Terminal()
+}
+
 // AddMetric adds a new metric with a name.
 // returns an error if the metric name is already in use `ErrAlreadyInUse`.
 func (r *Run) AddMetric(name string, m Metric) error {
@@ -87,6 +201,83 @@ func (r *Run) ArtifactsSlice() []Artifact {
 	return artifacts
 }
 
+// CollectMetricPoints is like CollectMetric but pairs each value with the
+// step and time it was logged at, so curves of runs logged at different
+// frequencies can be aligned.
+func CollectMetricPoints(runs []*Run, metric string) map[string][]MetricPoint {
+	return MetricPoints(runMetrics(runs, metric))
+}
+
+// MetricPoints pairs the values of metrics, keyed by run id, with the step
+// and time they were logged at.
+func MetricPoints(metrics map[string]Metric) map[string][]MetricPoint {
+	points := make(map[string][]MetricPoint, len(metrics))
+
+	for id, m := range metrics {
+		vals := m.Vals()
+		metas := m.Metas()
+		ps := make([]MetricPoint, len(vals))
+
+		for i, v := range vals {
+			ps[i] = MetricPoint{Value: v, ValMeta: metas[i]}
+		}
+
+		points[id] = ps
+	}
+
+	return points
+}
+
+// MetricAggregate summarizes the final (last) values a numeric metric took
+// across a set of runs, e.g. the trials of a sweep.
+type MetricAggregate struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	MinRun string  `json:"minRun"`
+	MaxRun string  `json:"maxRun"`
+}
+
+// AggregateFinalMetrics aggregates the last value of each metric of runs.
+// Metrics whose last value is not numeric are skipped. Ties on min/max keep
+// the first run in runs' order.
+func AggregateFinalMetrics(runs []*Run) map[string]MetricAggregate {
+	aggs := make(map[string]MetricAggregate)
+
+	for _, run := range runs {
+		for name, m := range run.Metrics {
+			vals := m.Vals()
+			if len(vals) == 0 {
+				continue
+			}
+
+			v, ok := NumericVal(vals[len(vals)-1])
+			if !ok {
+				continue
+			}
+
+			agg, seen := aggs[name]
+
+			if !seen || v < agg.Min {
+				agg.Min, agg.MinRun = v, run.Name
+			}
+
+			if !seen || v > agg.Max {
+				agg.Max, agg.MaxRun = v, run.Name
+			}
+
+			// running mean, avoids summing large values
+			agg.Count++
+			agg.Mean += (v - agg.Mean) / float64(agg.Count)
+
+			aggs[name] = agg
+		}
+	}
+
+	return aggs
+}
+
 // UniqueMetrics returns all distinct/unique metric names ids from a slice of runs.
 func UniqueMetrics(runs []*Run) []string {
 	metrics := make(map[string]struct{})
@@ -103,20 +294,35 @@ func UniqueMetrics(runs []*Run) []string {
 // CollectMetric aggregates all values of a metric present in a slice of runs.
 // Returns a key-value map of runIds (keys) and metric values.
 func CollectMetric(runs []*Run, metric string) (map[string]any, MetricType) {
-	metrics := make(map[string]any, len(runs))
+	return MetricVals(runMetrics(runs, metric))
+}
+
+// MetricVals is like CollectMetric for the values of a metric already
+// pulled per run, keyed by run id.
+func MetricVals(metrics map[string]Metric) (map[string]any, MetricType) {
+	vals := make(map[string]any, len(metrics))
 
 	var kind MetricType
 
+	for id, m := range metrics {
+		vals[id] = m.Vals()
+		kind = metricTypePrededence(kind, m.Type())
+	}
+
+	return vals, kind
+}
+
+// runMetrics returns the metric of each run that logged it, keyed by run id.
+func runMetrics(runs []*Run, metric string) map[string]Metric {
+	metrics := make(map[string]Metric, len(runs))
+
 	for _, run := range runs {
 		if m, ok := run.Metrics[metric]; ok {
-			metrics[run.Name] = m.Vals()
-			curr := m.Type()
-
-			kind = metricTypePrededence(kind, curr)
+			metrics[run.Name] = m
 		}
 	}
 
-	return metrics, kind
+	return metrics
 }
 
 // generateColor generetes a random color in hex format `#342d13`.
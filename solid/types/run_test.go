@@ -36,3 +36,122 @@ func TestRunColorGeneration(t *testing.T) {
 	assert.NotEmpty(t, r.Color)
 	assert.Regexp(t, "^#[a-fA-F0-9]{6}", r.Color)
 }
+
+func TestParseRunStatus(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name  string
+		input string
+		want  types.RunStatus
+		err   bool
+	}{
+		{"running", "running", types.RunRunning, false},
+		{"finished", "finished", types.RunFinished, false},
+		{"failed", "failed", types.RunFailed, false},
+		{"killed", "killed", types.RunKilled, false},
+		{"empty", "", "", true},
+		{"unknown", "paused", "", true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := types.ParseRunStatus(tc.input)
+			if tc.err {
+				require.ErrorIs(t, err, types.ErrBadRequest)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRunStatusTransitions(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		from, to types.RunStatus
+		allowed  bool
+	}{
+		{"running_to_finished", types.RunRunning, types.RunFinished, true},
+		{"running_to_killed", types.RunRunning, types.RunKilled, true},
+		{"running_to_running", types.RunRunning, types.RunRunning, true},
+		{"legacy_to_finished", "", types.RunFinished, true},
+		{"finished_to_running", types.RunFinished, types.RunRunning, false},
+		{"failed_to_finished", types.RunFailed, types.RunFinished, false},
+		{"killed_to_failed", types.RunKilled, types.RunFailed, false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.allowed, types.CanTransition(tc.from, tc.to))
+		})
+	}
+}
+
+func TestNewRunIsRunning(t *testing.T) {
+	t.Parallel()
+
+	r := types.NewRun("run", "exp")
+
+	assert.Equal(t, types.RunRunning, r.Status)
+	assert.Zero(t, r.EndTime)
+}
+
+func TestNormalizeTags(t *testing.T) {
+	t.Parallel()
+
+	tags, err := types.NormalizeTags(map[string]string{"Baseline": "true", "data set": "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"baseline": "true", "data-set": "v2"}, tags)
+
+	_, err = types.NormalizeTags(map[string]string{" ": "x"})
+	require.ErrorIs(t, err, types.ErrBadRequest)
+
+	_, err = types.NormalizeTags(map[string]string{"a:b": "x"})
+	require.ErrorIs(t, err, types.ErrBadRequest)
+}
+
+func TestAggregateFinalMetrics(t *testing.T) {
+	t.Parallel()
+
+	trial := func(name string, loss float64, optimizer string) *types.Run {
+		run := types.NewRun(name, "sweep")
+
+		l := types.NewGenericMetric[float64]("loss", 2)
+		l.Add(10)
+		l.Add(loss)
+		l.Commit()
+
+		o := types.NewGenericMetric[string]("optimizer", 1)
+		o.Add(optimizer)
+		o.Commit()
+
+		run.Metrics["loss"] = l
+		run.Metrics["optimizer"] = o
+
+		return &run
+	}
+
+	aggs := types.AggregateFinalMetrics([]*types.Run{
+		trial("trial-1", 0.4, "adam"), trial("trial-2", 0.1, "sgd"), trial("trial-3", 0.4, "adam"),
+	})
+
+	require.Len(t, aggs, 1)
+
+	loss := aggs["loss"]
+	assert.Equal(t, 3, loss.Count)
+	assert.InDelta(t, 0.3, loss.Mean, 1e-9)
+	assert.InDelta(t, 0.1, loss.Min, 0)
+	assert.InDelta(t, 0.4, loss.Max, 0)
+	assert.Equal(t, "trial-2", loss.MinRun)
+	assert.Equal(t, "trial-1", loss.MaxRun)
+}
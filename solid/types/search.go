@@ -0,0 +1,413 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RunFieldKind what a run search field refers to.
+type RunFieldKind string
+
+const (
+	// ParamField a param of the run, e.g. params.lr.
+	ParamField RunFieldKind = "params"
+	// TagField a tag of the run, e.g. tags.baseline.
+	TagField RunFieldKind = "tags"
+	// MetricField a numeric metric of the run, e.g. metrics.val_loss.
+	MetricField RunFieldKind = "metrics"
+	// StatusField the lifecycle status of the run.
+	StatusField RunFieldKind = "status"
+	// CreatedField the creation time of the run.
+	CreatedField RunFieldKind = "created"
+)
+
+// MetricAgg which value of a metric a MetricField is compared on.
+type MetricAgg string
+
+const (
+	MetricLast MetricAgg = "last"
+	MetricMin  MetricAgg = "min"
+	MetricMax  MetricAgg = "max"
+)
+
+// RunField a run attribute runs can be filtered and ordered on. Name is only
+// set for params, tags and metrics, Agg only for metrics.
+type RunField struct {
+	Kind RunFieldKind
+	Name string
+	Agg  MetricAgg
+}
+
+// ParseRunField parses a search field: "status", "created", "params.<name>",
+// "tags.<name>" or "metrics.<name>" (the metric's last value), the latter
+// also accepting "min(metrics.<name>)", "max(...)" and "last(...)".
+func ParseRunField(s string) (RunField, error) {
+	field := strings.TrimSpace(s)
+
+	for _, agg := range []MetricAgg{MetricLast, MetricMin, MetricMax} {
+		prefix := string(agg) + "("
+		if !strings.HasPrefix(strings.ToLower(field), prefix) {
+			continue
+		}
+
+		inner, ok := strings.CutSuffix(field[len(prefix):], ")")
+		if !ok {
+			return RunField{}, NewBadRequest(fmt.Sprintf("unclosed %s( in field %q", agg, s))
+		}
+
+		f, err := ParseRunField(inner)
+		if err != nil {
+			return RunField{}, err
+		}
+
+		if f.Kind != MetricField {
+			return RunField{}, NewBadRequest(fmt.Sprintf("%s() only applies to metrics, got %q", agg, s))
+		}
+
+		f.Agg = agg
+
+		return f, nil
+	}
+
+	switch strings.ToLower(field) {
+	case string(StatusField):
+		return RunField{Kind: StatusField}, nil //nolint: exhaustruct
+	case string(CreatedField):
+		return RunField{Kind: CreatedField}, nil //nolint: exhaustruct
+	}
+
+	kind, name, ok := strings.Cut(field, ".")
+	name = strings.TrimSpace(name)
+
+	if !ok || name == "" {
+		return RunField{}, NewBadRequest(fmt.Sprintf("unknown field %q", s))
+	}
+
+	// params and tags are stored under normalized names, metrics under the
+	// names they are logged with
+	switch RunFieldKind(strings.ToLower(kind)) {
+	case ParamField:
+		return RunField{Kind: ParamField, Name: normalizeName(name)}, nil //nolint: exhaustruct
+	case TagField:
+		return RunField{Kind: TagField, Name: normalizeName(name)}, nil //nolint: exhaustruct
+	case MetricField:
+		return RunField{Kind: MetricField, Name: name, Agg: MetricLast}, nil
+	default:
+		return RunField{}, NewBadRequest(fmt.Sprintf("unknown field %q", s))
+	}
+}
+
+// String formats the field the way ParseRunField reads it.
+func (f RunField) String() string {
+	switch f.Kind {
+	case StatusField, CreatedField:
+		return string(f.Kind)
+	case MetricField:
+		if f.Agg != "" && f.Agg != MetricLast {
+			return fmt.Sprintf("%s(%s.%s)", f.Agg, f.Kind, f.Name)
+		}
+	}
+
+	return string(f.Kind) + "." + f.Name
+}
+
+// CompareOp comparison operator of a search condition.
+type CompareOp string
+
+const (
+	OpEq  CompareOp = "="
+	OpNeq CompareOp = "!="
+	OpLt  CompareOp = "<"
+	OpLte CompareOp = "<="
+	OpGt  CompareOp = ">"
+	OpGte CompareOp = ">="
+)
+
+func parseCompareOp(s string) (CompareOp, bool) {
+	op := CompareOp(s)
+
+	switch op {
+	case OpEq, OpNeq, OpLt, OpLte, OpGt, OpGte:
+		return op, true
+	case "==":
+		return OpEq, true
+	default:
+		return "", false
+	}
+}
+
+// RunCondition a single "<field> <op> <value>" clause of a run filter.
+type RunCondition struct {
+	Field RunField
+	Op    CompareOp
+	Value string
+}
+
+// Match reports whether v, a run's value for the condition's field,
+// satisfies the condition. Runs without a value (ok false) never match.
+func (c RunCondition) Match(v string, ok bool) bool {
+	if !ok {
+		return false
+	}
+
+	cmp := CompareValues(v, c.Value)
+
+	switch c.Op {
+	case OpEq:
+		return cmp == 0
+	case OpNeq:
+		return cmp != 0
+	case OpLt:
+		return cmp < 0
+	case OpLte:
+		return cmp <= 0
+	case OpGt:
+		return cmp > 0
+	case OpGte:
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// CompareValues compares two field values, numerically when both parse as
+// numbers and lexically otherwise.
+func CompareValues(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return strings.Compare(a, b)
+}
+
+// ParseRunFilter parses a filter expression made of conditions joined with
+// AND, e.g. `metrics.val_loss < 0.2 AND params.lr = 0.001 AND tags.baseline = true`.
+// Values may be quoted with ' or " to hold spaces. Metric values must be
+// numeric, status values known statuses, and created values RFC 3339 times;
+// the latter are returned as unix seconds. An empty expression has no
+// conditions.
+func ParseRunFilter(expr string) ([]RunCondition, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := make([]RunCondition, 0, (len(tokens)+1)/4) //nolint: mnd
+
+	for i := 0; i < len(tokens); {
+		if i+2 >= len(tokens) {
+			return nil, NewBadRequest("incomplete condition at the end of the filter")
+		}
+
+		cond, err := parseRunCondition(tokens[i], tokens[i+1], tokens[i+2])
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, cond)
+
+		i += 3
+		if i == len(tokens) {
+			break
+		}
+
+		if !tokens[i].isKeyword("AND") {
+			return nil, NewBadRequest(fmt.Sprintf("expected AND, got %q", tokens[i].text))
+		}
+
+		i++
+		if i == len(tokens) {
+			return nil, NewBadRequest("filter cannot end with AND")
+		}
+	}
+
+	return conditions, nil
+}
+
+func parseRunCondition(field, op, value filterToken) (RunCondition, error) {
+	if field.quoted {
+		return RunCondition{}, NewBadRequest(fmt.Sprintf("expected a field, got %q", field.text))
+	}
+
+	f, err := ParseRunField(field.text)
+	if err != nil {
+		return RunCondition{}, err
+	}
+
+	o, ok := parseCompareOp(op.text)
+	if !ok || op.quoted {
+		return RunCondition{}, NewBadRequest(fmt.Sprintf("expected an operator after %s, got %q", f, op.text))
+	}
+
+	cond := RunCondition{Field: f, Op: o, Value: value.text}
+
+	switch f.Kind {
+	case MetricField:
+		if _, err := strconv.ParseFloat(value.text, 64); err != nil {
+			return RunCondition{}, NewBadRequest(fmt.Sprintf("%s must be compared to a number, got %q", f, value.text)) //nolint: lll
+		}
+	case StatusField:
+		if _, err := ParseRunStatus(value.text); err != nil {
+			return RunCondition{}, err
+		}
+	case CreatedField:
+		t, err := time.Parse(time.RFC3339, value.text)
+		if err != nil {
+			return RunCondition{}, NewBadRequest(fmt.Sprintf("created must be compared to an RFC 3339 time, got %q", value.text)) //nolint: lll
+		}
+
+		cond.Value = strconv.FormatInt(t.Unix(), 10)
+	case ParamField, TagField:
+	}
+
+	return cond, nil
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func (t filterToken) isKeyword(k string) bool {
+	return !t.quoted && strings.EqualFold(t.text, k)
+}
+
+func isOpRune(r rune) bool {
+	return r == '=' || r == '!' || r == '<' || r == '>'
+}
+
+// tokenizeFilter splits a filter expression into words, operators and
+// quoted values.
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	runes := []rune(expr)
+	tokens := make([]filterToken, 0)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			end := slices.Index(runes[i+1:], r)
+			if end < 0 {
+				return nil, NewBadRequest("unterminated quoted value in filter")
+			}
+
+			tokens = append(tokens, filterToken{text: string(runes[i+1 : i+1+end]), quoted: true})
+			i += end + 2 //nolint: mnd
+		case isOpRune(r):
+			j := i
+			for j < len(runes) && isOpRune(runes[j]) {
+				j++
+			}
+
+			tokens = append(tokens, filterToken{text: string(runes[i:j]), quoted: false})
+			i = j
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !isOpRune(runes[j]) {
+				j++
+			}
+
+			tokens = append(tokens, filterToken{text: string(runes[i:j]), quoted: false})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// RunOrder ordering of run search results.
+type RunOrder struct {
+	Field RunField
+	Desc  bool
+}
+
+// ParseRunOrder parses an ordering of the form "<field> [ASC|DESC]", ASC
+// being the default. An empty ordering sorts by creation time, newest first.
+func ParseRunOrder(s string) (RunOrder, error) {
+	fields := strings.Fields(s)
+
+	if len(fields) == 0 {
+		return RunOrder{Field: RunField{Kind: CreatedField}, Desc: true}, nil //nolint: exhaustruct
+	}
+
+	if len(fields) > 2 { //nolint: mnd
+		return RunOrder{}, NewBadRequest(fmt.Sprintf("malformed ordering %q", s))
+	}
+
+	f, err := ParseRunField(fields[0])
+	if err != nil {
+		return RunOrder{}, err
+	}
+
+	order := RunOrder{Field: f, Desc: false}
+
+	if len(fields) == 2 { //nolint: mnd
+		switch strings.ToUpper(fields[1]) {
+		case "ASC":
+		case "DESC":
+			order.Desc = true
+		default:
+			return RunOrder{}, NewBadRequest(fmt.Sprintf("ordering direction must be ASC or DESC, got %q", fields[1])) //nolint: lll
+		}
+	}
+
+	return order, nil
+}
+
+// RunQuery a parsed run search: runs of Exps (all experiments when empty)
+// matching every condition, ordered by Order and paginated with an offset
+// cursor.
+type RunQuery struct {
+	Exps       []string
+	Conditions []RunCondition
+	Order      RunOrder
+	Cursor     uint64
+	Limit      int64
+}
+
+// NewRunQuery builds a run query from a filter expression (see
+// ParseRunFilter) and an ordering (see ParseRunOrder).
+func NewRunQuery(exps []string, filter, order string, cursor uint64, limit int64) (RunQuery, error) {
+	conditions, err := ParseRunFilter(filter)
+	if err != nil {
+		return RunQuery{}, err
+	}
+
+	o, err := ParseRunOrder(order)
+	if err != nil {
+		return RunQuery{}, err
+	}
+
+	normalized := make([]string, 0, len(exps))
+
+	for _, exp := range exps {
+		if id := normalizeName(exp); id != "" {
+			normalized = append(normalized, id)
+		}
+	}
+
+	return RunQuery{
+		Exps:       normalized,
+		Conditions: conditions,
+		Order:      o,
+		Cursor:     cursor,
+		Limit:      limit,
+	}, nil
+}
@@ -0,0 +1,159 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestParseRunFilter(t *testing.T) {
+	t.Parallel()
+
+	valLoss := types.RunField{Kind: types.MetricField, Name: "val_loss", Agg: types.MetricLast}
+
+	testcases := []struct {
+		name     string
+		expr     string
+		expected []types.RunCondition
+	}{
+		{"empty", "  ", []types.RunCondition{}},
+		{
+			"metric_and_param", "metrics.val_loss < 0.2 AND params.lr = 0.001",
+			[]types.RunCondition{
+				{Field: valLoss, Op: types.OpLt, Value: "0.2"},
+				{Field: types.RunField{Kind: types.ParamField, Name: "lr"}, Op: types.OpEq, Value: "0.001"}, //nolint: exhaustruct
+			},
+		},
+		{
+			"no_spaces_and_lowercase_and", "metrics.val_loss>=1 and status=running",
+			[]types.RunCondition{
+				{Field: valLoss, Op: types.OpGte, Value: "1"},
+				{Field: types.RunField{Kind: types.StatusField}, Op: types.OpEq, Value: "running"}, //nolint: exhaustruct
+			},
+		},
+		{
+			"metric_aggregates", "min(metrics.val_loss) <= 0.1 AND MAX(metrics.acc) != 1",
+			[]types.RunCondition{
+				{Field: types.RunField{Kind: types.MetricField, Name: "val_loss", Agg: types.MetricMin}, Op: types.OpLte, Value: "0.1"},
+				{Field: types.RunField{Kind: types.MetricField, Name: "acc", Agg: types.MetricMax}, Op: types.OpNeq, Value: "1"},
+			},
+		},
+		{
+			"metric_names_are_exact", "metrics.Val_Loss > 0.1 AND last(metrics.Top1) > 0.5 AND params.Batch_Size = 32",
+			[]types.RunCondition{
+				{Field: types.RunField{Kind: types.MetricField, Name: "Val_Loss", Agg: types.MetricLast}, Op: types.OpGt, Value: "0.1"},
+				{Field: types.RunField{Kind: types.MetricField, Name: "Top1", Agg: types.MetricLast}, Op: types.OpGt, Value: "0.5"},
+				{Field: types.RunField{Kind: types.ParamField, Name: "batch_size"}, Op: types.OpEq, Value: "32"}, //nolint: exhaustruct
+			},
+		},
+		{
+			"quoted_tag_value", `tags.dataset = "imagenet v2"`,
+			[]types.RunCondition{
+				{Field: types.RunField{Kind: types.TagField, Name: "dataset"}, Op: types.OpEq, Value: "imagenet v2"}, //nolint: exhaustruct
+			},
+		},
+		{
+			"created_as_unix", "created > 2025-01-01T00:00:00Z",
+			[]types.RunCondition{
+				{Field: types.RunField{Kind: types.CreatedField}, Op: types.OpGt, Value: "1735689600"}, //nolint: exhaustruct
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conditions, err := types.ParseRunFilter(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, conditions)
+		})
+	}
+}
+
+func TestParseRunFilterErrors(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		expr string
+	}{
+		{"unknown_field", "foo.bar = 1"},
+		{"missing_value", "params.lr ="},
+		{"missing_and", "params.lr = 1 params.bs = 2"},
+		{"or_unsupported", "params.lr = 1 OR params.bs = 2"},
+		{"trailing_and", "params.lr = 1 AND"},
+		{"bad_operator", "params.lr =~ 1"},
+		{"non_numeric_metric", "metrics.loss < low"},
+		{"unknown_status", "status = done"},
+		{"aggregate_on_param", "min(params.lr) = 1"},
+		{"unterminated_quote", "tags.a = 'b"},
+		{"malformed_created", "created > yesterday"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := types.ParseRunFilter(tc.expr)
+			require.ErrorIs(t, err, types.ErrBadRequest)
+		})
+	}
+}
+
+func TestParseRunOrder(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		order    string
+		expected types.RunOrder
+	}{
+		{"default", "", types.RunOrder{Field: types.RunField{Kind: types.CreatedField}, Desc: true}},                     //nolint: exhaustruct
+		{"ascending_by_default", "params.lr", types.RunOrder{Field: types.RunField{Kind: types.ParamField, Name: "lr"}}}, //nolint: exhaustruct,lll
+		{"desc", "max(metrics.acc) desc", types.RunOrder{
+			Field: types.RunField{Kind: types.MetricField, Name: "acc", Agg: types.MetricMax}, Desc: true,
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			order, err := types.ParseRunOrder(tc.order)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, order)
+		})
+	}
+
+	_, err := types.ParseRunOrder("params.lr sideways")
+	require.ErrorIs(t, err, types.ErrBadRequest)
+}
+
+func TestRunConditionMatch(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		cond     types.RunCondition
+		value    string
+		ok       bool
+		expected bool
+	}{
+		{"numeric_equality", types.RunCondition{Op: types.OpEq, Value: "0.001"}, "1e-3", true, true},       //nolint: exhaustruct
+		{"numeric_ordering", types.RunCondition{Op: types.OpLt, Value: "10"}, "9", true, true},             //nolint: exhaustruct
+		{"string_equality", types.RunCondition{Op: types.OpEq, Value: "adam"}, "sgd", true, false},         //nolint: exhaustruct
+		{"string_inequality", types.RunCondition{Op: types.OpNeq, Value: "adam"}, "sgd", true, true},       //nolint: exhaustruct
+		{"missing_value_never_matches", types.RunCondition{Op: types.OpNeq, Value: "a"}, "", false, false}, //nolint: exhaustruct
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.cond.Match(tc.value, tc.ok))
+		})
+	}
+}
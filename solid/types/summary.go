@@ -0,0 +1,93 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Summary statistics kept for each numeric metric of a run. They prefix the
+// fields of the run's metric summary hash, e.g. "Min:val_loss".
+const (
+	SummaryCount    = "Count"
+	SummarySum      = "Sum"
+	SummaryMin      = "Min"
+	SummaryMinStep  = "MinStep"
+	SummaryMax      = "Max"
+	SummaryMaxStep  = "MaxStep"
+	SummaryLast     = "Last"
+	SummaryLastStep = "LastStep"
+)
+
+// MetricSummary summary statistics of the numeric values of a metric,
+// maintained as values are logged so they can be read without the values
+// themselves. Steps are nil when the value was logged without one; ties keep
+// the first value reaching the min or max.
+type MetricSummary struct {
+	Count    int64
+	Sum      float64
+	Min      float64
+	MinStep  *int64
+	Max      float64
+	MaxStep  *int64
+	Last     float64
+	LastStep *int64
+}
+
+// Mean returns the mean of the summarized values, 0 when there are none.
+func (s MetricSummary) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.Sum / float64(s.Count)
+}
+
+// ParseMetricSummaries parses the fields of a metric summary hash, keyed by
+// "<stat>:<metric>", into the summary of each metric. Malformed fields are
+// skipped.
+func ParseMetricSummaries(hash map[string]string) map[string]MetricSummary {
+	summaries := make(map[string]MetricSummary)
+
+	for field, raw := range hash {
+		stat, metric, ok := strings.Cut(field, ":")
+		if !ok || metric == "" {
+			continue
+		}
+
+		s := summaries[metric]
+
+		switch stat {
+		case SummaryCount:
+			s.Count, _ = strconv.ParseInt(raw, 10, 64)
+		case SummarySum:
+			s.Sum, _ = strconv.ParseFloat(raw, 64)
+		case SummaryMin:
+			s.Min, _ = strconv.ParseFloat(raw, 64)
+		case SummaryMax:
+			s.Max, _ = strconv.ParseFloat(raw, 64)
+		case SummaryLast:
+			s.Last, _ = strconv.ParseFloat(raw, 64)
+		case SummaryMinStep:
+			s.MinStep = parseStep(raw)
+		case SummaryMaxStep:
+			s.MaxStep = parseStep(raw)
+		case SummaryLastStep:
+			s.LastStep = parseStep(raw)
+		default:
+			continue
+		}
+
+		summaries[metric] = s
+	}
+
+	return summaries
+}
+
+func parseStep(raw string) *int64 {
+	step, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &step
+}
@@ -0,0 +1,82 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestParseMetricSummaries(t *testing.T) {
+	t.Parallel()
+
+	step := func(s int64) *int64 { return &s }
+
+	testcases := []struct {
+		name     string
+		hash     map[string]string
+		expected map[string]types.MetricSummary
+	}{
+		{
+			name:     "empty_hash",
+			hash:     map[string]string{},
+			expected: map[string]types.MetricSummary{},
+		},
+		{
+			name: "full_summary",
+			hash: map[string]string{
+				"Count:val_loss":    "4",
+				"Sum:val_loss":      "2",
+				"Min:val_loss":      "0.2",
+				"MinStep:val_loss":  "3",
+				"Max:val_loss":      "0.9",
+				"MaxStep:val_loss":  "0",
+				"Last:val_loss":     "0.3",
+				"LastStep:val_loss": "4",
+			},
+			expected: map[string]types.MetricSummary{
+				"val_loss": {
+					Count: 4, Sum: 2, Min: 0.2, MinStep: step(3), Max: 0.9, MaxStep: step(0),
+					Last: 0.3, LastStep: step(4),
+				},
+			},
+		},
+		{
+			name: "values_logged_without_steps",
+			hash: map[string]string{
+				"Count:acc": "1", "Sum:acc": "0.5", "Min:acc": "0.5", "MinStep:acc": "",
+				"Max:acc": "0.5", "MaxStep:acc": "", "Last:acc": "0.5", "LastStep:acc": "",
+			},
+			expected: map[string]types.MetricSummary{
+				"acc": {Count: 1, Sum: 0.5, Min: 0.5, Max: 0.5, Last: 0.5},
+			},
+		},
+		{
+			name: "metric_names_may_hold_colons",
+			hash: map[string]string{"Last:eval:acc": "0.7"},
+			expected: map[string]types.MetricSummary{
+				"eval:acc": {Last: 0.7},
+			},
+		},
+		{
+			name:     "unknown_fields_are_skipped",
+			hash:     map[string]string{"Median:acc": "0.7", "acc": "0.1"},
+			expected: map[string]types.MetricSummary{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, types.ParseMetricSummaries(tc.hash))
+		})
+	}
+}
+
+func TestMetricSummaryMean(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 0.5, types.MetricSummary{Count: 4, Sum: 2}.Mean(), 1e-9)
+	assert.Zero(t, types.MetricSummary{}.Mean())
+}
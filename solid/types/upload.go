@@ -0,0 +1,181 @@
+package types //nolint: var-naming
+
+import (
+	"cmp"
+	"encoding"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uploadPartFieldPrefix prefix of the upload session hash fields holding the
+// ETag of each uploaded part, e.g. "Part:3".
+const uploadPartFieldPrefix = "Part:"
+
+// UploadSession a resumable artifact upload. Content is uploaded in parts of
+// PartSize bytes (the last one being shorter) into an S3 multipart upload,
+// Offset being how many bytes were uploaded so far. Uploading resumes from
+// Offset after a dropped connection, until the session expires.
+//
+// Presigned sessions are uploaded by the client straight to S3 with a
+// pre-signed PUT URL instead, S3 checking the content against SHA256.
+//
+// Deduplicated sessions are complete as soon as they start: their content,
+// with SHA256, is already stored as an artifact of the run (see Blob) and
+// is not uploaded.
+type UploadSession struct {
+	ID          string
+	RunID       string
+	Name        string
+	ContentType ContentType
+	// Size the size of the artifact, declared when the upload started.
+	Size int64
+	// SHA256 the hex encoded SHA-256 the client expects the artifact to
+	// have, empty when it did not send one.
+	SHA256     string
+	PartSize   int64
+	Offset     int64
+	S3Key      string
+	S3UploadID string
+	// Parts the uploaded parts, in order.
+	Parts []UploadedPart
+	// DigestState the state of the digest of the bytes uploaded so far, see
+	// Digester.State.
+	DigestState  []byte
+	Presigned    bool
+	Deduplicated bool
+	ExpiresAt    time.Time
+}
+
+// UploadedPart a part of a multipart upload stored in S3.
+type UploadedPart struct {
+	Number int32
+	ETag   string
+}
+
+// MultipartUpload a multipart upload started in S3.
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// NewUploadSession creates an UploadSession from its redis hash, see Hash.
+func NewUploadSession(m map[string]string) UploadSession {
+	s := UploadSession{
+		ID:           m["ID"],
+		RunID:        m["RunID"],
+		Name:         m["Name"],
+		ContentType:  ContentType(m["Type"]),
+		Size:         0,
+		SHA256:       m["SHA256"],
+		PartSize:     0,
+		Offset:       0,
+		S3Key:        m["S3Key"],
+		S3UploadID:   m["S3UploadID"],
+		Parts:        make([]UploadedPart, 0),
+		DigestState:  []byte(m["DigestState"]),
+		Presigned:    m["Presigned"] == "true",
+		Deduplicated: m["Deduplicated"] == "true",
+		ExpiresAt:    time.Time{},
+	}
+
+	s.Size, _ = strconv.ParseInt(m["Size"], 10, 64)
+	s.PartSize, _ = strconv.ParseInt(m["PartSize"], 10, 64)
+	s.Offset, _ = strconv.ParseInt(m["Offset"], 10, 64)
+
+	if t, err := time.Parse(time.RFC3339, m["ExpiresAt"]); err == nil {
+		s.ExpiresAt = t
+	}
+
+	for field, etag := range m {
+		raw, ok := strings.CutPrefix(field, uploadPartFieldPrefix)
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			continue
+		}
+
+		s.Parts = append(s.Parts, UploadedPart{Number: int32(n), ETag: etag})
+	}
+
+	slices.SortFunc(s.Parts, func(a, b UploadedPart) int { return cmp.Compare(a.Number, b.Number) })
+
+	return s
+}
+
+// Hash returns the fields of the session as stored in a redis hash, the
+// reverse of NewUploadSession.
+func (s UploadSession) Hash() map[string]any {
+	fields := map[string]any{
+		"ID":           s.ID,
+		"RunID":        s.RunID,
+		"Name":         s.Name,
+		"Type":         string(s.ContentType),
+		"Size":         strconv.FormatInt(s.Size, 10),
+		"SHA256":       s.SHA256,
+		"PartSize":     strconv.FormatInt(s.PartSize, 10),
+		"Offset":       strconv.FormatInt(s.Offset, 10),
+		"S3Key":        s.S3Key,
+		"S3UploadID":   s.S3UploadID,
+		"DigestState":  s.DigestState,
+		"Presigned":    strconv.FormatBool(s.Presigned),
+		"Deduplicated": strconv.FormatBool(s.Deduplicated),
+		"ExpiresAt":    s.ExpiresAt.Format(time.RFC3339),
+	}
+
+	for _, p := range s.Parts {
+		fields[UploadPartField(p.Number)] = p.ETag
+	}
+
+	return fields
+}
+
+// UploadPartField returns the upload session hash field holding the ETag of
+// part n.
+func UploadPartField(n int32) string {
+	return uploadPartFieldPrefix + strconv.FormatInt(int64(n), 10)
+}
+
+// Expired reports whether the session expired at now.
+func (s UploadSession) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// Complete reports whether all the content of the artifact was uploaded.
+func (s UploadSession) Complete() bool {
+	return s.Offset == s.Size
+}
+
+// NextPart returns the number and length of the part starting at Offset.
+// Parts are numbered from 1.
+func (s UploadSession) NextPart() (int32, int64) {
+	return int32(s.Offset/s.PartSize) + 1, min(s.PartSize, s.Size-s.Offset) //nolint: gosec
+}
+
+// Digester resumes the digest of the bytes uploaded so far.
+func (s UploadSession) Digester() (*Digester, error) {
+	d := NewDigester()
+
+	if s.Offset == 0 && len(s.DigestState) == 0 {
+		return d, nil
+	}
+
+	u, ok := d.hash.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return nil, NewInternalErr("digest state cannot be restored")
+	}
+
+	if err := u.UnmarshalBinary(s.DigestState); err != nil {
+		return nil, fmt.Errorf("%w: malformed digest state of upload <%s>: %w", ErrInternal, s.ID, err)
+	}
+
+	d.size = s.Offset
+
+	return d, nil
+}
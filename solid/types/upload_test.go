@@ -0,0 +1,103 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestUploadSessionHash(t *testing.T) {
+	t.Parallel()
+
+	s := types.UploadSession{
+		ID:           "upload",
+		RunID:        "run",
+		Name:         "model.pt",
+		ContentType:  types.ModelContentType,
+		Size:         25,
+		SHA256:       "",
+		PartSize:     10,
+		Offset:       20,
+		S3Key:        "artifacts/model.pt",
+		S3UploadID:   "s3-upload",
+		Parts:        []types.UploadedPart{{Number: 1, ETag: "a"}, {Number: 2, ETag: "b"}},
+		DigestState:  []byte{0, 1, 2},
+		Presigned:    false,
+		Deduplicated: true,
+		ExpiresAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	hash := make(map[string]string)
+
+	for field, v := range s.Hash() {
+		switch val := v.(type) {
+		case string:
+			hash[field] = val
+		case []byte:
+			hash[field] = string(val)
+		}
+	}
+
+	assert.Equal(t, s, types.NewUploadSession(hash))
+}
+
+func TestUploadSessionNextPart(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		offset int64
+		number int32
+		length int64
+	}{
+		{"first_part", 0, 1, 10},
+		{"middle_part", 10, 2, 10},
+		{"last_part", 20, 3, 5},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := types.UploadSession{Size: 25, PartSize: 10, Offset: tc.offset} //nolint: exhaustruct
+
+			number, length := s.NextPart()
+			assert.Equal(t, tc.number, number)
+			assert.Equal(t, tc.length, length)
+		})
+	}
+}
+
+func TestUploadSessionDigester(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("resumable upload content")
+
+	full := types.NewDigester()
+	_, err := full.Write(content)
+	require.NoError(t, err)
+
+	first := types.NewDigester()
+	_, err = first.Write(content[:10])
+	require.NoError(t, err)
+
+	state, err := first.State()
+	require.NoError(t, err)
+
+	s := types.UploadSession{Offset: 10, DigestState: state} //nolint: exhaustruct
+
+	resumed, err := s.Digester()
+	require.NoError(t, err)
+
+	_, err = resumed.Write(content[10:])
+	require.NoError(t, err)
+
+	assert.Equal(t, full.Digest(), resumed.Digest())
+
+	s.DigestState = []byte("garbage")
+	_, err = s.Digester()
+	require.ErrorIs(t, err, types.ErrInternal)
+}
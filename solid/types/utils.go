@@ -31,3 +31,21 @@ func ParseVal(s string) any {
 
 	return s
 }
+
+// NumericVal returns v as a float64 if it holds a number.
+func NumericVal(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	default:
+		return 0, false
+	}
+}
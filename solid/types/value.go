@@ -0,0 +1,277 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ValueKind the type of the values of a metric. All values of a metric
+// stream share the same kind, stored along with each value so they are read
+// back with the type they were logged with.
+type ValueKind string
+
+const (
+	IntKind       ValueKind = "int"
+	FloatKind     ValueKind = "float"
+	StringKind    ValueKind = "string"
+	BoolKind      ValueKind = "bool"
+	HistogramKind ValueKind = "histogram"
+	VectorKind    ValueKind = "vector"
+)
+
+// Histogram a distribution of values (e.g. of a layer's weights) logged as a
+// single metric value. Counts[i] is the number of values falling in
+// [Edges[i], Edges[i+1]).
+type Histogram struct {
+	Edges  []float64 `json:"edges"`
+	Counts []float64 `json:"counts"`
+}
+
+// Validate checks the histogram has one more edge than it has counts, and
+// that its edges are increasing.
+func (h Histogram) Validate() error {
+	if len(h.Counts) == 0 || len(h.Edges) != len(h.Counts)+1 {
+		return NewBadRequest(fmt.Sprintf("histogram needs one more edge than counts, got %d edges for %d counts",
+			len(h.Edges), len(h.Counts)))
+	}
+
+	for i := 1; i < len(h.Edges); i++ {
+		if h.Edges[i] <= h.Edges[i-1] {
+			return NewBadRequest("histogram edges must be increasing")
+		}
+	}
+
+	return nil
+}
+
+// Vector a fixed list of numbers (e.g. per-class accuracy) logged as a
+// single metric value.
+type Vector []float64
+
+// KindOf returns the kind of a metric value.
+func KindOf(v any) (ValueKind, bool) {
+	switch v.(type) {
+	case int64:
+		return IntKind, true
+	case float64:
+		return FloatKind, true
+	case string:
+		return StringKind, true
+	case bool:
+		return BoolKind, true
+	case Histogram:
+		return HistogramKind, true
+	case Vector:
+		return VectorKind, true
+	default:
+		return "", false
+	}
+}
+
+// MetricKind returns the kind of the values of a metric.
+func MetricKind(m Metric) (ValueKind, bool) {
+	switch m.(type) {
+	case *GenericMetric[int64]:
+		return IntKind, true
+	case *GenericMetric[float64]:
+		return FloatKind, true
+	case *GenericMetric[string]:
+		return StringKind, true
+	case *GenericMetric[bool]:
+		return BoolKind, true
+	case *GenericMetric[Histogram]:
+		return HistogramKind, true
+	case *GenericMetric[Vector]:
+		return VectorKind, true
+	default:
+		return "", false
+	}
+}
+
+// CanonicalMetric returns m holding values of one of the types metric values
+// are stored as (see MetricKind): float32 values are converted to float64,
+// and values of the other Go integer types to int64. Metrics of any other
+// unsupported type are an ErrBadRequest error. Only the uncommitted values
+// of converted metrics are kept.
+func CanonicalMetric(m Metric) (Metric, error) { //nolint: ireturn
+	if _, ok := MetricKind(m); ok {
+		return m, nil
+	}
+
+	switch g := m.(type) {
+	case *GenericMetric[float32]:
+		return convertMetric(g, func(v float32) (float64, bool) {
+			// through its shortest representation, so 0.1 stays 0.1
+			f, err := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+
+			return f, err == nil
+		})
+	case *GenericMetric[int]:
+		return convertMetric(g, func(v int) (int64, bool) { return int64(v), true })
+	case *GenericMetric[int8]:
+		return convertMetric(g, func(v int8) (int64, bool) { return int64(v), true })
+	case *GenericMetric[int16]:
+		return convertMetric(g, func(v int16) (int64, bool) { return int64(v), true })
+	case *GenericMetric[int32]:
+		return convertMetric(g, func(v int32) (int64, bool) { return int64(v), true })
+	case *GenericMetric[uint]:
+		return convertMetric(g, func(v uint) (int64, bool) { return int64(v), v <= math.MaxInt64 }) //nolint: gosec
+	case *GenericMetric[uint8]:
+		return convertMetric(g, func(v uint8) (int64, bool) { return int64(v), true })
+	case *GenericMetric[uint16]:
+		return convertMetric(g, func(v uint16) (int64, bool) { return int64(v), true })
+	case *GenericMetric[uint32]:
+		return convertMetric(g, func(v uint32) (int64, bool) { return int64(v), true })
+	case *GenericMetric[uint64]:
+		return convertMetric(g, func(v uint64) (int64, bool) { return int64(v), v <= math.MaxInt64 }) //nolint: gosec
+	default:
+		return nil, NewBadRequest(fmt.Sprintf("metric %q holds values of an unsupported type", m.Name()))
+	}
+}
+
+func convertMetric[T, U any](m *GenericMetric[T], convert func(T) (U, bool)) (Metric, error) { //nolint: ireturn
+	vals := m.UnCommited()
+	metas := m.MetasToCommit()
+	c := NewGenericMetric[U](m.Name(), len(vals))
+
+	for i, v := range vals {
+		u, ok := convert(v)
+		if !ok {
+			return nil, NewBadRequest(fmt.Sprintf("value %v of metric %q is out of range", v, m.Name()))
+		}
+
+		c.AddWithMeta(u, metas[i])
+	}
+
+	return c, nil
+}
+
+// NewMetricOfKind creates an empty metric holding values of kind.
+func NewMetricOfKind(name string, kind ValueKind, sizeAlloc int) (Metric, error) { //nolint: ireturn
+	switch kind {
+	case IntKind:
+		return NewGenericMetric[int64](name, sizeAlloc), nil
+	case FloatKind:
+		return NewGenericMetric[float64](name, sizeAlloc), nil
+	case StringKind:
+		return NewGenericMetric[string](name, sizeAlloc), nil
+	case BoolKind:
+		return NewGenericMetric[bool](name, sizeAlloc), nil
+	case HistogramKind:
+		return NewGenericMetric[Histogram](name, sizeAlloc), nil
+	case VectorKind:
+		return NewGenericMetric[Vector](name, sizeAlloc), nil
+	default:
+		return nil, NewBadRequest(fmt.Sprintf("unknown metric value kind %q", kind))
+	}
+}
+
+// CoerceMetric returns m holding values of kind. Int metrics are converted
+// to float ones, any other mismatch being an ErrBadRequest error. Only the
+// uncommitted values of m are kept.
+func CoerceMetric(m Metric, kind ValueKind) (Metric, error) { //nolint: ireturn
+	current, ok := MetricKind(m)
+	if !ok {
+		return nil, NewBadRequest(fmt.Sprintf("metric %q holds values of an unsupported type", m.Name()))
+	}
+
+	if current == kind {
+		return m, nil
+	}
+
+	if current != IntKind || kind != FloatKind {
+		return nil, NewBadRequest(fmt.Sprintf("metric %q holds %s values, cannot log %s values", m.Name(), kind, current))
+	}
+
+	vals := m.ValsToCommit()
+	metas := m.MetasToCommit()
+	f := NewGenericMetric[float64](m.Name(), len(vals))
+
+	for i, v := range vals {
+		f.AddWithMeta(float64(v.(int64)), metas[i]) //nolint: forcetypeassert
+	}
+
+	return f, nil
+}
+
+// EncodeVal serializes a metric value to be stored, see DecodeVal.
+func EncodeVal(v any) (string, error) {
+	switch val := v.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64), nil
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case Histogram, Vector:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return "", fmt.Errorf("%w: could not encode metric value: %w", ErrInvalidInput, err)
+		}
+
+		return string(b), nil
+	default:
+		return "", NewBadRequest(fmt.Sprintf("unsupported metric value type %T", v))
+	}
+}
+
+// DecodeVal parses a metric value serialized with EncodeVal as a value of
+// kind.
+func DecodeVal(s string, kind ValueKind) (any, error) {
+	var (
+		v   any
+		err error
+	)
+
+	switch kind {
+	case IntKind:
+		v, err = strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case FloatKind:
+		v, err = strconv.ParseFloat(strings.TrimSpace(s), 64)
+	case StringKind:
+		v = s
+	case BoolKind:
+		v, err = strconv.ParseBool(s)
+	case HistogramKind:
+		var h Histogram
+		err = json.Unmarshal([]byte(s), &h)
+		v = h
+	case VectorKind:
+		var vec Vector
+		err = json.Unmarshal([]byte(s), &vec)
+		v = vec
+	default:
+		return nil, NewInvalidInputErr(fmt.Sprintf("unknown metric value kind %q", kind))
+	}
+
+	if err != nil {
+		return nil, NewInvalidInputErr(fmt.Sprintf("malformed %s metric value %q", kind, s))
+	}
+
+	return v, nil
+}
+
+// InferKind guesses the kind of metric values stored before kinds were
+// recorded: int when they are all integers, float when they are all numbers
+// (so a float logged as 1.0 does not make the stream an int one), string
+// otherwise.
+func InferKind(vals []string) ValueKind {
+	kind := IntKind
+
+	for _, s := range vals {
+		switch ParseVal(s).(type) {
+		case int64:
+		case float64:
+			kind = FloatKind
+		default:
+			return StringKind
+		}
+	}
+
+	return kind
+}
@@ -0,0 +1,187 @@
+package types_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddo123/mlsolid/solid/types"
+)
+
+func TestValRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		val  any
+		kind types.ValueKind
+	}{
+		{name: "int", val: int64(42), kind: types.IntKind},
+		{name: "negative_int", val: int64(-7), kind: types.IntKind},
+		{name: "float", val: 0.125, kind: types.FloatKind},
+		{name: "integral_float_stays_float", val: 1.0, kind: types.FloatKind},
+		{name: "large_float", val: 1e21, kind: types.FloatKind},
+		{name: "string", val: "converging", kind: types.StringKind},
+		{name: "numeric_string_stays_string", val: "1", kind: types.StringKind},
+		{name: "bool", val: true, kind: types.BoolKind},
+		{
+			name: "histogram",
+			val:  types.Histogram{Edges: []float64{0, 0.5, 1}, Counts: []float64{3, 7}},
+			kind: types.HistogramKind,
+		},
+		{name: "vector", val: types.Vector{0.9, 0.75, 1}, kind: types.VectorKind},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			kind, ok := types.KindOf(tc.val)
+			require.True(t, ok)
+			assert.Equal(t, tc.kind, kind)
+
+			encoded, err := types.EncodeVal(tc.val)
+			require.NoError(t, err)
+
+			decoded, err := types.DecodeVal(encoded, kind)
+			require.NoError(t, err)
+			assert.Equal(t, tc.val, decoded)
+			assert.IsType(t, tc.val, decoded)
+		})
+	}
+}
+
+func TestDecodeMalformedVal(t *testing.T) {
+	t.Parallel()
+
+	_, err := types.DecodeVal("0.5", types.IntKind)
+	require.ErrorIs(t, err, types.ErrInvalidInput)
+
+	_, err = types.DecodeVal("[1, 2", types.VectorKind)
+	require.ErrorIs(t, err, types.ErrInvalidInput)
+
+	_, err = types.DecodeVal("1", types.ValueKind("complex"))
+	require.ErrorIs(t, err, types.ErrInvalidInput)
+}
+
+func TestHistogramValidate(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name  string
+		h     types.Histogram
+		valid bool
+	}{
+		{name: "valid", h: types.Histogram{Edges: []float64{0, 1, 2}, Counts: []float64{1, 1}}, valid: true},
+		{name: "empty", h: types.Histogram{}, valid: false},
+		{name: "missing_edge", h: types.Histogram{Edges: []float64{0, 1}, Counts: []float64{1, 1}}, valid: false},
+		{name: "decreasing_edges", h: types.Histogram{Edges: []float64{0, 2, 1}, Counts: []float64{1, 1}}, valid: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.h.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, types.ErrBadRequest)
+			}
+		})
+	}
+}
+
+func TestInferKind(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		vals     []string
+		expected types.ValueKind
+	}{
+		{name: "integers", vals: []string{"1", "2", "3"}, expected: types.IntKind},
+		{name: "integral_first_value", vals: []string{"1", "0.5"}, expected: types.FloatKind},
+		{name: "strings", vals: []string{"1", "path/to/ckpt"}, expected: types.StringKind},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, types.InferKind(tc.vals))
+		})
+	}
+}
+
+func TestCoerceMetric(t *testing.T) {
+	t.Parallel()
+
+	step := int64(4)
+
+	epochs := types.NewGenericMetric[int64]("loss", 1)
+	epochs.AddWithMeta(1, types.ValMeta{Step: &step})
+
+	m, err := types.CoerceMetric(epochs, types.FloatKind)
+	require.NoError(t, err)
+	assert.Equal(t, []any{1.0}, m.ValsToCommit())
+	assert.Equal(t, &step, m.MetasToCommit()[0].Step)
+
+	m, err = types.CoerceMetric(epochs, types.IntKind)
+	require.NoError(t, err)
+	assert.Same(t, epochs, m)
+
+	_, err = types.CoerceMetric(epochs, types.StringKind)
+	require.ErrorIs(t, err, types.ErrBadRequest)
+
+	loss := types.NewGenericMetric[float64]("loss", 1)
+	loss.Add(0.5)
+
+	_, err = types.CoerceMetric(loss, types.IntKind)
+	require.ErrorIs(t, err, types.ErrBadRequest)
+}
+
+func TestCanonicalMetric(t *testing.T) {
+	t.Parallel()
+
+	step := int64(2)
+
+	mse := types.NewGenericMetric[float32]("mse", 1)
+	mse.AddWithMeta(0.234, types.ValMeta{Step: &step})
+
+	m, err := types.CanonicalMetric(mse)
+	require.NoError(t, err)
+	assert.Equal(t, []any{0.234}, m.ValsToCommit())
+	assert.Equal(t, &step, m.MetasToCommit()[0].Step)
+
+	kind, ok := types.MetricKind(m)
+	require.True(t, ok)
+	assert.Equal(t, types.FloatKind, kind)
+
+	epochs := types.NewGenericMetric[int32]("epochs", 1)
+	epochs.Add(3)
+
+	m, err = types.CanonicalMetric(epochs)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(3)}, m.ValsToCommit())
+
+	loss := types.NewGenericMetric[float64]("loss", 1)
+	loss.Add(0.5)
+
+	m, err = types.CanonicalMetric(loss)
+	require.NoError(t, err)
+	assert.Same(t, loss, m)
+
+	big := types.NewGenericMetric[uint64]("big", 1)
+	big.Add(math.MaxUint64)
+
+	_, err = types.CanonicalMetric(big)
+	require.ErrorIs(t, err, types.ErrBadRequest)
+
+	bytes := types.NewGenericMetric[[]byte]("bytes", 1)
+	bytes.Add([]byte("x"))
+
+	_, err = types.CanonicalMetric(bytes)
+	require.ErrorIs(t, err, types.ErrBadRequest)
+}